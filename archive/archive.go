@@ -0,0 +1,282 @@
+// Package archive streams rates between a currency.Storage and a portable archive,
+// so history can be moved from one storage to another.
+//
+// Archives hold one rate per line, as JSON (JSONL) or CSV with a header, optionally gzip compressed.
+// Rates keep their ID, provider and CreatedAt, IDs are kept by the importing storage when it can use them.
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/malusev998/currency"
+)
+
+const (
+	FormatJSONL Format = "jsonl"
+	FormatCSV   Format = "csv"
+
+	DefaultBatchSize = 1000
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown archive format")
+	ErrInvalidRecord = errors.New("invalid archive record")
+
+	// Header is the first row of CSV archives
+	Header = []string{"id", "from", "to", "provider", "rate", "created_at"}
+
+	gzipMagic = []byte{0x1f, 0x8b}
+)
+
+type (
+	Format string
+
+	// Progress is reported after every batch
+	Progress struct {
+		// Pair is the pair of the batch, exports only
+		Pair string
+		// Rates is the number of rates exported or imported so far,
+		// for imports it includes skipped rates and can be used as ImportConfig.Skip to resume
+		Rates int
+	}
+
+	// Encoder writes rates one by one, Flush has to be called after the last rate
+	Encoder interface {
+		Encode(rate currency.CurrencyWithID) error
+		Flush() error
+	}
+
+	decoder interface {
+		// Decode returns io.EOF after the last rate
+		Decode() (currency.CurrencyWithID, error)
+	}
+
+	jsonlEncoder struct {
+		encoder *json.Encoder
+	}
+
+	csvEncoder struct {
+		w *csv.Writer
+	}
+
+	jsonlDecoder struct {
+		decoder *json.Decoder
+	}
+
+	csvDecoder struct {
+		r       *csv.Reader
+		columns map[string]int
+	}
+)
+
+// ConvertToFormatFromString converts the case insensitive name of a format
+func ConvertToFormatFromString(format string) (Format, error) {
+	switch f := Format(strings.ToLower(format)); f {
+	case FormatJSONL, FormatCSV:
+		return f, nil
+	}
+
+	return "", fmt.Errorf("%w %q, expected %s or %s", ErrUnknownFormat, format, FormatJSONL, FormatCSV)
+}
+
+// FormatFromPath guesses the format from a file name like rates.csv.gz, JSONL is returned when unknown
+func FormatFromPath(path string) Format {
+	if strings.HasSuffix(strings.TrimSuffix(strings.ToLower(path), ".gz"), ".csv") {
+		return FormatCSV
+	}
+
+	return FormatJSONL
+}
+
+// idString formats IDs of every storage, MongoDB ObjectIDs as hex
+func idString(id interface{}) string {
+	if id == nil {
+		return ""
+	}
+
+	if hex, ok := id.(interface{ Hex() string }); ok {
+		return hex.Hex()
+	}
+
+	return fmt.Sprint(id)
+}
+
+// Record formats a rate as a CSV row matching Header
+func Record(rate currency.CurrencyWithID) []string {
+	return []string{
+		idString(rate.ID),
+		rate.From,
+		rate.To,
+		string(rate.Provider),
+		rate.Rate.String(),
+		rate.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// NewEncoder writes rates to w in the format, the CSV header is written right away
+func NewEncoder(w io.Writer, format Format) (Encoder, error) {
+	switch format {
+	case FormatJSONL:
+		return jsonlEncoder{encoder: json.NewEncoder(w)}, nil
+	case FormatCSV:
+		c := csvEncoder{w: csv.NewWriter(w)}
+
+		return c, c.w.Write(Header)
+	}
+
+	return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+}
+
+func (e jsonlEncoder) Encode(rate currency.CurrencyWithID) error {
+	return e.encoder.Encode(rate)
+}
+
+func (e jsonlEncoder) Flush() error {
+	return nil
+}
+
+func (e csvEncoder) Encode(rate currency.CurrencyWithID) error {
+	return e.w.Write(Record(rate))
+}
+
+func (e csvEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// newDecoder decompresses gzip archives and detects the format when format is empty
+func newDecoder(r io.Reader, format Format) (decoder, error) {
+	buffered := bufio.NewReader(r)
+
+	if magic, err := buffered.Peek(len(gzipMagic)); err == nil && string(magic) == string(gzipMagic) {
+		gz, err := gzip.NewReader(buffered)
+
+		if err != nil {
+			return nil, err
+		}
+
+		buffered = bufio.NewReader(gz)
+	}
+
+	if format == "" {
+		format = detectFormat(buffered)
+	}
+
+	switch format {
+	case FormatJSONL:
+		return jsonlDecoder{decoder: json.NewDecoder(buffered)}, nil
+	case FormatCSV:
+		return newCSVDecoder(buffered)
+	}
+
+	return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+}
+
+// detectFormat returns FormatJSONL for archives starting with a JSON object, FormatCSV otherwise
+func detectFormat(r *bufio.Reader) Format {
+	for n := 1; ; n++ {
+		data, _ := r.Peek(n)
+
+		if len(data) < n {
+			return FormatJSONL
+		}
+
+		switch data[n-1] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{':
+			return FormatJSONL
+		}
+
+		return FormatCSV
+	}
+}
+
+func (d jsonlDecoder) Decode() (currency.CurrencyWithID, error) {
+	var rate currency.CurrencyWithID
+
+	if err := d.decoder.Decode(&rate); err != nil {
+		if errors.Is(err, io.EOF) {
+			return rate, io.EOF
+		}
+
+		return rate, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+	}
+
+	return rate, nil
+}
+
+func newCSVDecoder(r io.Reader) (decoder, error) {
+	d := csvDecoder{r: csv.NewReader(r), columns: make(map[string]int, len(Header))}
+	d.r.ReuseRecord = true
+
+	header, err := d.r.Read()
+
+	if errors.Is(err, io.EOF) {
+		return d, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+	}
+
+	for i, column := range header {
+		d.columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	// id is optional, storages generate missing IDs
+	for _, column := range Header[1:] {
+		if _, ok := d.columns[column]; !ok {
+			return nil, fmt.Errorf("%w: CSV header is missing column %s", ErrInvalidRecord, column)
+		}
+	}
+
+	return d, nil
+}
+
+func (d csvDecoder) Decode() (currency.CurrencyWithID, error) {
+	var rate currency.CurrencyWithID
+
+	// Empty archives have no header
+	if len(d.columns) == 0 {
+		return rate, io.EOF
+	}
+
+	record, err := d.r.Read()
+
+	if errors.Is(err, io.EOF) {
+		return rate, io.EOF
+	}
+
+	if err != nil {
+		return rate, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+	}
+
+	if i, ok := d.columns["id"]; ok && record[i] != "" {
+		rate.ID = record[i]
+	}
+
+	rate.From = record[d.columns["from"]]
+	rate.To = record[d.columns["to"]]
+	rate.Provider = currency.Provider(record[d.columns["provider"]])
+
+	if rate.Rate, err = decimal.NewFromString(record[d.columns["rate"]]); err != nil {
+		return rate, fmt.Errorf("%w: rate: %v", ErrInvalidRecord, err)
+	}
+
+	if rate.CreatedAt, err = time.Parse(time.RFC3339Nano, record[d.columns["created_at"]]); err != nil {
+		return rate, fmt.Errorf("%w: created_at: %v", ErrInvalidRecord, err)
+	}
+
+	return rate, nil
+}
@@ -0,0 +1,194 @@
+package archive_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/archive"
+	"github.com/malusev998/currency/storage"
+)
+
+var start = time.Date(2020, 10, 16, 8, 0, 0, 0, time.UTC)
+
+func newMemoryStorage(t *testing.T) currency.Storage {
+	st, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+	require.NoError(t, err)
+
+	return st
+}
+
+func newSQLiteStorage(t *testing.T) currency.Storage {
+	st, err := storage.NewSQLiteStorage(storage.SQLiteConfig{
+		BaseConfig: storage.BaseConfig{
+			Cxt:     context.Background(),
+			Migrate: true,
+		},
+		Path:      ":memory:",
+		TableName: "currency",
+	})
+	require.NoError(t, err)
+
+	return st
+}
+
+func seed(t *testing.T, st currency.Storage) []currency.CurrencyWithID {
+	currencies := make([]currency.Currency, 0)
+
+	for i := 0; i < 5; i++ {
+		createdAt := start.Add(time.Duration(i) * time.Hour)
+		currencies = append(currencies,
+			currency.Currency{From: "EUR", To: "USD", Provider: currency.ECBProvider, Rate: decimal.New(int64(110+i), -2), CreatedAt: createdAt},
+			currency.Currency{From: "EUR", To: "RSD", Provider: currency.NBSProvider, Rate: decimal.RequireFromString("117.58129384"), CreatedAt: createdAt},
+		)
+	}
+
+	stored, err := st.Store(currencies)
+	require.NoError(t, err)
+
+	return stored
+}
+
+func all(t *testing.T, st currency.Storage, pair string) []currency.CurrencyWithID {
+	codes := strings.Split(pair, "_")
+	rates, err := st.Get(codes[0], codes[1], 1, 100)
+	require.NoError(t, err)
+
+	return rates
+}
+
+func TestExportImport(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []archive.Format{archive.FormatJSONL, archive.FormatCSV} {
+		for _, gzip := range []bool{false, true} {
+			format, gzip := format, gzip
+
+			t.Run(fmt.Sprintf("%s_gzip_%v", format, gzip), func(t *testing.T) {
+				t.Parallel()
+				asserts := require.New(t)
+				source := newMemoryStorage(t)
+				destination := newSQLiteStorage(t)
+				seed(t, source)
+
+				var buf bytes.Buffer
+				progress := make([]archive.Progress, 0)
+
+				written, err := archive.Export(source, &buf, archive.ExportConfig{
+					Format:    format,
+					Gzip:      gzip,
+					BatchSize: 2,
+					Progress:  func(p archive.Progress) { progress = append(progress, p) },
+				})
+				asserts.NoError(err)
+				asserts.Equal(10, written)
+				asserts.Equal(archive.Progress{Pair: "EUR_RSD", Rates: 2}, progress[0])
+				asserts.Equal(archive.Progress{Pair: "EUR_USD", Rates: 10}, progress[len(progress)-1])
+
+				// Format and compression are detected
+				imported, err := archive.Import(destination, &buf, archive.ImportConfig{BatchSize: 3})
+				asserts.NoError(err)
+				asserts.Equal(10, imported)
+
+				for _, pair := range []string{"EUR_USD", "EUR_RSD"} {
+					expected := all(t, source, pair)
+					actual := all(t, destination, pair)
+					asserts.Len(actual, len(expected))
+
+					for i := range expected {
+						asserts.Equal(expected[i].ID, actual[i].ID)
+						asserts.Equal(expected[i].Provider, actual[i].Provider)
+						asserts.True(expected[i].Rate.Equal(actual[i].Rate))
+						asserts.True(expected[i].CreatedAt.Equal(actual[i].CreatedAt))
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestExport_Pairs(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	source := newMemoryStorage(t)
+	seed(t, source)
+
+	var buf bytes.Buffer
+
+	written, err := archive.Export(source, &buf, archive.ExportConfig{Pairs: []string{"EUR_USD"}})
+	asserts.NoError(err)
+	asserts.Equal(5, written)
+	asserts.Equal(5, strings.Count(buf.String(), "\n"))
+	asserts.NotContains(buf.String(), "RSD")
+
+	_, err = archive.Export(source, &buf, archive.ExportConfig{Pairs: []string{"EURUSD"}})
+	asserts.Error(err)
+
+	_, err = archive.Export(source, &buf, archive.ExportConfig{Format: "xml"})
+	asserts.True(errors.Is(err, archive.ErrUnknownFormat))
+}
+
+func TestImport_Resume(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	source := newMemoryStorage(t)
+	destination := newMemoryStorage(t)
+	seed(t, source)
+
+	var buf bytes.Buffer
+
+	_, err := archive.Export(source, &buf, archive.ExportConfig{Format: archive.FormatCSV})
+	asserts.NoError(err)
+
+	// The first 6 rates were imported before the import was interrupted
+	imported, err := archive.Import(destination, &buf, archive.ImportConfig{Format: archive.FormatCSV, Skip: 6, BatchSize: 3})
+	asserts.NoError(err)
+	asserts.Equal(10, imported)
+	asserts.Empty(all(t, destination, "EUR_RSD"))
+	asserts.Len(all(t, destination, "EUR_USD"), 4)
+}
+
+func TestImport_InvalidRecord(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"JSON", `{"id":null,"rate":"1.1","from":"EUR","to":"USD","provider":"ECB","created_at":"2020-10-16T08:00:00Z"}` + "\n{\n"},
+		{"MissingProvider", `{"rate":"1.1","from":"EUR","to":"USD","created_at":"2020-10-16T08:00:00Z"}`},
+		{"MissingColumn", "id,from,to,rate,created_at\n,EUR,USD,1.1,2020-10-16T08:00:00Z\n"},
+		{"Rate", "id,from,to,provider,rate,created_at\n,EUR,USD,ECB,abc,2020-10-16T08:00:00Z\n"},
+		{"CreatedAt", "id,from,to,provider,rate,created_at\n,EUR,USD,ECB,1.1,16.10.2020\n"},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			asserts := require.New(t)
+
+			_, err := archive.Import(newMemoryStorage(t), strings.NewReader(test.data), archive.ImportConfig{})
+			asserts.True(errors.Is(err, archive.ErrInvalidRecord), "expected archive.ErrInvalidRecord, got %v", err)
+		})
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+
+	asserts.Equal(archive.FormatCSV, archive.FormatFromPath("rates.CSV.gz"))
+	asserts.Equal(archive.FormatCSV, archive.FormatFromPath("rates.csv"))
+	asserts.Equal(archive.FormatJSONL, archive.FormatFromPath("rates.jsonl.gz"))
+	asserts.Equal(archive.FormatJSONL, archive.FormatFromPath("-"))
+}
@@ -0,0 +1,120 @@
+package archive
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/iso4217"
+)
+
+var ErrNoPairs = errors.New("storage cannot list stored pairs, pairs have to be set")
+
+type ExportConfig struct {
+	// Format defaults to FormatJSONL
+	Format Format
+	// Gzip compresses the archive
+	Gzip bool
+	// Pairs to export, e.g. EUR_RSD, every stored pair when empty if the storage implements currency.PairLister
+	Pairs []string
+	// BatchSize is the number of rates read from the storage per query, DefaultBatchSize when zero
+	BatchSize int64
+	// Progress is called after every written batch
+	Progress func(Progress)
+}
+
+// pairs returns configured pairs or every pair stored in s
+func (c ExportConfig) pairs(s currency.Storage) ([]string, error) {
+	if len(c.Pairs) != 0 {
+		return c.Pairs, nil
+	}
+
+	lister, ok := s.(currency.PairLister)
+
+	if !ok {
+		lister, ok = currency.UnwrapStorage(s).(currency.PairLister)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoPairs, s.GetStorageProviderName())
+	}
+
+	return lister.Pairs()
+}
+
+// Export writes every rate of the pairs stored in s to w, pairs are written one after another
+// and rates of a pair in the order they were created. It returns the number of written rates.
+func Export(s currency.Storage, w io.Writer, config ExportConfig) (int, error) {
+	if config.Format == "" {
+		config.Format = FormatJSONL
+	}
+
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+
+	pairs, err := config.pairs(s)
+
+	if err != nil {
+		return 0, err
+	}
+
+	var gz *gzip.Writer
+
+	if config.Gzip {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+
+	e, err := NewEncoder(w, config.Format)
+
+	if err != nil {
+		return 0, err
+	}
+
+	written := 0
+
+	for _, value := range pairs {
+		pair, err := iso4217.SplitPair(value)
+
+		if err != nil {
+			return written, err
+		}
+
+		for page := int64(1); ; page++ {
+			rates, err := s.Get(pair.From, pair.To, page, config.BatchSize)
+
+			if err != nil {
+				return written, fmt.Errorf("error while reading %s rates: %w", pair, err)
+			}
+
+			for _, rate := range rates {
+				if err := e.Encode(rate); err != nil {
+					return written, err
+				}
+
+				written++
+			}
+
+			if len(rates) != 0 && config.Progress != nil {
+				config.Progress(Progress{Pair: value, Rates: written})
+			}
+
+			if int64(len(rates)) < config.BatchSize {
+				break
+			}
+		}
+	}
+
+	if err := e.Flush(); err != nil {
+		return written, err
+	}
+
+	if gz != nil {
+		return written, gz.Close()
+	}
+
+	return written, nil
+}
@@ -0,0 +1,101 @@
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/malusev998/currency"
+)
+
+type ImportConfig struct {
+	// Format is detected from the archive when empty, gzip compression is always detected
+	Format Format
+	// BatchSize is the number of rates stored at once, DefaultBatchSize when zero
+	BatchSize int
+	// Skip is the number of rates already imported, Progress.Rates of an interrupted import
+	Skip int
+	// Progress is called after every stored batch
+	Progress func(Progress)
+}
+
+func validRecord(rate currency.CurrencyWithID) error {
+	switch {
+	case rate.From == "" || rate.To == "":
+		return fmt.Errorf("%w: missing currency", ErrInvalidRecord)
+	case rate.Provider == "":
+		return fmt.Errorf("%w: missing provider", ErrInvalidRecord)
+	case rate.CreatedAt.IsZero():
+		return fmt.Errorf("%w: missing created_at", ErrInvalidRecord)
+	}
+
+	return nil
+}
+
+// Import stores rates read from r in s in batches. A failed import can be resumed
+// with Skip set to the last reported Progress.Rates, batches are stored whole or not at all
+// by SQL storages. It returns the number of stored rates, including skipped ones.
+func Import(s currency.Storage, r io.Reader, config ImportConfig) (int, error) {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+
+	d, err := newDecoder(r, config.Format)
+
+	if err != nil {
+		return 0, err
+	}
+
+	batch := make([]currency.CurrencyWithID, 0, config.BatchSize)
+	read, stored := 0, 0
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if _, err := currency.CopyRates(s, batch); err != nil {
+			return fmt.Errorf("error while storing rates %d to %d: %w", stored+1, stored+len(batch), err)
+		}
+
+		stored += len(batch)
+		batch = batch[:0]
+
+		if config.Progress != nil {
+			config.Progress(Progress{Rates: stored})
+		}
+
+		return nil
+	}
+
+	for {
+		rate, err := d.Decode()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		read++
+
+		if err == nil {
+			err = validRecord(rate)
+		}
+
+		if err != nil {
+			return stored, fmt.Errorf("rate %d: %w", read, err)
+		}
+
+		if read <= config.Skip {
+			stored = read
+			continue
+		}
+
+		if batch = append(batch, rate); len(batch) == config.BatchSize {
+			if err := flush(); err != nil {
+				return stored, err
+			}
+		}
+	}
+
+	return stored, flush()
+}
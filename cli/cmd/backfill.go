@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/malusev998/currency"
+)
+
+const dateFormat = "2006-01-02"
+
+var errBackfillFailed = errors.New("backfill failed")
+
+func handleCurrencyBackfill(config *Config, start, end time.Time, logger *log.Logger) []error {
+	errs := make([]error, 0)
+
+	for _, service := range config.CurrencyService {
+		historicalService, ok := service.(currency.HistoricalService)
+
+		if !ok {
+			if *config.debug {
+				logger.Printf("Skipping service %T, it does not support historical rates\n", service)
+			}
+
+			continue
+		}
+
+		// Rates saved to storages that succeeded are returned with the error
+		currenciesMap, err := historicalService.SaveByDateRange(config.CurrenciesToFetch, start, end)
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		for storage, currencies := range currenciesMap {
+			logger.Printf("%d rates saved to %s\n", len(currencies), storage)
+
+			if *config.debug {
+				for i, c := range currencies {
+					logger.Printf("%d\tCurrency %s_%s (%s) saved to %s: Rate: %s\n", i, c.From, c.To, c.CreatedAt.Format(dateFormat), storage, c.Rate)
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+func backfill(config *Config) *cobra.Command {
+	var from, to string
+
+	backfillCmd := &cobra.Command{
+		Use:   "backfill",
+		Short: "Fetch and store historical rates between --from and --to",
+	}
+
+	backfillCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		logger := log.New(cmd.OutOrStdout(), "backfill ", 0)
+		errLogger := log.New(cmd.ErrOrStderr(), "backfill-error ", 0)
+
+		start, err := time.Parse(dateFormat, from)
+
+		if err != nil {
+			return fmt.Errorf("invalid --from date, expected format %s: %v", dateFormat, err)
+		}
+
+		end, err := time.Parse(dateFormat, to)
+
+		if err != nil {
+			return fmt.Errorf("invalid --to date, expected format %s: %v", dateFormat, err)
+		}
+
+		if start.After(end) {
+			return fmt.Errorf("--from (%s) cannot be after --to (%s)", from, to)
+		}
+
+		errs := handleCurrencyBackfill(config, start, end, logger)
+
+		if len(errs) == 0 {
+			return nil
+		}
+
+		messages := make([]string, 0, len(errs))
+
+		for _, err := range errs {
+			errLogger.Printf("ERROR: %v", err)
+			messages = append(messages, err.Error())
+		}
+
+		cmd.SilenceUsage = true
+
+		return fmt.Errorf("%w: %s", errBackfillFailed, strings.Join(messages, "; "))
+	}
+
+	backfillCmd.Flags().StringVar(&from, "from", "", "First day to fetch (YYYY-MM-DD)")
+	backfillCmd.Flags().StringVar(&to, "to", time.Now().Format(dateFormat), "Last day to fetch (YYYY-MM-DD)")
+	_ = backfillCmd.MarkFlagRequired("from")
+
+	return backfillCmd
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	currencyFetcher "github.com/malusev998/currency"
+)
+
+// serviceStub fetches current rates only
+type serviceStub struct{}
+
+func (serviceStub) Save([]string) (map[string][]currencyFetcher.CurrencyWithID, error) {
+	return nil, nil
+}
+
+type historicalServiceStub struct {
+	start, end time.Time
+	err        error
+}
+
+func (h *historicalServiceStub) Save([]string) (map[string][]currencyFetcher.CurrencyWithID, error) {
+	return nil, nil
+}
+
+func (h *historicalServiceStub) SaveByDateRange(currenciesToFetch []string, start, end time.Time) (map[string][]currencyFetcher.CurrencyWithID, error) {
+	h.start, h.end = start, end
+
+	return map[string][]currencyFetcher.CurrencyWithID{
+		"stub": {{ID: 1, Currency: currencyFetcher.Currency{From: "EUR", To: "USD", Rate: decimal.RequireFromString("1.17"), CreatedAt: start}}},
+	}, h.err
+}
+
+func TestBackfillCommand(t *testing.T) {
+	t.Parallel()
+	debug := false
+
+	t.Run("Passes date range to services", func(t *testing.T) {
+		asserts := require.New(t)
+		service := &historicalServiceStub{}
+		config := Config{
+			Ctx:               context.Background(),
+			debug:             &debug,
+			CurrenciesToFetch: []string{"EUR_USD"},
+			CurrencyService:   []currencyFetcher.Service{service},
+		}
+
+		var out bytes.Buffer
+		cmd := backfill(&config)
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"--from", "2020-10-01", "--to", "2020-10-07"})
+
+		asserts.Nil(cmd.Execute())
+		asserts.Equal(time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC), service.start)
+		asserts.Equal(time.Date(2020, 10, 7, 0, 0, 0, 0, time.UTC), service.end)
+	})
+
+	t.Run("Returns service errors", func(t *testing.T) {
+		asserts := require.New(t)
+		storageErr := errors.New("storage unavailable")
+		config := Config{
+			Ctx:               context.Background(),
+			debug:             &debug,
+			CurrenciesToFetch: []string{"EUR_USD"},
+			CurrencyService: []currencyFetcher.Service{
+				&historicalServiceStub{err: storageErr},
+				&historicalServiceStub{},
+			},
+		}
+
+		var errOut bytes.Buffer
+		cmd := backfill(&config)
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&errOut)
+		cmd.SetArgs([]string{"--from", "2020-10-01", "--to", "2020-10-07"})
+
+		err := cmd.Execute()
+
+		asserts.True(errors.Is(err, errBackfillFailed))
+		asserts.Contains(err.Error(), storageErr.Error())
+	})
+
+	t.Run("Skips services without historical rates", func(t *testing.T) {
+		asserts := require.New(t)
+		verbose := true
+		config := Config{
+			Ctx:               context.Background(),
+			debug:             &verbose,
+			CurrenciesToFetch: []string{"EUR_USD"},
+			CurrencyService:   []currencyFetcher.Service{serviceStub{}},
+		}
+
+		var out bytes.Buffer
+		cmd := backfill(&config)
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"--from", "2020-10-01"})
+
+		asserts.Nil(cmd.Execute())
+		asserts.Contains(out.String(), "does not support historical rates")
+	})
+
+	t.Run("Invalid range", func(t *testing.T) {
+		asserts := require.New(t)
+		config := Config{Ctx: context.Background(), debug: &debug}
+
+		cmd := backfill(&config)
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--from", "2020-10-07", "--to", "2020-10-01"})
+
+		asserts.Error(cmd.Execute())
+	})
+}
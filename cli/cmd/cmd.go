@@ -7,6 +7,8 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/fetchers"
+	"github.com/malusev998/currency/services"
 )
 
 var (
@@ -24,6 +26,12 @@ type (
 		Ctx               context.Context
 		CurrenciesToFetch []string
 		CurrencyService   []currency.Service
+		Storages          []currency.Storage
+		Conversion        services.ConversionService
+		HTTPAddr          string
+		GRPCAddr          string
+		MetricsAddr       string
+		Quotas            []*fetchers.QuotaTracker
 		debug             *bool
 	}
 )
@@ -47,6 +55,14 @@ func Execute(config *Config) error {
 	config.debug = &debug
 
 	rootCmd.AddCommand(fetch(config))
+	rootCmd.AddCommand(backfill(config))
+	rootCmd.AddCommand(serve(config))
+	rootCmd.AddCommand(grpcServe(config))
+	rootCmd.AddCommand(convert(config))
+	rootCmd.AddCommand(history(config))
+	rootCmd.AddCommand(exportRates(config))
+	rootCmd.AddCommand(importRates(config))
+	rootCmd.AddCommand(reconcile(config))
 
 	return rootCmd.Execute()
 }
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+
+	"github.com/malusev998/currency/iso4217"
+	"github.com/malusev998/currency/rest"
+	"github.com/malusev998/currency/services"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// parseDate accepts dateFormat and RFC 3339, empty value returns zero time.
+// Dates without time are moved to the end of the day like in the HTTP API.
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if date, err := time.Parse(dateFormat, value); err == nil {
+		return date.Add(24*time.Hour - time.Nanosecond), nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+func printConversion(w io.Writer, output string, response rest.ConvertResponse) error {
+	if output == OutputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(response)
+	}
+
+	value := response.Value.String()
+
+	if to, err := iso4217.Lookup(response.To); err == nil {
+		value = response.Value.StringFixed(int32(to.MinorUnits))
+	}
+
+	_, err := fmt.Fprintf(w, "%s %s = %s %s\nRate:     %s\nProvider: %s\nDate:     %s\n",
+		response.Amount, response.From, value, response.To,
+		response.Rate.Rate, response.Rate.Provider, response.Rate.CreatedAt.Format(time.RFC3339),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	// Stored rates are their own only leg, legs are printed for inverted and triangulated rates
+	if response.Rate.ID != nil {
+		return nil
+	}
+
+	for _, leg := range response.Legs {
+		_, err = fmt.Fprintf(w, "Via:      %s_%s %s (%s, %s)\n", leg.From, leg.To, leg.Rate, leg.Provider, leg.CreatedAt.Format(time.RFC3339))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func convert(config *Config) *cobra.Command {
+	var date, provider, output string
+
+	convertCmd := &cobra.Command{
+		Use:   "convert AMOUNT FROM TO",
+		Short: "Convert an amount using rates from the configured storages",
+		Args:  cobra.ExactArgs(3),
+	}
+
+	convertCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		amount, err := decimal.NewFromString(args[0])
+
+		if err != nil {
+			return fmt.Errorf("invalid amount %q: %v", args[0], err)
+		}
+
+		pair, err := iso4217.ParsePair(args[1] + iso4217.PairSeparator + args[2])
+
+		if err != nil {
+			return err
+		}
+
+		at, err := parseDate(date)
+
+		if err != nil {
+			return fmt.Errorf("invalid --date, expected format %s or RFC 3339: %v", dateFormat, err)
+		}
+
+		p, err := services.ParseProvider(provider)
+
+		if err != nil {
+			return fmt.Errorf("invalid --provider: %v", err)
+		}
+
+		output = strings.ToLower(output)
+
+		if output != OutputText && output != OutputJSON {
+			return fmt.Errorf("invalid --output %q, expected %s or %s", output, OutputText, OutputJSON)
+		}
+
+		// Arguments are valid, usage is not printed for lookup errors
+		cmd.SilenceUsage = true
+
+		conversion := config.Conversion
+		conversion.Ctx = config.Ctx
+
+		if len(conversion.Storages) == 0 {
+			conversion.Storages = config.Storages
+		}
+
+		result, err := conversion.ConvertWithRate(pair.From, pair.To, p, amount, at)
+
+		if errors.Is(err, services.ErrCurrencyNotFound) {
+			if at.IsZero() {
+				return fmt.Errorf("no %s rate is stored, fetch it first: %w", pair, err)
+			}
+
+			return fmt.Errorf("no %s rate is stored for %s: %w", pair, date, err)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return printConversion(cmd.OutOrStdout(), output, rest.ConvertResponse{
+			From:   pair.From,
+			To:     pair.To,
+			Amount: amount,
+			Value:  result.Value,
+			Rate:   result.Rate,
+			Legs:   result.Legs,
+		})
+	}
+
+	convertCmd.Flags().StringVar(&date, "date", "", "Convert with the rate of the date (YYYY-MM-DD or RFC 3339), latest rate when empty")
+	convertCmd.Flags().StringVar(&provider, "provider", "", "Use rates of the provider only")
+	convertCmd.Flags().StringVarP(&output, "output", "o", OutputText, "Output format, text or json")
+
+	return convertCmd
+}
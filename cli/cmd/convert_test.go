@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/rest"
+	"github.com/malusev998/currency/services"
+	"github.com/malusev998/currency/storage"
+)
+
+func TestConvertCommand(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2020, 10, 1, 14, 0, 0, 0, time.UTC)
+	memory, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+	require.Nil(t, err)
+
+	_, err = memory.Store([]currencyFetcher.Currency{
+		{From: "EUR", To: "RSD", Provider: currencyFetcher.NBSProvider, Rate: decimal.RequireFromString("117.58"), CreatedAt: createdAt},
+	})
+	require.Nil(t, err)
+
+	newConfig := func() *Config {
+		return &Config{Ctx: context.Background(), Storages: []currencyFetcher.Storage{memory}}
+	}
+
+	execute := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := convert(newConfig())
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+
+		return out.String(), err
+	}
+
+	t.Run("Text", func(t *testing.T) {
+		asserts := require.New(t)
+
+		out, err := execute("100", "eur", "rsd")
+
+		asserts.Nil(err)
+		asserts.Equal("100 EUR = 11758.00 RSD\nRate:     117.58\nProvider: NBS\nDate:     2020-10-01T14:00:00Z\n", out)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		asserts := require.New(t)
+
+		out, err := execute("10", "EUR", "RSD", "--date", "2020-10-01", "--provider", "nbs", "--output", "json")
+
+		asserts.Nil(err)
+
+		var response rest.ConvertResponse
+		asserts.Nil(json.Unmarshal([]byte(out), &response))
+		asserts.Equal("1175.8", response.Value.String())
+		asserts.Equal(currencyFetcher.NBSProvider, response.Rate.Provider)
+		asserts.True(createdAt.Equal(response.Rate.CreatedAt))
+	})
+
+	t.Run("Inverted", func(t *testing.T) {
+		asserts := require.New(t)
+
+		out, err := execute("1000", "RSD", "EUR")
+
+		asserts.Nil(err)
+		asserts.Contains(out, "1000 RSD = 8.50 EUR\n")
+		asserts.Contains(out, "Via:      EUR_RSD 117.58 (NBS, 2020-10-01T14:00:00Z)\n")
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		asserts := require.New(t)
+
+		_, err := execute("100", "EUR", "USD")
+
+		asserts.True(errors.Is(err, services.ErrCurrencyNotFound))
+		asserts.Contains(err.Error(), "no EUR_USD rate is stored")
+	})
+
+	t.Run("InvalidArguments", func(t *testing.T) {
+		asserts := require.New(t)
+
+		_, err := execute("abc", "EUR", "RSD")
+		asserts.Error(err)
+
+		_, err = execute("100", "EUR", "XYZ")
+		asserts.Error(err)
+
+		_, err = execute("100", "EUR", "RSD", "--output", "xml")
+		asserts.Error(err)
+
+		_, err = execute("100", "EUR", "RSD", "--date", "01.10.2020")
+		asserts.Error(err)
+
+		_, err = execute("100", "EUR")
+		asserts.Error(err)
+	})
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/malusev998/currency/archive"
+	"github.com/malusev998/currency/iso4217"
+	"github.com/malusev998/currency/services"
+)
+
+// stdio is the --file value for standard input and output
+const stdio = "-"
+
+func exportRates(config *Config) *cobra.Command {
+	var file, format, storageName string
+	var pairs []string
+	var compress bool
+	var batchSize int64
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write every stored rate to a JSONL or CSV archive, which can be loaded with import",
+	}
+
+	exportCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		logger := log.New(cmd.ErrOrStderr(), "export ", 0)
+		exportConfig := archive.ExportConfig{
+			Format:    archive.FormatFromPath(file),
+			Gzip:      compress || strings.HasSuffix(strings.ToLower(file), ".gz"),
+			BatchSize: batchSize,
+			Progress: func(p archive.Progress) {
+				logger.Printf("%d rates exported (%s)\n", p.Rates, p.Pair)
+			},
+		}
+
+		if format != "" {
+			f, err := archive.ConvertToFormatFromString(format)
+
+			if err != nil {
+				return fmt.Errorf("invalid --format: %w", err)
+			}
+
+			exportConfig.Format = f
+		}
+
+		if len(pairs) != 0 {
+			parsed, err := iso4217.ParsePairs(pairs)
+
+			if err != nil {
+				return fmt.Errorf("invalid --pairs: %w", err)
+			}
+
+			exportConfig.Pairs = iso4217.Strings(parsed)
+		}
+
+		if batchSize <= 0 {
+			return fmt.Errorf("--batch-size has to be positive")
+		}
+
+		storage, err := services.FindStorage(config.Storages, storageName)
+
+		if err != nil {
+			return err
+		}
+
+		cmd.SilenceUsage = true
+
+		var w io.Writer = cmd.OutOrStdout()
+		var out *os.File
+
+		if file != stdio {
+			if out, err = os.Create(file); err != nil {
+				return err
+			}
+
+			defer out.Close()
+
+			w = out
+		}
+
+		written, err := archive.Export(storage, w, exportConfig)
+
+		if err != nil {
+			return fmt.Errorf("error while exporting rates from %s after %d rates: %w", storage.GetStorageProviderName(), written, err)
+		}
+
+		// Close errors mean the archive was not completely written
+		if out != nil {
+			if err := out.Close(); err != nil {
+				return err
+			}
+		}
+
+		logger.Printf("%d rates exported from %s\n", written, storage.GetStorageProviderName())
+
+		return nil
+	}
+
+	exportCmd.Flags().StringVarP(&file, "file", "f", stdio, "Archive to write, standard output when -")
+	exportCmd.Flags().StringVar(&format, "format", "", "Archive format, jsonl or csv, guessed from --file when empty")
+	exportCmd.Flags().BoolVar(&compress, "gzip", false, "Compress the archive, always compressed when --file ends with .gz")
+	exportCmd.Flags().StringVar(&storageName, "storage", "", "Storage to export, the first configured storage when empty")
+	exportCmd.Flags().StringSliceVar(&pairs, "pairs", nil, "Pairs to export (EUR_RSD,EUR_USD), every stored pair when empty")
+	exportCmd.Flags().Int64Var(&batchSize, "batch-size", archive.DefaultBatchSize, "Rates read from the storage per query")
+
+	return exportCmd
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/storage"
+)
+
+func TestExportImportCommands(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	debug := false
+	dir, err := ioutil.TempDir("", "currency-archive")
+	asserts.Nil(err)
+
+	defer os.RemoveAll(dir)
+
+	source, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+	asserts.Nil(err)
+	destination, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+	asserts.Nil(err)
+
+	rates := make([]currencyFetcher.Currency, 0, 6)
+
+	for day := 1; day <= 3; day++ {
+		createdAt := time.Date(2020, 10, day, 14, 0, 0, 0, time.UTC)
+		rates = append(rates,
+			currencyFetcher.Currency{From: "EUR", To: "RSD", Provider: currencyFetcher.NBSProvider, Rate: decimal.RequireFromString("117.58"), CreatedAt: createdAt},
+			currencyFetcher.Currency{From: "EUR", To: "USD", Provider: currencyFetcher.ECBProvider, Rate: decimal.RequireFromString("1.17"), CreatedAt: createdAt},
+		)
+	}
+
+	stored, err := source.Store(rates)
+	asserts.Nil(err)
+
+	execute := func(cmd func(*Config) *cobra.Command, storages []currencyFetcher.Storage, args ...string) (string, error) {
+		var errOut bytes.Buffer
+		c := cmd(&Config{Ctx: context.Background(), debug: &debug, Storages: storages})
+		c.SetOut(&bytes.Buffer{})
+		c.SetErr(&errOut)
+		c.SetArgs(args)
+
+		err := c.Execute()
+
+		return errOut.String(), err
+	}
+
+	file := filepath.Join(dir, "rates.csv.gz")
+	checkpoint := filepath.Join(dir, "checkpoint")
+
+	out, err := execute(exportRates, []currencyFetcher.Storage{source}, "--file", file, "--batch-size", "2")
+	asserts.Nil(err)
+	asserts.Contains(out, "6 rates exported from memory")
+
+	// Rates of the first batch were imported before
+	asserts.Nil(writeCheckpoint(checkpoint, 2))
+
+	out, err = execute(importRates, []currencyFetcher.Storage{destination}, "--file", file, "--checkpoint", checkpoint, "--batch-size", "2")
+	asserts.Nil(err)
+	asserts.Contains(out, "skipping 2 rates imported before")
+	asserts.Contains(out, "4 rates imported to memory")
+
+	_, err = os.Stat(checkpoint)
+	asserts.True(os.IsNotExist(err))
+
+	rsd, err := destination.Get("EUR", "RSD", 1, 10)
+	asserts.Nil(err)
+	asserts.Len(rsd, 1)
+	asserts.Equal(stored[4].ID, rsd[0].ID)
+
+	usd, err := destination.Get("EUR", "USD", 1, 10)
+	asserts.Nil(err)
+	asserts.Len(usd, 3)
+
+	_, err = execute(exportRates, []currencyFetcher.Storage{source}, "--pairs", "EUR_XYZ")
+	asserts.NotNil(err)
+
+	_, err = execute(importRates, []currencyFetcher.Storage{destination}, "--format", "xml")
+	asserts.NotNil(err)
+}
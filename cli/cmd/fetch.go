@@ -1,54 +1,116 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
+
+	"github.com/malusev998/currency"
 )
 
 func handleCurrencySave(config *Config, logger *log.Logger) []error {
 	errs := make([]error, 0)
 
 	for _, service := range config.CurrencyService {
-		currenciesMap, err := service.Save(config.CurrenciesToFetch)
+		result, err := currency.SaveWithResult(service, config.CurrenciesToFetch)
 
 		if err != nil {
 			errs = append(errs, err)
 		}
 
 		if *config.debug {
-			for storage, currencies := range currenciesMap {
-				for i, currency := range currencies {
-					logger.Printf("%d\tCurrency %s_%s saved to %s: Rate: %f\n", i, currency.From, currency.To, storage, currency.Rate)
-				}
+			logSaveResult(result, logger)
+		}
+	}
+
+	if *config.debug {
+		for _, quota := range config.Quotas {
+			status, err := quota.Status()
+
+			if err != nil {
+				errs = append(errs, err)
+				continue
 			}
+
+			logger.Printf("Quota %s: %d of %d requests remaining, resets at %s\n", status.Provider, status.Remaining, status.Limit, status.ResetsAt.Format(time.RFC3339))
 		}
 	}
 
 	return errs
 }
 
+// logSaveResult logs rates saved to every storage, failed storages are logged
+// even when the service save policy is met
+func logSaveResult(result currency.SaveResult, logger *log.Logger) {
+	for _, storage := range result.Storages {
+		if storage.Err != nil {
+			logger.Printf("Storage %s failed: %v\n", storage.Storage, storage.Err)
+			continue
+		}
+
+		logger.Printf("Storage %s: %d rates saved\n", storage.Storage, len(storage.Currencies))
+
+		for i, c := range storage.Currencies {
+			logger.Printf("%d\tCurrency %s_%s saved to %s: Rate: %s\n", i, c.From, c.To, storage.Storage, c.Rate)
+		}
+	}
+}
+
+// serveMetrics exposes metrics registered on the default Prometheus registry
+// on addr until ctx is done
+func serveMetrics(ctx context.Context, addr string, errLogger *log.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errLogger.Printf("ERROR: metrics listener: %v", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		_ = server.Shutdown(shutdownCtx)
+	}()
+}
+
 func fetchCobraCommand(
-	standalone bool,
-	after time.Duration,
+	standalone *bool,
+	after *time.Duration,
+	metricsAddr *string,
 	config *Config, logger,
 	errLogger *log.Logger,
 ) func(cmd *cobra.Command, args []string) {
 	return func(cmd *cobra.Command, args []string) {
+		if *standalone && *metricsAddr != "" {
+			serveMetrics(config.Ctx, *metricsAddr, errLogger)
+			logger.Printf("Serving metrics on %s/metrics\n", *metricsAddr)
+		}
+
 		if errs := handleCurrencySave(config, logger); len(errs) != 0 {
 			for _, err := range errs {
 				errLogger.Printf("ERROR: %v", err)
 			}
 		}
 
-		if !standalone {
+		if !*standalone {
 			return
 		}
 
 		for {
 			select {
-			case <-time.After(after):
+			case <-time.After(*after):
 				if errs := handleCurrencySave(config, logger); len(errs) != 0 {
 					for _, err := range errs {
 						errLogger.Printf("ERROR: %v", err)
@@ -64,6 +126,7 @@ func fetchCobraCommand(
 func fetch(config *Config) *cobra.Command {
 	var standalone bool
 	var after time.Duration
+	var metricsAddr string
 
 	fetchCmd := &cobra.Command{
 		Use: "fetch",
@@ -72,9 +135,10 @@ func fetch(config *Config) *cobra.Command {
 	logger := log.New(fetchCmd.OutOrStdout(), "fetch ", 0)
 	errLogger := log.New(fetchCmd.OutOrStderr(), "fetch-error ", 0)
 
-	fetchCmd.Run = fetchCobraCommand(standalone, after, config, logger, errLogger)
+	fetchCmd.Run = fetchCobraCommand(&standalone, &after, &metricsAddr, config, logger, errLogger)
 	fetchCmd.Flags().BoolVar(&standalone, "standalone", false, "Start up a long running fetching service")
 	fetchCmd.Flags().DurationVar(&after, "after", time.Duration(1)*time.Hour, "Fetching for standalone process")
+	fetchCmd.Flags().StringVar(&metricsAddr, "metrics-addr", config.MetricsAddr, "Address of the Prometheus /metrics listener in standalone mode, disabled when empty")
 
 	return fetchCmd
 }
@@ -1,16 +1,20 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
 
 	"github.com/go-sql-driver/mysql"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 
 	currencyFetcher "github.com/malusev998/currency"
@@ -157,3 +161,89 @@ func TestFetchCommand(t *testing.T) {
 		testMySQLDataSet(asserts, rows)
 	})
 }
+
+func TestHandleCurrencySave_QuotaDebug(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	debug := true
+	var out bytes.Buffer
+
+	quota := &fetchers.QuotaTracker{
+		Storage:  storage.NewMemoryQuotaStorage(),
+		Provider: currencyFetcher.FreeConvProvider,
+		Limit:    100,
+	}
+	asserts.Nil(quota.Reserve(40))
+
+	config := Config{Ctx: context.Background(), debug: &debug, Quotas: []*fetchers.QuotaTracker{quota}}
+
+	asserts.Empty(handleCurrencySave(&config, log.New(&out, "", 0)))
+	asserts.Contains(out.String(), "Quota FreeCurrConversion: 60 of 100 requests remaining")
+
+	debug = false
+	out.Reset()
+
+	asserts.Empty(handleCurrencySave(&config, log.New(&out, "", 0)))
+	asserts.Empty(out.String())
+}
+
+type (
+	ratesFetcherStub struct {
+		currencies []currencyFetcher.Currency
+	}
+
+	failingStorage struct {
+		currencyFetcher.Storage
+	}
+)
+
+func (f ratesFetcherStub) Fetch([]string) ([]currencyFetcher.Currency, error) {
+	return f.currencies, nil
+}
+
+func (failingStorage) Store([]currencyFetcher.Currency) ([]currencyFetcher.CurrencyWithID, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingStorage) GetStorageProviderName() string {
+	return storage.MySQLStorageProviderName
+}
+
+func TestHandleCurrencySave_StorageResultsDebug(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	debug := true
+	var out bytes.Buffer
+
+	memory, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+	asserts.Nil(err)
+
+	service := services.Service{
+		Fetcher: ratesFetcherStub{currencies: []currencyFetcher.Currency{
+			{From: "EUR", To: "USD", Provider: currencyFetcher.ECBProvider, Rate: decimal.RequireFromString("1.17")},
+		}},
+		Storage: []currencyFetcher.Storage{failingStorage{}, memory},
+		Policy:  services.SaveAtLeastOne,
+	}
+
+	config := Config{
+		Ctx:               context.Background(),
+		debug:             &debug,
+		CurrenciesToFetch: []string{"EUR_USD"},
+		CurrencyService:   []currencyFetcher.Service{service},
+	}
+
+	asserts.Empty(handleCurrencySave(&config, log.New(&out, "", 0)))
+	asserts.Contains(out.String(), "Storage mysql failed: connection refused\n")
+	asserts.Contains(out.String(), "Storage memory: 1 rates saved\n")
+	asserts.Contains(out.String(), "0\tCurrency EUR_USD saved to memory: Rate: 1.17\n")
+
+	service.Policy = services.SaveAll
+	config.CurrencyService = []currencyFetcher.Service{service}
+	out.Reset()
+
+	errs := handleCurrencySave(&config, log.New(&out, "", 0))
+	asserts.Len(errs, 1)
+	asserts.Equal("1 of 2 storages failed (policy all): mysql: connection refused", errs[0].Error())
+	asserts.Contains(out.String(), "Storage memory: 1 rates saved\n")
+}
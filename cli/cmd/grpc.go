@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"log"
+	"net"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+
+	"github.com/malusev998/currency/rpc"
+)
+
+const DefaultGRPCAddr = ":9090"
+
+func grpcServe(config *Config) *cobra.Command {
+	var (
+		addr         string
+		pollInterval time.Duration
+	)
+
+	grpcCmd := &cobra.Command{
+		Use:   "grpc",
+		Short: "Serve stored rates, conversions and rate updates over gRPC",
+	}
+
+	grpcCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		logger := log.New(cmd.OutOrStdout(), "grpc ", 0)
+		listener, err := net.Listen("tcp", addr)
+
+		if err != nil {
+			return err
+		}
+
+		server := grpc.NewServer()
+
+		rpc.NewServer(rpc.Config{
+			Storages:     config.Storages,
+			Conversion:   config.Conversion,
+			PollInterval: pollInterval,
+		}).Register(server)
+
+		errChannel := make(chan error, 1)
+
+		go func() {
+			errChannel <- server.Serve(listener)
+		}()
+
+		logger.Printf("Listening on %s\n", listener.Addr())
+
+		select {
+		case err := <-errChannel:
+			return err
+		case <-config.Ctx.Done():
+			stopped := make(chan struct{})
+
+			go func() {
+				server.GracefulStop()
+				close(stopped)
+			}()
+
+			// Open WatchRates streams end with the request context,
+			// they are cut off when graceful stop takes too long
+			select {
+			case <-stopped:
+			case <-time.After(shutdownTimeout):
+				server.Stop()
+			}
+
+			return nil
+		}
+	}
+
+	defaultAddr := config.GRPCAddr
+
+	if defaultAddr == "" {
+		defaultAddr = DefaultGRPCAddr
+	}
+
+	grpcCmd.Flags().StringVar(&addr, "addr", defaultAddr, "Address the gRPC server listens on")
+	grpcCmd.Flags().DurationVar(&pollInterval, "poll-interval", rpc.DefaultPollInterval, "How often streamed rates are checked for updates")
+
+	return grpcCmd
+}
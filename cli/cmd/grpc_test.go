@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGRPCCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Shuts down when context is done", func(t *testing.T) {
+		asserts := require.New(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		config := Config{Ctx: ctx}
+
+		var out bytes.Buffer
+		cmd := grpcServe(&config)
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"--addr", "127.0.0.1:0"})
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+		}()
+
+		asserts.Nil(cmd.Execute())
+		asserts.Regexp(`^grpc Listening on 127\.0\.0\.1:\d+\n$`, out.String())
+	})
+
+	t.Run("Invalid address", func(t *testing.T) {
+		asserts := require.New(t)
+		config := Config{Ctx: context.Background()}
+
+		cmd := grpcServe(&config)
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--addr", "127.0.0.1:-1"})
+
+		asserts.Error(cmd.Execute())
+	})
+
+	t.Run("Default address from config", func(t *testing.T) {
+		asserts := require.New(t)
+		config := Config{Ctx: context.Background(), GRPCAddr: "127.0.0.1:9191"}
+
+		cmd := grpcServe(&config)
+
+		asserts.Equal("127.0.0.1:9191", cmd.Flag("addr").DefValue)
+	})
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/archive"
+	"github.com/malusev998/currency/iso4217"
+	"github.com/malusev998/currency/services"
+)
+
+const (
+	OutputTable = "table"
+	OutputCSV   = "csv"
+	OutputJSONL = "jsonl"
+
+	DefaultHistoryPageSize = 500
+)
+
+type (
+	tableWriter struct {
+		w *tabwriter.Writer
+	}
+
+	jsonWriter struct {
+		w     io.Writer
+		count int
+	}
+)
+
+// newRateWriter writes rates one by one, so whole histories are never kept in memory.
+// CSV and JSONL rates are written the same way as in archives.
+func newRateWriter(w io.Writer, output string) (archive.Encoder, error) {
+	switch output {
+	case OutputTable:
+		t := tableWriter{w: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)}
+
+		_, err := fmt.Fprintln(t.w, strings.ToUpper(strings.Join(archive.Header, "\t")))
+
+		return t, err
+	case OutputCSV:
+		return archive.NewEncoder(w, archive.FormatCSV)
+	case OutputJSON:
+		return &jsonWriter{w: w}, nil
+	case OutputJSONL:
+		return archive.NewEncoder(w, archive.FormatJSONL)
+	}
+
+	return nil, fmt.Errorf("invalid --output %q, expected %s, %s, %s or %s", output, OutputTable, OutputCSV, OutputJSON, OutputJSONL)
+}
+
+func (t tableWriter) Encode(rate currency.CurrencyWithID) error {
+	_, err := fmt.Fprintln(t.w, strings.Join(archive.Record(rate), "\t"))
+	return err
+}
+
+func (t tableWriter) Flush() error {
+	return t.w.Flush()
+}
+
+// Encode streams the JSON array element by element
+func (j *jsonWriter) Encode(rate currency.CurrencyWithID) error {
+	data, err := json.Marshal(rate)
+
+	if err != nil {
+		return err
+	}
+
+	prefix := ",\n  "
+
+	if j.count == 0 {
+		prefix = "[\n  "
+	}
+
+	j.count++
+
+	if _, err := io.WriteString(j.w, prefix); err != nil {
+		return err
+	}
+
+	_, err = j.w.Write(data)
+
+	return err
+}
+
+func (j *jsonWriter) Flush() error {
+	suffix := "\n]\n"
+
+	if j.count == 0 {
+		suffix = "[]\n"
+	}
+
+	_, err := io.WriteString(j.w, suffix)
+
+	return err
+}
+
+// writeHistory pages through rates of the pair stored between start and end and writes them
+// in the order they were created, it returns the number of rates written
+func writeHistory(
+	storage currency.Storage,
+	w archive.Encoder,
+	pair iso4217.Pair,
+	provider currency.Provider,
+	start, end time.Time,
+	pageSize int64,
+) (int, error) {
+	written := 0
+
+	for page := int64(1); ; page++ {
+		rates, err := storage.GetByDateAndProvider(pair.From, pair.To, provider, start, end, page, pageSize)
+
+		if err != nil {
+			return written, err
+		}
+
+		for _, rate := range rates {
+			if err := w.Encode(rate); err != nil {
+				return written, err
+			}
+
+			written++
+		}
+
+		if int64(len(rates)) < pageSize {
+			return written, w.Flush()
+		}
+	}
+}
+
+func history(config *Config) *cobra.Command {
+	var from, to, provider, storageName, output string
+	var pageSize int64
+
+	historyCmd := &cobra.Command{
+		Use:   "history PAIR",
+		Short: "Write rates of a pair stored between --from and --to as a table, CSV, JSON or JSONL",
+		Args:  cobra.ExactArgs(1),
+	}
+
+	historyCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		pair, err := iso4217.ParsePair(args[0])
+
+		if err != nil {
+			return err
+		}
+
+		start, err := time.Parse(dateFormat, from)
+
+		if err != nil {
+			return fmt.Errorf("invalid --from date, expected format %s: %v", dateFormat, err)
+		}
+
+		end, err := time.Parse(dateFormat, to)
+
+		if err != nil {
+			return fmt.Errorf("invalid --to date, expected format %s: %v", dateFormat, err)
+		}
+
+		if start.After(end) {
+			return fmt.Errorf("--from (%s) cannot be after --to (%s)", from, to)
+		}
+
+		// Rates stored during the last day are included
+		end = end.Add(24*time.Hour - time.Nanosecond)
+
+		p, err := services.ParseProvider(provider)
+
+		if err != nil {
+			return fmt.Errorf("invalid --provider: %v", err)
+		}
+
+		if pageSize <= 0 {
+			return fmt.Errorf("--page-size has to be positive")
+		}
+
+		storage, err := services.FindStorage(config.Storages, storageName)
+
+		if err != nil {
+			return err
+		}
+
+		w, err := newRateWriter(cmd.OutOrStdout(), strings.ToLower(output))
+
+		if err != nil {
+			return err
+		}
+
+		cmd.SilenceUsage = true
+
+		written, err := writeHistory(storage, w, pair, p, start, end, pageSize)
+
+		if err != nil {
+			return fmt.Errorf("error while reading %s history from %s after %d rates: %w", pair, storage.GetStorageProviderName(), written, err)
+		}
+
+		if *config.debug {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%d rates written\n", written)
+		}
+
+		return nil
+	}
+
+	historyCmd.Flags().StringVar(&from, "from", "", "First day (YYYY-MM-DD)")
+	historyCmd.Flags().StringVar(&to, "to", time.Now().Format(dateFormat), "Last day (YYYY-MM-DD)")
+	historyCmd.Flags().StringVar(&provider, "provider", "", "Rates of the provider only, every provider when empty")
+	historyCmd.Flags().StringVar(&storageName, "storage", "", "Storage to read from, the first configured storage when empty")
+	historyCmd.Flags().StringVarP(&output, "output", "o", OutputTable, "Output format, table, csv, json or jsonl")
+	historyCmd.Flags().Int64Var(&pageSize, "page-size", DefaultHistoryPageSize, "Rates read from the storage per query")
+	_ = historyCmd.MarkFlagRequired("from")
+
+	return historyCmd
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/archive"
+	"github.com/malusev998/currency/storage"
+)
+
+func TestHistoryCommand(t *testing.T) {
+	t.Parallel()
+	debug := false
+
+	memory, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+	require.Nil(t, err)
+
+	rates := make([]currencyFetcher.Currency, 0, 6)
+
+	for day := 1; day <= 3; day++ {
+		createdAt := time.Date(2020, 10, day, 14, 0, 0, 0, time.UTC)
+		rates = append(rates,
+			currencyFetcher.Currency{From: "EUR", To: "RSD", Provider: currencyFetcher.NBSProvider, Rate: decimal.RequireFromString(fmt.Sprintf("117.5%d", day)), CreatedAt: createdAt},
+			currencyFetcher.Currency{From: "EUR", To: "RSD", Provider: currencyFetcher.ECBProvider, Rate: decimal.RequireFromString(fmt.Sprintf("117.6%d", day)), CreatedAt: createdAt.Add(time.Hour)},
+		)
+	}
+
+	_, err = memory.Store(rates)
+	require.Nil(t, err)
+
+	execute := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := history(&Config{Ctx: context.Background(), debug: &debug, Storages: []currencyFetcher.Storage{memory}})
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+
+		return out.String(), err
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		asserts := require.New(t)
+
+		out, err := execute("EUR_RSD", "--from", "2020-10-01", "--to", "2020-10-03", "--output", "csv", "--page-size", "2")
+		asserts.Nil(err)
+
+		records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+		asserts.Nil(err)
+		asserts.Len(records, 7)
+		asserts.Equal(archive.Header, records[0])
+		asserts.Equal([]string{"EUR", "RSD", "NBS", "117.51", "2020-10-01T14:00:00Z"}, records[1][1:])
+		asserts.Equal([]string{"EUR", "RSD", "ECB", "117.63", "2020-10-03T15:00:00Z"}, records[6][1:])
+	})
+
+	t.Run("JSONByProvider", func(t *testing.T) {
+		asserts := require.New(t)
+
+		out, err := execute("eur_rsd", "--from", "2020-10-02", "--to", "2020-10-03", "--provider", "nbs", "-o", "json", "--page-size", "1")
+		asserts.Nil(err)
+
+		var decoded []currencyFetcher.CurrencyWithID
+		asserts.Nil(json.Unmarshal([]byte(out), &decoded))
+		asserts.Len(decoded, 2)
+		asserts.Equal("117.52", decoded[0].Rate.String())
+		asserts.Equal("117.53", decoded[1].Rate.String())
+	})
+
+	t.Run("JSONL", func(t *testing.T) {
+		asserts := require.New(t)
+
+		out, err := execute("EUR_RSD", "--from", "2020-10-03", "--output", "jsonl")
+		asserts.Nil(err)
+
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		asserts.Len(lines, 2)
+
+		var decoded currencyFetcher.CurrencyWithID
+		asserts.Nil(json.Unmarshal([]byte(lines[1]), &decoded))
+		asserts.Equal(currencyFetcher.ECBProvider, decoded.Provider)
+	})
+
+	t.Run("Table", func(t *testing.T) {
+		asserts := require.New(t)
+
+		out, err := execute("EUR_RSD", "--from", "2020-10-01", "--to", "2020-10-01")
+		asserts.Nil(err)
+
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		asserts.Len(lines, 3)
+		asserts.True(strings.HasPrefix(lines[0], "ID"))
+		asserts.Contains(lines[2], "ECB")
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		asserts := require.New(t)
+
+		out, err := execute("EUR_USD", "--from", "2020-10-01", "--output", "json")
+		asserts.Nil(err)
+		asserts.Equal("[]\n", out)
+	})
+
+	t.Run("InvalidArguments", func(t *testing.T) {
+		asserts := require.New(t)
+
+		_, err := execute("EUR-RSD", "--from", "2020-10-01")
+		asserts.Error(err)
+
+		_, err = execute("EUR_RSD", "--from", "2020-10-03", "--to", "2020-10-01")
+		asserts.Error(err)
+
+		_, err = execute("EUR_RSD", "--from", "2020-10-01", "--output", "xml")
+		asserts.Error(err)
+
+		_, err = execute("EUR_RSD", "--from", "2020-10-01", "--storage", "mysql")
+		asserts.Error(err)
+
+		_, err = execute("EUR_RSD")
+		asserts.Error(err)
+	})
+}
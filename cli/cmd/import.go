@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/malusev998/currency/archive"
+	"github.com/malusev998/currency/services"
+)
+
+// readCheckpoint returns the number of rates imported by an interrupted import, zero without a checkpoint
+func readCheckpoint(path string) (int, error) {
+	if path == "" {
+		return 0, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	imported, err := strconv.Atoi(strings.TrimSpace(string(data)))
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid checkpoint %s: %v", path, err)
+	}
+
+	return imported, nil
+}
+
+func writeCheckpoint(path string, imported int) error {
+	return ioutil.WriteFile(path, []byte(strconv.Itoa(imported)+"\n"), 0600)
+}
+
+func importRates(config *Config) *cobra.Command {
+	var file, format, storageName, checkpoint string
+	var batchSize, skip int
+
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Store rates from an archive written by export, gzip compression and format are detected",
+	}
+
+	importCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		logger := log.New(cmd.ErrOrStderr(), "import ", 0)
+		importConfig := archive.ImportConfig{BatchSize: batchSize, Skip: skip}
+
+		if format != "" {
+			f, err := archive.ConvertToFormatFromString(format)
+
+			if err != nil {
+				return fmt.Errorf("invalid --format: %w", err)
+			}
+
+			importConfig.Format = f
+		}
+
+		if batchSize <= 0 {
+			return fmt.Errorf("--batch-size has to be positive")
+		}
+
+		storage, err := services.FindStorage(config.Storages, storageName)
+
+		if err != nil {
+			return err
+		}
+
+		if !cmd.Flags().Changed("skip") {
+			if importConfig.Skip, err = readCheckpoint(checkpoint); err != nil {
+				return err
+			}
+		}
+
+		cmd.SilenceUsage = true
+
+		var r io.Reader = cmd.InOrStdin()
+
+		if file != stdio {
+			f, err := os.Open(file)
+
+			if err != nil {
+				return err
+			}
+
+			defer f.Close()
+
+			r = f
+		}
+
+		if importConfig.Skip != 0 {
+			logger.Printf("skipping %d rates imported before\n", importConfig.Skip)
+		}
+
+		importConfig.Progress = func(p archive.Progress) {
+			logger.Printf("%d rates imported\n", p.Rates)
+
+			if checkpoint == "" {
+				return
+			}
+
+			if err := writeCheckpoint(checkpoint, p.Rates); err != nil {
+				logger.Printf("ERROR: cannot write checkpoint %s: %v\n", checkpoint, err)
+			}
+		}
+
+		imported, err := archive.Import(storage, r, importConfig)
+
+		if err != nil {
+			if checkpoint != "" {
+				return fmt.Errorf("error while importing rates to %s after %d rates, run again to resume: %w", storage.GetStorageProviderName(), imported, err)
+			}
+
+			return fmt.Errorf("error while importing rates to %s after %d rates, resume with --skip %d: %w", storage.GetStorageProviderName(), imported, imported, err)
+		}
+
+		// The import is complete, running it again starts from the beginning
+		if checkpoint != "" {
+			if err := os.Remove(checkpoint); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+
+		logger.Printf("%d rates imported to %s\n", imported-importConfig.Skip, storage.GetStorageProviderName())
+
+		return nil
+	}
+
+	importCmd.Flags().StringVarP(&file, "file", "f", stdio, "Archive to read, standard input when -")
+	importCmd.Flags().StringVar(&format, "format", "", "Archive format, jsonl or csv, detected when empty")
+	importCmd.Flags().StringVar(&storageName, "storage", "", "Storage to import to, the first configured storage when empty")
+	importCmd.Flags().IntVar(&batchSize, "batch-size", archive.DefaultBatchSize, "Rates stored at once")
+	importCmd.Flags().IntVar(&skip, "skip", 0, "Number of rates already imported, overrides --checkpoint")
+	importCmd.Flags().StringVar(&checkpoint, "checkpoint", "", "File keeping the number of imported rates, so an interrupted import resumes where it stopped")
+
+	return importCmd
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func freeAddr(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer listener.Close()
+
+	return listener.Addr().String()
+}
+
+func TestFetchCommand_Metrics(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Serves metrics in standalone mode", func(t *testing.T) {
+		asserts := require.New(t)
+		debug := false
+		ctx, cancel := context.WithCancel(context.Background())
+		addr := freeAddr(t)
+		config := Config{Ctx: ctx, debug: &debug}
+
+		cmd := fetch(&config)
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--standalone", "--after", "1h", "--metrics-addr", addr})
+
+		done := make(chan error, 1)
+
+		go func() {
+			done <- cmd.Execute()
+		}()
+
+		var res *http.Response
+		var err error
+
+		for i := 0; i < 50; i++ {
+			if res, err = http.Get("http://" + addr + "/metrics"); err == nil {
+				break
+			}
+
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		asserts.Nil(err)
+		res.Body.Close()
+		asserts.Equal(http.StatusOK, res.StatusCode)
+
+		cancel()
+		asserts.Nil(<-done)
+	})
+
+	t.Run("Default address from config", func(t *testing.T) {
+		debug := false
+		config := Config{Ctx: context.Background(), debug: &debug, MetricsAddr: ":9100"}
+
+		require.Equal(t, ":9100", fetch(&config).Flag("metrics-addr").DefValue)
+	})
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/iso4217"
+	"github.com/malusev998/currency/services"
+)
+
+var errStoragesDiffer = errors.New("storages are not consistent")
+
+type (
+	reconcileDifference struct {
+		Left  currency.CurrencyWithID `json:"left"`
+		Right currency.CurrencyWithID `json:"right"`
+	}
+
+	reconcileResponse struct {
+		Left         string                    `json:"left"`
+		Right        string                    `json:"right"`
+		Pairs        []string                  `json:"pairs"`
+		Matched      int                       `json:"matched"`
+		MissingLeft  []currency.CurrencyWithID `json:"missing_left"`
+		MissingRight []currency.CurrencyWithID `json:"missing_right"`
+		Different    []reconcileDifference     `json:"different"`
+		CopiedLeft   int                       `json:"copied_left"`
+		CopiedRight  int                       `json:"copied_right"`
+	}
+)
+
+func newReconcileResponse(left, right string, report services.ReconcileReport) reconcileResponse {
+	different := make([]reconcileDifference, 0, len(report.Different))
+
+	for _, d := range report.Different {
+		different = append(different, reconcileDifference{Left: d.Left, Right: d.Right})
+	}
+
+	return reconcileResponse{
+		Left:         left,
+		Right:        right,
+		Pairs:        report.Pairs,
+		Matched:      report.Matched,
+		MissingLeft:  report.MissingLeft,
+		MissingRight: report.MissingRight,
+		Different:    different,
+		CopiedLeft:   report.CopiedLeft,
+		CopiedRight:  report.CopiedRight,
+	}
+}
+
+// printReconcileReport writes a row for every missing or different rate followed by a summary
+func printReconcileReport(w io.Writer, output string, response reconcileResponse) error {
+	if output == OutputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(response)
+	}
+
+	t := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	row := func(status string, c currency.CurrencyWithID, left, right string) {
+		_, _ = fmt.Fprintf(t, "%s\t%s_%s\t%s\t%s\t%s\t%s\n", status, c.From, c.To, c.Provider, c.CreatedAt.UTC().Format(time.RFC3339), left, right)
+	}
+
+	if len(response.MissingLeft)+len(response.MissingRight)+len(response.Different) != 0 {
+		_, _ = fmt.Fprintf(t, "STATUS\tPAIR\tPROVIDER\tCREATED_AT\t%s\t%s\n", strings.ToUpper(response.Left), strings.ToUpper(response.Right))
+	}
+
+	for _, c := range response.MissingLeft {
+		row("missing in "+response.Left, c, "-", c.Rate.String())
+	}
+
+	for _, c := range response.MissingRight {
+		row("missing in "+response.Right, c, c.Rate.String(), "-")
+	}
+
+	for _, d := range response.Different {
+		row("different", d.Left, d.Left.Rate.String(), d.Right.Rate.String())
+	}
+
+	if err := t.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "%d pairs compared: %d rates match, %d missing in %s, %d missing in %s, %d different\n",
+		len(response.Pairs), response.Matched,
+		len(response.MissingLeft), response.Left, len(response.MissingRight), response.Right,
+		len(response.Different),
+	)
+
+	if err != nil || response.CopiedLeft+response.CopiedRight == 0 {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%d rates copied to %s, %d rates copied to %s\n", response.CopiedLeft, response.Left, response.CopiedRight, response.Right)
+
+	return err
+}
+
+func reconcile(config *Config) *cobra.Command {
+	var from, to, provider, output string
+	var pairs []string
+	var repair bool
+	var pageSize int64
+
+	reconcileCmd := &cobra.Command{
+		Use:   "reconcile LEFT RIGHT",
+		Short: "Compare rates of two configured storages between --from and --to and copy missing rates with --repair",
+		Long: "Compare rates of two configured storages, e.g. mysql and mongodb, between --from and --to.\n" +
+			"Rates are matched by pair, provider and creation time. Missing rates are copied to the other storage\n" +
+			"with --repair, rates with different values are only reported. Exits with an error when storages differ.",
+		Args: cobra.ExactArgs(2),
+	}
+
+	reconcileCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		start, err := time.Parse(dateFormat, from)
+
+		if err != nil {
+			return fmt.Errorf("invalid --from date, expected format %s: %v", dateFormat, err)
+		}
+
+		end, err := time.Parse(dateFormat, to)
+
+		if err != nil {
+			return fmt.Errorf("invalid --to date, expected format %s: %v", dateFormat, err)
+		}
+
+		if start.After(end) {
+			return fmt.Errorf("--from (%s) cannot be after --to (%s)", from, to)
+		}
+
+		// Rates stored during the last day are included
+		end = end.Add(24*time.Hour - time.Nanosecond)
+
+		reconciler := services.Reconciler{
+			PageSize: pageSize,
+			Repair:   repair,
+		}
+
+		if reconciler.Provider, err = services.ParseProvider(provider); err != nil {
+			return fmt.Errorf("invalid --provider: %v", err)
+		}
+
+		if len(pairs) != 0 {
+			parsed, err := iso4217.ParsePairs(pairs)
+
+			if err != nil {
+				return fmt.Errorf("invalid --pairs: %w", err)
+			}
+
+			reconciler.Pairs = iso4217.Strings(parsed)
+		}
+
+		if pageSize <= 0 {
+			return fmt.Errorf("--page-size has to be positive")
+		}
+
+		output = strings.ToLower(output)
+
+		if output != OutputText && output != OutputJSON {
+			return fmt.Errorf("invalid --output %q, expected %s or %s", output, OutputText, OutputJSON)
+		}
+
+		if strings.EqualFold(args[0], args[1]) {
+			return fmt.Errorf("storage %s cannot be reconciled with itself", args[0])
+		}
+
+		if reconciler.Left, err = services.FindStorage(config.Storages, args[0]); err != nil {
+			return err
+		}
+
+		if reconciler.Right, err = services.FindStorage(config.Storages, args[1]); err != nil {
+			return err
+		}
+
+		cmd.SilenceUsage = true
+
+		report, err := reconciler.Reconcile(start, end)
+
+		if err != nil {
+			return err
+		}
+
+		response := newReconcileResponse(reconciler.Left.GetStorageProviderName(), reconciler.Right.GetStorageProviderName(), report)
+
+		if err := printReconcileReport(cmd.OutOrStdout(), output, response); err != nil {
+			return err
+		}
+
+		if !report.Consistent() {
+			return errStoragesDiffer
+		}
+
+		return nil
+	}
+
+	reconcileCmd.Flags().StringVar(&from, "from", "", "First day (YYYY-MM-DD)")
+	reconcileCmd.Flags().StringVar(&to, "to", time.Now().Format(dateFormat), "Last day (YYYY-MM-DD)")
+	reconcileCmd.Flags().StringVar(&provider, "provider", "", "Compare rates of the provider only, every provider when empty")
+	reconcileCmd.Flags().StringSliceVar(&pairs, "pairs", nil, "Pairs to compare (EUR_RSD,EUR_USD), every stored pair when empty")
+	reconcileCmd.Flags().BoolVar(&repair, "repair", false, "Copy missing rates to the other storage")
+	reconcileCmd.Flags().Int64Var(&pageSize, "page-size", services.DefaultReconcilePageSize, "Rates read from a storage per query")
+	reconcileCmd.Flags().StringVarP(&output, "output", "o", OutputText, "Output format, text or json")
+	_ = reconcileCmd.MarkFlagRequired("from")
+
+	return reconcileCmd
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/storage"
+)
+
+func TestReconcileCommand(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	debug := false
+
+	memory, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+	asserts.Nil(err)
+
+	sqlite, err := storage.NewSQLiteStorage(storage.SQLiteConfig{
+		BaseConfig: storage.BaseConfig{Cxt: context.Background(), Migrate: true},
+		Path:       ":memory:",
+		TableName:  "currency",
+	})
+	asserts.Nil(err)
+
+	rate := func(value string, day int) currencyFetcher.Currency {
+		return currencyFetcher.Currency{
+			From:      "EUR",
+			To:        "RSD",
+			Provider:  currencyFetcher.NBSProvider,
+			Rate:      decimal.RequireFromString(value),
+			CreatedAt: time.Date(2020, 10, day, 14, 0, 0, 0, time.UTC),
+		}
+	}
+
+	_, err = memory.Store([]currencyFetcher.Currency{rate("117.51", 1), rate("117.52", 2)})
+	asserts.Nil(err)
+	_, err = sqlite.Store([]currencyFetcher.Currency{rate("117.51", 1), rate("117.53", 3)})
+	asserts.Nil(err)
+
+	execute := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := reconcile(&Config{Ctx: context.Background(), debug: &debug, Storages: []currencyFetcher.Storage{memory, sqlite}})
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+
+		return out.String(), err
+	}
+
+	out, err := execute("memory", "sqlite", "--from", "2020-10-01", "--to", "2020-10-03", "-o", "json")
+	asserts.True(errors.Is(err, errStoragesDiffer))
+
+	// cobra writes the error after the report
+	var response reconcileResponse
+	asserts.Nil(json.NewDecoder(strings.NewReader(out)).Decode(&response))
+	asserts.Equal(1, response.Matched)
+	asserts.Len(response.MissingLeft, 1)
+	asserts.Equal("117.53", response.MissingLeft[0].Rate.String())
+	asserts.Len(response.MissingRight, 1)
+	asserts.Equal("117.52", response.MissingRight[0].Rate.String())
+
+	out, err = execute("memory", "sqlite", "--from", "2020-10-01", "--to", "2020-10-03", "--repair")
+	asserts.Nil(err)
+	asserts.Contains(out, "missing in memory")
+	asserts.Contains(out, "1 rates copied to memory, 1 rates copied to sqlite")
+
+	out, err = execute("memory", "sqlite", "--from", "2020-10-01", "--to", "2020-10-03")
+	asserts.Nil(err)
+	asserts.Equal("1 pairs compared: 3 rates match, 0 missing in memory, 0 missing in sqlite, 0 different\n", out)
+
+	_, err = execute("memory", "memory", "--from", "2020-10-01")
+	asserts.NotNil(err)
+
+	_, err = execute("memory", "mysql", "--from", "2020-10-01")
+	asserts.NotNil(err)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/malusev998/currency/rest"
+)
+
+const (
+	DefaultHTTPAddr = ":8080"
+	shutdownTimeout = 10 * time.Second
+)
+
+func serve(config *Config) *cobra.Command {
+	var addr string
+
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve stored rates and conversions over a JSON HTTP API",
+	}
+
+	serveCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		logger := log.New(cmd.OutOrStdout(), "serve ", 0)
+		listener, err := net.Listen("tcp", addr)
+
+		if err != nil {
+			return err
+		}
+
+		server := &http.Server{
+			Handler: rest.NewHandler(config.Storages, config.Conversion),
+		}
+
+		errChannel := make(chan error, 1)
+
+		go func() {
+			errChannel <- server.Serve(listener)
+		}()
+
+		logger.Printf("Listening on %s\n", listener.Addr())
+
+		select {
+		case err := <-errChannel:
+			return err
+		case <-config.Ctx.Done():
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+
+			if err := server.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+
+			return nil
+		}
+	}
+
+	defaultAddr := config.HTTPAddr
+
+	if defaultAddr == "" {
+		defaultAddr = DefaultHTTPAddr
+	}
+
+	serveCmd.Flags().StringVar(&addr, "addr", defaultAddr, "Address the HTTP server listens on")
+
+	return serveCmd
+}
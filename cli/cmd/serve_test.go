@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Shuts down when context is done", func(t *testing.T) {
+		asserts := require.New(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		config := Config{Ctx: ctx}
+
+		var out bytes.Buffer
+		cmd := serve(&config)
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"--addr", "127.0.0.1:0"})
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+		}()
+
+		asserts.Nil(cmd.Execute())
+		asserts.Regexp(`^serve Listening on 127\.0\.0\.1:\d+\n$`, out.String())
+	})
+
+	t.Run("Invalid address", func(t *testing.T) {
+		asserts := require.New(t)
+		config := Config{Ctx: context.Background()}
+
+		var out bytes.Buffer
+		cmd := serve(&config)
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--addr", "127.0.0.1:-1"})
+
+		asserts.Error(cmd.Execute())
+		asserts.NotContains(out.String(), "Listening on")
+	})
+
+	t.Run("Default address from config", func(t *testing.T) {
+		asserts := require.New(t)
+		config := Config{Ctx: context.Background(), HTTPAddr: "127.0.0.1:9090"}
+
+		cmd := serve(&config)
+
+		asserts.Equal("127.0.0.1:9090", cmd.Flag("addr").DefValue)
+	})
+}
@@ -4,24 +4,65 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/spf13/viper"
 
 	"github.com/malusev998/currency"
 	"github.com/malusev998/currency/fetchers"
+	"github.com/malusev998/currency/iso4217"
+	"github.com/malusev998/currency/services"
 	"github.com/malusev998/currency/storage"
 )
 
 type (
 	FetchersConfig map[currency.Provider]interface{}
+	RetryConfig    map[currency.Provider]fetchers.RetryConfig
 	StorageConfig  map[storage.Provider]interface{}
-	Config         struct {
+	QuotaConfig    struct {
+		// Storage is file, memory, mysql, postgres or sqlite, quota is not tracked when empty
+		Storage  string
+		Path     string
+		Table    string
+		MaxDefer time.Duration
+	}
+	FallbackConfig struct {
+		// Providers in priority order, empty disables the fallback chain
+		Providers []currency.Provider
+		Partial   bool
+	}
+	ConsensusConfig struct {
+		// Providers aggregated into Consensus rates, empty disables the consensus service
+		Providers  []currency.Provider
+		Method     fetchers.ConsensusMethod
+		Trim       float64
+		MinSources int
+	}
+	ValidationConfig struct {
+		Enabled    bool
+		MaxChange  float64
+		Currencies []string
+		// Quarantine is the storage of rejected rates, they are not stored when empty
+		Quarantine      storage.Provider
+		QuarantineTable string
+	}
+	Config struct {
 		Fetchers          []currency.Provider
+		Fallback          FallbackConfig
+		Consensus         ConsensusConfig
 		Storage           []storage.Provider
+		SavePolicy        services.SavePolicy
 		FetchersConfig    FetchersConfig
+		RetryConfig       RetryConfig
+		ValidationConfig  ValidationConfig
+		QuotaConfig       QuotaConfig
 		StorageConfig     StorageConfig
 		CurrenciesToFetch []string
+		HTTPAddr          string
+		GRPCAddr          string
+		MetricsAddr       string
+		Conversion        services.ConversionService
 	}
 )
 
@@ -36,9 +77,45 @@ func getMysqlDSN(config map[string]string) string {
 	return mysqlDriverConfig.FormatDSN()
 }
 
+func getRetryConfig(ctx context.Context) (RetryConfig, error) {
+	retryConfig := make(RetryConfig)
+
+	for name := range viper.GetStringMap("fetchers.retry") {
+		provider, err := currency.ConvertToProviderFromString(name)
+
+		if err != nil {
+			return nil, fmt.Errorf("error while parsing fetchers.retry: %v", err)
+		}
+
+		key := "fetchers.retry." + name
+		retryable, err := fetchers.ConvertToRetryableFromStrings(viper.GetStringSlice(key + ".retryOn"))
+
+		if err != nil {
+			return nil, fmt.Errorf("error while parsing %s.retryOn: %v", key, err)
+		}
+
+		retryConfig[provider] = fetchers.RetryConfig{
+			Ctx: ctx,
+			Policy: fetchers.RetryPolicy{
+				MaxAttempts:    viper.GetInt(key + ".maxAttempts"),
+				InitialBackoff: viper.GetDuration(key + ".initialBackoff"),
+				MaxBackoff:     viper.GetDuration(key + ".maxBackoff"),
+				Multiplier:     viper.GetFloat64(key + ".multiplier"),
+				Jitter:         viper.GetFloat64(key + ".jitter"),
+				MaxRetryAfter:  viper.GetDuration(key + ".maxRetryAfter"),
+				Retryable:      retryable,
+			},
+		}
+	}
+
+	return retryConfig, nil
+}
+
 func getConfig(ctx context.Context) (*Config, error) {
 	mysqlConfig := viper.GetStringMapString("databases.mysql")
 	mongodbConfig := viper.GetStringMapString("databases.mongo")
+	postgresConfig := viper.GetStringMapString("databases.postgres")
+	sqliteConfig := viper.GetStringMapString("databases.sqlite")
 
 	fetcherConfig := viper.GetStringMapString("fetchers.freecurrconversion")
 	maxPerHour, err := strconv.ParseUint(fetcherConfig["maxperhour"], 10, 32)
@@ -60,6 +137,60 @@ func getConfig(ctx context.Context) (*Config, error) {
 
 	storages, err := storage.ConvertToProvidersFromStringSlice(viper.GetStringSlice("storage"))
 
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := services.ConvertToLookupPolicyFromString(viper.GetString("conversion.policy"))
+
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing conversion.policy: %v", err)
+	}
+
+	fallback, err := currency.ConvertToProvidersFromStringSlice(viper.GetStringSlice("fetchers.fallback.providers"))
+
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing fetchers.fallback.providers: %v", err)
+	}
+
+	consensus, err := currency.ConvertToProvidersFromStringSlice(viper.GetStringSlice("fetchers.consensus.providers"))
+
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing fetchers.consensus.providers: %v", err)
+	}
+
+	consensusMethod, err := fetchers.ConvertToConsensusMethodFromString(viper.GetString("fetchers.consensus.method"))
+
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing fetchers.consensus.method: %v", err)
+	}
+
+	savePolicy, err := services.ConvertToSavePolicyFromString(viper.GetString("savePolicy"))
+
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing savePolicy: %v", err)
+	}
+
+	var quarantine storage.Provider
+
+	if name := viper.GetString("validation.quarantine.storage"); name != "" {
+		if quarantine, err = storage.ConvertToProviderFromString(name); err != nil {
+			return nil, fmt.Errorf("error while parsing validation.quarantine.storage: %v", err)
+		}
+	}
+
+	pairs, err := iso4217.ParsePairs(viper.GetStringSlice("currencies"))
+
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing currencies: %v", err)
+	}
+
+	retryConfig, err := getRetryConfig(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
 	storageBaseConfig := storage.BaseConfig{
 		Cxt:     ctx,
 		Migrate: viper.GetBool("migrate"),
@@ -67,7 +198,18 @@ func getConfig(ctx context.Context) (*Config, error) {
 
 	return &Config{
 		Fetchers: fetcher,
-		Storage:  storages,
+		Fallback: FallbackConfig{
+			Providers: fallback,
+			Partial:   viper.GetBool("fetchers.fallback.partial"),
+		},
+		Consensus: ConsensusConfig{
+			Providers:  consensus,
+			Method:     consensusMethod,
+			Trim:       viper.GetFloat64("fetchers.consensus.trim"),
+			MinSources: viper.GetInt("fetchers.consensus.minSources"),
+		},
+		Storage:    storages,
+		SavePolicy: savePolicy,
 		StorageConfig: StorageConfig{
 			storage.MySQL: storage.MySQLConfig{
 				BaseConfig:       storageBaseConfig,
@@ -75,6 +217,22 @@ func getConfig(ctx context.Context) (*Config, error) {
 				TableName:        mysqlConfig["table"],
 				IDGenerator:      nil,
 			},
+			storage.PostgreSQL: storage.PostgreSQLConfig{
+				BaseConfig:       storageBaseConfig,
+				ConnectionString: postgresConfig["uri"],
+				TableName:        postgresConfig["table"],
+				IDGenerator:      nil,
+			},
+			storage.SQLite: storage.SQLiteConfig{
+				BaseConfig:  storageBaseConfig,
+				Path:        sqliteConfig["path"],
+				TableName:   sqliteConfig["table"],
+				IDGenerator: nil,
+			},
+			storage.Memory: storage.MemoryConfig{
+				BaseConfig: storageBaseConfig,
+				MaxEntries: viper.GetInt("databases.memory.maxEntries"),
+			},
 			storage.MongoDB: storage.MongoDBConfig{
 				BaseConfig:       storageBaseConfig,
 				ConnectionString: mongodbConfig["uri"],
@@ -89,6 +247,18 @@ func getConfig(ctx context.Context) (*Config, error) {
 					URL: viper.GetString("fetchers.exchangeratesapi"),
 				},
 			},
+			currency.ECBProvider: fetchers.ECBConfig{
+				BaseConfig: fetchers.BaseConfig{
+					Ctx: ctx,
+					URL: viper.GetString("fetchers.ecb.url"),
+				},
+			},
+			currency.NBSProvider: fetchers.NBSConfig{
+				BaseConfig: fetchers.BaseConfig{
+					Ctx: ctx,
+					URL: viper.GetString("fetchers.nbs.url"),
+				},
+			},
 			currency.FreeConvProvider: fetchers.FreeConvServiceConfig{
 				BaseConfig: fetchers.BaseConfig{
 					Ctx: ctx,
@@ -99,6 +269,30 @@ func getConfig(ctx context.Context) (*Config, error) {
 				MaxPerRequest:      int(maxPerRequest),
 			},
 		},
-		CurrenciesToFetch: viper.GetStringSlice("currencies"),
+		RetryConfig: retryConfig,
+		ValidationConfig: ValidationConfig{
+			Enabled:         viper.GetBool("validation.enabled"),
+			MaxChange:       viper.GetFloat64("validation.maxChange"),
+			Currencies:      viper.GetStringSlice("validation.currencies"),
+			Quarantine:      quarantine,
+			QuarantineTable: viper.GetString("validation.quarantine.table"),
+		},
+		QuotaConfig: QuotaConfig{
+			Storage:  viper.GetString("fetchers.freecurrconversion.quota.storage"),
+			Path:     viper.GetString("fetchers.freecurrconversion.quota.path"),
+			Table:    viper.GetString("fetchers.freecurrconversion.quota.table"),
+			MaxDefer: viper.GetDuration("fetchers.freecurrconversion.quota.maxDefer"),
+		},
+		CurrenciesToFetch: iso4217.Strings(pairs),
+		HTTPAddr:          viper.GetString("http.addr"),
+		GRPCAddr:          viper.GetString("grpc.addr"),
+		MetricsAddr:       viper.GetString("metrics.addr"),
+		Conversion: services.ConversionService{
+			Ctx:          ctx,
+			Policy:       policy,
+			MaxStaleness: viper.GetDuration("conversion.maxStaleness"),
+			Pivot:        viper.GetString("conversion.pivot"),
+			MaxRateSkew:  viper.GetDuration("conversion.maxRateSkew"),
+		},
 	}, nil
 }
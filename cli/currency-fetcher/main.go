@@ -6,9 +6,13 @@ import (
 	"os"
 	"os/signal"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/viper"
 
+	"github.com/malusev998/currency"
 	"github.com/malusev998/currency/cli/cmd"
+	"github.com/malusev998/currency/fetchers"
+	"github.com/malusev998/currency/metrics"
 )
 
 func main() {
@@ -36,13 +40,40 @@ func main() {
 		log.Fatal(err)
 	}
 
-	storages, err := createStorages(config)
+	instruments, err := metrics.New(prometheus.DefaultRegisterer)
+
+	if err != nil {
+		log.Fatalf("Error while registering metrics: %v\n", err)
+	}
+
+	quotaTracker, quotaStorage, err := createQuotaTracker(ctx, config)
+
+	if err != nil {
+		log.Fatalf("Error while creating quota storage: %v\n", err)
+	}
+
+	quotas := make([]*fetchers.QuotaTracker, 0, 1)
+
+	if quotaTracker != nil {
+		fetcherConfig := config.FetchersConfig[currency.FreeConvProvider].(fetchers.FreeConvServiceConfig)
+		fetcherConfig.Quota = quotaTracker
+		config.FetchersConfig[currency.FreeConvProvider] = fetcherConfig
+		quotas = append(quotas, quotaTracker)
+	}
+
+	storages, err := createStorages(config, instruments)
 
 	if err != nil {
 		log.Fatalf("Error while creating storages: %v\n", err)
 	}
 
-	fetchServices, err := createCurrencyService(config, storages)
+	validator, quarantine, err := createValidator(config, storages, instruments)
+
+	if err != nil {
+		log.Fatalf("Error while creating the quarantine storage: %v\n", err)
+	}
+
+	fetchServices, err := createCurrencyService(config, storages, validator, instruments)
 
 	if err != nil {
 		log.Fatalf("Error while creating fetchers services: %v\n", err)
@@ -60,12 +91,30 @@ func main() {
 		Ctx:               ctx,
 		CurrenciesToFetch: config.CurrenciesToFetch,
 		CurrencyService:   fetchServices,
+		Storages:          storages,
+		Conversion:        config.Conversion,
+		HTTPAddr:          config.HTTPAddr,
+		GRPCAddr:          config.GRPCAddr,
+		MetricsAddr:       config.MetricsAddr,
+		Quotas:            quotas,
 	})
 
 	if err != nil {
 		log.Fatalf("Error while executing command: %v", err)
 	}
 
+	if quotaStorage != nil {
+		if err := quotaStorage.Close(); err != nil {
+			log.Fatalf("Error while closing the quota storage: %v\n", err)
+		}
+	}
+
+	if quarantine != nil {
+		if err := quarantine.Close(); err != nil {
+			log.Fatalf("Error while closing the quarantine storage: %v\n", err)
+		}
+	}
+
 	for _, st := range storages {
 		if err := st.Close(); err != nil {
 			log.Fatalf("Error while closing the storage %s: %v\n", st.GetStorageProviderName(), err)
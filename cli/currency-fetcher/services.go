@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	currencyFetcher "github.com/malusev998/currency"
 	"github.com/malusev998/currency/fetchers"
+	"github.com/malusev998/currency/metrics"
 	service "github.com/malusev998/currency/services"
 	"github.com/malusev998/currency/storage"
 )
 
-func createStorages(config *Config) ([]currencyFetcher.Storage, error) {
+func createStorages(config *Config, m *metrics.Metrics) ([]currencyFetcher.Storage, error) {
 	storages := make([]currencyFetcher.Storage, 0, len(config.Storage))
 	for _, s := range config.Storage {
 		c, ok := config.StorageConfig[s]
@@ -23,27 +28,217 @@ func createStorages(config *Config) ([]currencyFetcher.Storage, error) {
 			return nil, err
 		}
 
-		storages = append(storages, st)
+		storages = append(storages, m.Storage(st))
 	}
 
 	return storages, nil
 }
 
-func createCurrencyService(config *Config, storages []currencyFetcher.Storage) ([]currencyFetcher.Service, error) {
-	services := make([]currencyFetcher.Service, 0, len(config.Fetchers))
+// fallbackServiceName labels metrics of the service running the fallback chain
+const fallbackServiceName currencyFetcher.Provider = "Fallback"
+
+func createFetcher(config *Config, f currencyFetcher.Provider, m *metrics.Metrics) (currencyFetcher.Fetcher, error) {
+	c, ok := config.FetchersConfig[f]
+
+	if !ok {
+		return nil, fmt.Errorf("fetcher %s does not exist", f)
+	}
+
+	// Metrics are recorded for every attempt, retries are counted as separate fetches
+	fetcher := m.Fetcher(f, fetchers.NewCurrencyFetcher(f, c))
+
+	if retryConfig, ok := config.RetryConfig[f]; ok {
+		fetcher = fetchers.NewRetryFetcher(fetcher, retryConfig)
+	}
+
+	return fetcher, nil
+}
+
+// createCurrencyService creates a service for every fetcher, providers in the fallback chain
+// share a single service instead. Consensus providers get one more service storing aggregated rates.
+func createCurrencyService(
+	config *Config,
+	storages []currencyFetcher.Storage,
+	validator *service.Validator,
+	m *metrics.Metrics,
+) ([]currencyFetcher.Service, error) {
+	services := make([]currencyFetcher.Service, 0, len(config.Fetchers)+2)
+	inFallback := make(map[currencyFetcher.Provider]bool, len(config.Fallback.Providers))
+
+	if len(config.Fallback.Providers) != 0 {
+		chain := make([]fetchers.ProviderFetcher, 0, len(config.Fallback.Providers))
+
+		for _, f := range config.Fallback.Providers {
+			fetcher, err := createFetcher(config, f, m)
+
+			if err != nil {
+				return nil, err
+			}
+
+			inFallback[f] = true
+			chain = append(chain, fetchers.ProviderFetcher{Provider: f, Fetcher: fetcher})
+		}
+
+		services = append(services, m.Service(fallbackServiceName, service.Service{
+			Fetcher:   fetchers.NewFallbackFetcher(fetchers.FallbackConfig{Fetchers: chain, Partial: config.Fallback.Partial}),
+			Storage:   storages,
+			Validator: validator,
+			Policy:    config.SavePolicy,
+		}))
+	}
+
+	if len(config.Consensus.Providers) != 0 {
+		sources := make([]fetchers.ProviderFetcher, 0, len(config.Consensus.Providers))
+
+		for _, f := range config.Consensus.Providers {
+			fetcher, err := createFetcher(config, f, m)
+
+			if err != nil {
+				return nil, err
+			}
+
+			sources = append(sources, fetchers.ProviderFetcher{Provider: f, Fetcher: fetcher})
+		}
+
+		services = append(services, m.Service(currencyFetcher.ConsensusProvider, service.Service{
+			Fetcher: fetchers.NewConsensusFetcher(fetchers.ConsensusConfig{
+				Fetchers:   sources,
+				Method:     config.Consensus.Method,
+				Trim:       config.Consensus.Trim,
+				MinSources: config.Consensus.MinSources,
+				OnRate:     m.ConsensusRate,
+			}),
+			Storage:   storages,
+			Validator: validator,
+			Policy:    config.SavePolicy,
+		}))
+	}
 
 	for _, f := range config.Fetchers {
-		c, ok := config.FetchersConfig[f]
+		if inFallback[f] {
+			continue
+		}
 
-		if !ok {
-			return nil, fmt.Errorf("fetcher %s does not exist", f)
+		fetcher, err := createFetcher(config, f, m)
+
+		if err != nil {
+			return nil, err
 		}
 
-		services = append(services, service.Service{
-			Fetcher: fetchers.NewCurrencyFetcher(f, c),
-			Storage: storages,
-		})
+		services = append(services, m.Service(f, service.Service{
+			Fetcher:   fetcher,
+			Storage:   storages,
+			Validator: validator,
+			Policy:    config.SavePolicy,
+		}))
 	}
 
 	return services, nil
 }
+
+// createValidator returns nil when validation is disabled, last rates are looked up
+// in the first storage and rejected rates are logged, counted and quarantined when configured
+func createValidator(config *Config, storages []currencyFetcher.Storage, m *metrics.Metrics) (*service.Validator, currencyFetcher.Storage, error) {
+	if !config.ValidationConfig.Enabled {
+		return nil, nil, nil
+	}
+
+	validator := &service.Validator{
+		MaxChange:  config.ValidationConfig.MaxChange,
+		Currencies: config.ValidationConfig.Currencies,
+		OnReject: func(r service.RejectedRate) {
+			log.Printf("Rate not stored: %v\n", r)
+			m.RejectedRate(r)
+		},
+	}
+
+	if len(storages) != 0 {
+		validator.Storage = storages[0]
+	}
+
+	if config.ValidationConfig.Quarantine == "" {
+		return validator, nil, nil
+	}
+
+	table := config.ValidationConfig.QuarantineTable
+
+	var quarantineConfig interface{}
+
+	switch c := config.StorageConfig[config.ValidationConfig.Quarantine].(type) {
+	case storage.MySQLConfig:
+		c.TableName = table
+		quarantineConfig = c
+	case storage.PostgreSQLConfig:
+		c.TableName = table
+		quarantineConfig = c
+	case storage.SQLiteConfig:
+		c.TableName = table
+		quarantineConfig = c
+	case storage.MongoDBConfig:
+		c.Collection = table
+		quarantineConfig = c
+	default:
+		quarantineConfig = c
+	}
+
+	quarantine, err := storage.NewStorage(config.ValidationConfig.Quarantine, quarantineConfig)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	validator.Quarantine = quarantine
+
+	return validator, quarantine, nil
+}
+
+// createQuotaTracker returns nil when quota tracking for FreeCurrConv is not configured,
+// SQL quota storages open their own connection with the connection string of the storage with the same name
+func createQuotaTracker(ctx context.Context, config *Config) (*fetchers.QuotaTracker, currencyFetcher.QuotaStorage, error) {
+	if config.QuotaConfig.Storage == "" {
+		return nil, nil, nil
+	}
+
+	var quotaStorage currencyFetcher.QuotaStorage
+
+	switch strings.ToLower(config.QuotaConfig.Storage) {
+	case "file":
+		quotaStorage = storage.NewFileQuotaStorage(config.QuotaConfig.Path)
+	case "memory":
+		quotaStorage = storage.NewMemoryQuotaStorage()
+	default:
+		provider, err := storage.ConvertToProviderFromString(config.QuotaConfig.Storage)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		quotaConfig := storage.SQLQuotaConfig{Driver: provider, TableName: config.QuotaConfig.Table}
+
+		switch c := config.StorageConfig[provider].(type) {
+		case storage.MySQLConfig:
+			quotaConfig.BaseConfig, quotaConfig.ConnectionString = c.BaseConfig, c.ConnectionString
+		case storage.PostgreSQLConfig:
+			quotaConfig.BaseConfig, quotaConfig.ConnectionString = c.BaseConfig, c.ConnectionString
+		case storage.SQLiteConfig:
+			quotaConfig.BaseConfig, quotaConfig.ConnectionString = c.BaseConfig, c.Path
+		}
+
+		quotaStorage, err = storage.NewSQLQuotaStorage(quotaConfig)
+
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	fetcherConfig := config.FetchersConfig[currencyFetcher.FreeConvProvider].(fetchers.FreeConvServiceConfig)
+
+	return &fetchers.QuotaTracker{
+		Ctx:      ctx,
+		Storage:  quotaStorage,
+		Provider: currencyFetcher.FreeConvProvider,
+		Limit:    fetcherConfig.MaxPerHourRequests,
+		Window:   time.Hour,
+		MaxDefer: config.QuotaConfig.MaxDefer,
+	}, quotaStorage, nil
+}
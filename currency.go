@@ -1,7 +1,18 @@
 package currency
 
+import "time"
+
 type (
 	Fetcher interface {
 		Fetch(currenciesToFetch []string) ([]Currency, error)
 	}
+
+	// HistoricalFetcher is implemented by fetchers whose provider
+	// can return rates for past dates. Returned currencies have CreatedAt
+	// set to the date the provider published the rate for.
+	HistoricalFetcher interface {
+		Fetcher
+		FetchByDate(currenciesToFetch []string, date time.Time) ([]Currency, error)
+		FetchByDateRange(currenciesToFetch []string, start, end time.Time) ([]Currency, error)
+	}
 )
@@ -0,0 +1,343 @@
+package fetchers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	currencyFetcher "github.com/malusev998/currency"
+)
+
+const (
+	ConsensusMedian      ConsensusMethod = "median"
+	ConsensusTrimmedMean ConsensusMethod = "trimmedmean"
+
+	DefaultConsensusTrim = 0.2
+	// consensusPrecision matches the precision kept by storages
+	consensusPrecision = 8
+)
+
+var (
+	ErrNoConsensus            = errors.New("no provider returned rates")
+	ErrUnknownConsensusMethod = errors.New("unknown consensus method")
+
+	decimalTwo = decimal.NewFromInt(2)
+)
+
+type (
+	ConsensusMethod string
+
+	ConsensusConfig struct {
+		Fetchers []ProviderFetcher
+		// Method defaults to ConsensusMedian
+		Method ConsensusMethod
+		// Trim is the fraction of sources dropped from each end by ConsensusTrimmedMean,
+		// between 0 and 0.5, DefaultConsensusTrim is used when zero
+		Trim float64
+		// MinSources is the number of distinct providers that have to return a pair,
+		// pairs with fewer sources are left out
+		MinSources int
+		// OnRate is called for every aggregated rate with its spread and sources
+		OnRate func(ConsensusRate)
+	}
+
+	// ConsensusRate is the aggregated rate of a pair with rates it was computed from
+	ConsensusRate struct {
+		currencyFetcher.Currency
+		// Spread is the difference between the highest and the lowest source rate
+		Spread decimal.Decimal
+		// Sources hold one rate per provider, ordered by rate
+		Sources []currencyFetcher.Currency
+	}
+
+	ConsensusFetcher struct {
+		fetchers   []ProviderFetcher
+		method     ConsensusMethod
+		trim       float64
+		minSources int
+		onRate     func(ConsensusRate)
+	}
+
+	historicalConsensusFetcher struct {
+		ConsensusFetcher
+	}
+)
+
+func (m ConsensusMethod) String() string {
+	return string(m)
+}
+
+func ConvertToConsensusMethodFromString(value string) (ConsensusMethod, error) {
+	switch strings.ToLower(value) {
+	case "", "median":
+		return ConsensusMedian, nil
+	case "trimmedmean":
+		return ConsensusTrimmedMean, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownConsensusMethod, value)
+}
+
+// NewConsensusFetcher queries every fetcher concurrently and returns one ConsensusProvider rate
+// per pair. It implements HistoricalFetcher when any of the fetchers does,
+// historical rates are aggregated per pair and day.
+func NewConsensusFetcher(c ConsensusConfig) currencyFetcher.Fetcher {
+	f := newConsensusFetcher(c)
+
+	for _, fetcher := range c.Fetchers {
+		if _, ok := fetcher.Fetcher.(currencyFetcher.HistoricalFetcher); ok {
+			return historicalConsensusFetcher{ConsensusFetcher: f}
+		}
+	}
+
+	return f
+}
+
+func newConsensusFetcher(c ConsensusConfig) ConsensusFetcher {
+	method := c.Method
+
+	if method == "" {
+		method = ConsensusMedian
+	}
+
+	trim := c.Trim
+
+	if trim <= 0 {
+		trim = DefaultConsensusTrim
+	}
+
+	if trim >= 0.5 {
+		trim = 0.49
+	}
+
+	minSources := c.MinSources
+
+	if minSources < 1 {
+		minSources = 1
+	}
+
+	return ConsensusFetcher{
+		fetchers:   c.Fetchers,
+		method:     method,
+		trim:       trim,
+		minSources: minSources,
+		onRate:     c.OnRate,
+	}
+}
+
+// collect runs fetch for every fetcher concurrently, errors are returned
+// only when no fetcher returned rates
+func (f ConsensusFetcher) collect(fetch func(fetcher currencyFetcher.Fetcher) ([]currencyFetcher.Currency, error)) ([]currencyFetcher.Currency, error) {
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+
+	sources := make([]currencyFetcher.Currency, 0)
+	errs := make([]string, 0)
+
+	wg.Add(len(f.fetchers))
+
+	for _, fetcher := range f.fetchers {
+		go func(fetcher ProviderFetcher) {
+			defer wg.Done()
+
+			currencies, err := fetch(fetcher.Fetcher)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", fetcher.Provider, err))
+				return
+			}
+
+			sources = append(sources, currencies...)
+		}(fetcher)
+	}
+
+	wg.Wait()
+
+	if len(sources) == 0 && len(errs) != 0 {
+		sort.Strings(errs)
+		return nil, fmt.Errorf("%w: %s", ErrNoConsensus, strings.Join(errs, "; "))
+	}
+
+	return sources, nil
+}
+
+// aggregate groups sources by key and computes their consensus rates, ordered by key.
+// Every provider counts once per group, the most recent of its rates is used.
+func (f ConsensusFetcher) aggregate(sources []currencyFetcher.Currency, key func(c currencyFetcher.Currency) string) []ConsensusRate {
+	groups := make(map[string]map[currencyFetcher.Provider]currencyFetcher.Currency)
+	keys := make([]string, 0)
+
+	for _, source := range sources {
+		k := key(source)
+
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+			groups[k] = make(map[currencyFetcher.Provider]currencyFetcher.Currency)
+		}
+
+		if previous, ok := groups[k][source.Provider]; !ok || source.CreatedAt.After(previous.CreatedAt) {
+			groups[k][source.Provider] = source
+		}
+	}
+
+	sort.Strings(keys)
+
+	rates := make([]ConsensusRate, 0, len(keys))
+
+	for _, k := range keys {
+		if len(groups[k]) < f.minSources {
+			continue
+		}
+
+		group := make([]currencyFetcher.Currency, 0, len(groups[k]))
+
+		for _, source := range groups[k] {
+			group = append(group, source)
+		}
+
+		sort.Slice(group, func(i, j int) bool {
+			if !group[i].Rate.Equal(group[j].Rate) {
+				return group[i].Rate.LessThan(group[j].Rate)
+			}
+
+			return group[i].Provider < group[j].Provider
+		})
+
+		values := make([]decimal.Decimal, 0, len(group))
+		createdAt := time.Time{}
+
+		for _, source := range group {
+			values = append(values, source.Rate)
+
+			if source.CreatedAt.After(createdAt) {
+				createdAt = source.CreatedAt
+			}
+		}
+
+		rate := ConsensusRate{
+			Currency: currencyFetcher.Currency{
+				From:      group[0].From,
+				To:        group[0].To,
+				Provider:  currencyFetcher.ConsensusProvider,
+				Rate:      f.rate(values).Round(consensusPrecision),
+				CreatedAt: createdAt,
+			},
+			Spread:  values[len(values)-1].Sub(values[0]),
+			Sources: group,
+		}
+
+		if f.onRate != nil {
+			f.onRate(rate)
+		}
+
+		rates = append(rates, rate)
+	}
+
+	return rates
+}
+
+// rate aggregates sorted values
+func (f ConsensusFetcher) rate(values []decimal.Decimal) decimal.Decimal {
+	if f.method == ConsensusTrimmedMean {
+		trimmed := int(float64(len(values)) * f.trim)
+		kept := values[trimmed : len(values)-trimmed]
+
+		return decimal.Sum(kept[0], kept[1:]...).Div(decimal.NewFromInt(int64(len(kept))))
+	}
+
+	middle := len(values) / 2
+
+	if len(values)%2 == 1 {
+		return values[middle]
+	}
+
+	return values[middle-1].Add(values[middle]).Div(decimalTwo)
+}
+
+func pairKey(c currencyFetcher.Currency) string {
+	return c.From + "_" + c.To
+}
+
+func pairDayKey(c currencyFetcher.Currency) string {
+	return pairKey(c) + "@" + c.CreatedAt.UTC().Format("2006-01-02")
+}
+
+func toCurrencies(rates []ConsensusRate) []currencyFetcher.Currency {
+	currencies := make([]currencyFetcher.Currency, 0, len(rates))
+
+	for _, rate := range rates {
+		currencies = append(currencies, rate.Currency)
+	}
+
+	return currencies
+}
+
+// ConsensusRates returns the latest consensus rates with their spread and sources
+func (f ConsensusFetcher) ConsensusRates(currenciesToFetch []string) ([]ConsensusRate, error) {
+	sources, err := f.collect(func(fetcher currencyFetcher.Fetcher) ([]currencyFetcher.Currency, error) {
+		return fetcher.Fetch(currenciesToFetch)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return f.aggregate(requestedOnly(sources, currenciesToFetch), pairKey), nil
+}
+
+func (f ConsensusFetcher) Fetch(currenciesToFetch []string) ([]currencyFetcher.Currency, error) {
+	rates, err := f.ConsensusRates(currenciesToFetch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return toCurrencies(rates), nil
+}
+
+func (f historicalConsensusFetcher) historical(fetch func(fetcher currencyFetcher.HistoricalFetcher) ([]currencyFetcher.Currency, error)) ([]currencyFetcher.Currency, error) {
+	historical := make([]ProviderFetcher, 0, len(f.fetchers))
+
+	for _, fetcher := range f.fetchers {
+		if _, ok := fetcher.Fetcher.(currencyFetcher.HistoricalFetcher); ok {
+			historical = append(historical, fetcher)
+		}
+	}
+
+	c := f.ConsensusFetcher
+	c.fetchers = historical
+
+	sources, err := c.collect(func(fetcher currencyFetcher.Fetcher) ([]currencyFetcher.Currency, error) {
+		return fetch(fetcher.(currencyFetcher.HistoricalFetcher))
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return toCurrencies(c.aggregate(sources, pairDayKey)), nil
+}
+
+func (f historicalConsensusFetcher) FetchByDate(currenciesToFetch []string, date time.Time) ([]currencyFetcher.Currency, error) {
+	return f.historical(func(fetcher currencyFetcher.HistoricalFetcher) ([]currencyFetcher.Currency, error) {
+		return fetcher.FetchByDate(currenciesToFetch, date)
+	})
+}
+
+func (f historicalConsensusFetcher) FetchByDateRange(currenciesToFetch []string, start, end time.Time) ([]currencyFetcher.Currency, error) {
+	if start.After(end) {
+		return nil, ErrInvalidDateRange
+	}
+
+	return f.historical(func(fetcher currencyFetcher.HistoricalFetcher) ([]currencyFetcher.Currency, error) {
+		return fetcher.FetchByDateRange(currenciesToFetch, start, end)
+	})
+}
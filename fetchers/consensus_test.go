@@ -0,0 +1,161 @@
+package fetchers_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/fetchers"
+)
+
+func TestConsensusFetcher(t *testing.T) {
+	t.Parallel()
+
+	newFetchers := func() []fetchers.ProviderFetcher {
+		return []fetchers.ProviderFetcher{
+			{Provider: currency.ECBProvider, Fetcher: &pairsFetcher{provider: currency.ECBProvider, rates: map[string]string{"EUR_USD": "1.10", "EUR_RSD": "117.5"}}},
+			{Provider: currency.NBSProvider, Fetcher: &pairsFetcher{provider: currency.NBSProvider, rates: map[string]string{"EUR_USD": "1.20", "EUR_RSD": "117.6"}}},
+			{Provider: currency.ExchangeRatesAPIProvider, Fetcher: &pairsFetcher{provider: currency.ExchangeRatesAPIProvider, rates: map[string]string{"EUR_USD": "1.90"}}},
+		}
+	}
+
+	t.Run("Median", func(t *testing.T) {
+		asserts := require.New(t)
+		f := fetchers.NewConsensusFetcher(fetchers.ConsensusConfig{Fetchers: newFetchers()})
+
+		rates, err := f.Fetch([]string{"EUR_USD", "EUR_RSD"})
+
+		asserts.Nil(err)
+		asserts.Len(rates, 2)
+		asserts.Equal("EUR_RSD", rates[0].From+"_"+rates[0].To)
+		asserts.Equal("117.55", rates[0].Rate.String())
+		asserts.Equal("EUR_USD", rates[1].From+"_"+rates[1].To)
+		asserts.Equal("1.2", rates[1].Rate.String())
+
+		for _, rate := range rates {
+			asserts.Equal(currency.ConsensusProvider, rate.Provider)
+		}
+	})
+
+	t.Run("TrimmedMean", func(t *testing.T) {
+		asserts := require.New(t)
+		c := fetchers.NewConsensusFetcher(fetchers.ConsensusConfig{
+			Fetchers: newFetchers(),
+			Method:   fetchers.ConsensusTrimmedMean,
+			Trim:     0.34,
+		}).(fetchers.ConsensusFetcher)
+
+		rates, err := c.ConsensusRates([]string{"EUR_USD"})
+
+		asserts.Nil(err)
+		asserts.Len(rates, 1)
+		asserts.Equal("1.2", rates[0].Rate.String())
+		asserts.Equal("0.8", rates[0].Spread.String())
+		asserts.Len(rates[0].Sources, 3)
+	})
+
+	t.Run("MinSources", func(t *testing.T) {
+		asserts := require.New(t)
+		f := fetchers.NewConsensusFetcher(fetchers.ConsensusConfig{Fetchers: newFetchers(), MinSources: 3})
+
+		rates, err := f.Fetch([]string{"EUR_USD", "EUR_RSD"})
+
+		asserts.Nil(err)
+		asserts.Len(rates, 1)
+		asserts.Equal("USD", rates[0].To)
+	})
+
+	t.Run("ProvidersCountOnce", func(t *testing.T) {
+		asserts := require.New(t)
+		fs := append(newFetchers()[:2], fetchers.ProviderFetcher{
+			Provider: currency.ECBProvider,
+			Fetcher:  &pairsFetcher{provider: currency.ECBProvider, rates: map[string]string{"EUR_USD": "1.10", "EUR_RSD": "117.5"}},
+		})
+		reported := make([]fetchers.ConsensusRate, 0)
+
+		rates, err := fetchers.NewConsensusFetcher(fetchers.ConsensusConfig{
+			Fetchers: fs,
+			OnRate: func(rate fetchers.ConsensusRate) {
+				reported = append(reported, rate)
+			},
+		}).Fetch([]string{"EUR_USD"})
+
+		asserts.Nil(err)
+		asserts.Len(rates, 1)
+		asserts.Equal("1.15", rates[0].Rate.String())
+
+		asserts.Len(reported, 1)
+		asserts.Len(reported[0].Sources, 2)
+		asserts.Equal("0.1", reported[0].Spread.String())
+
+		rates, err = fetchers.NewConsensusFetcher(fetchers.ConsensusConfig{Fetchers: fs, MinSources: 3}).Fetch([]string{"EUR_USD"})
+
+		asserts.Nil(err)
+		asserts.Empty(rates)
+	})
+
+	t.Run("FailedProvidersAreIgnored", func(t *testing.T) {
+		asserts := require.New(t)
+		fs := newFetchers()
+		fs[2].Fetcher.(*pairsFetcher).err = fetchers.ErrServer
+
+		rates, err := fetchers.NewConsensusFetcher(fetchers.ConsensusConfig{Fetchers: fs}).Fetch([]string{"EUR_USD"})
+
+		asserts.Nil(err)
+		asserts.Len(rates, 1)
+		asserts.Equal("1.15", rates[0].Rate.String())
+	})
+
+	t.Run("AllProvidersFail", func(t *testing.T) {
+		asserts := require.New(t)
+		fs := newFetchers()
+
+		for _, f := range fs {
+			f.Fetcher.(*pairsFetcher).err = fetchers.ErrServer
+		}
+
+		rates, err := fetchers.NewConsensusFetcher(fetchers.ConsensusConfig{Fetchers: fs}).Fetch([]string{"EUR_USD"})
+
+		asserts.Nil(rates)
+		asserts.True(errors.Is(err, fetchers.ErrNoConsensus))
+		asserts.Contains(err.Error(), string(currency.NBSProvider))
+	})
+
+	t.Run("Historical", func(t *testing.T) {
+		asserts := require.New(t)
+		fs := newFetchers()
+		fs[0].Fetcher = historicalPairsFetcher{pairsFetcher: fs[0].Fetcher.(*pairsFetcher)}
+		fs[1].Fetcher = historicalPairsFetcher{pairsFetcher: fs[1].Fetcher.(*pairsFetcher)}
+
+		f, ok := fetchers.NewConsensusFetcher(fetchers.ConsensusConfig{Fetchers: fs}).(currency.HistoricalFetcher)
+		asserts.True(ok)
+
+		rates, err := f.FetchByDate([]string{"EUR_USD"}, time.Now())
+
+		asserts.Nil(err)
+		asserts.Len(rates, 1)
+		asserts.Equal("1.15", rates[0].Rate.String())
+
+		_, err = f.FetchByDateRange([]string{"EUR_USD"}, time.Now(), time.Now().Add(-time.Hour))
+		asserts.True(errors.Is(err, fetchers.ErrInvalidDateRange))
+	})
+}
+
+func TestConvertToConsensusMethodFromString(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+
+	method, err := fetchers.ConvertToConsensusMethodFromString("TrimmedMean")
+	asserts.Nil(err)
+	asserts.Equal(fetchers.ConsensusTrimmedMean, method)
+
+	method, err = fetchers.ConvertToConsensusMethodFromString("")
+	asserts.Nil(err)
+	asserts.Equal(fetchers.ConsensusMedian, method)
+
+	_, err = fetchers.ConvertToConsensusMethodFromString("mode")
+	asserts.True(errors.Is(err, fetchers.ErrUnknownConsensusMethod))
+}
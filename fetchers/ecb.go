@@ -0,0 +1,122 @@
+package fetchers
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	currencyFetcher "github.com/malusev998/currency"
+)
+
+const (
+	ecbBaseCurrency = "EUR"
+	ecbDateFormat   = "2006-01-02"
+)
+
+type (
+	ECBFetcher struct {
+		Ctx context.Context
+		URL string
+	}
+
+	ecbRate struct {
+		Currency string          `xml:"currency,attr"`
+		Rate     decimal.Decimal `xml:"rate,attr"`
+	}
+
+	ecbResponse struct {
+		XMLName xml.Name `xml:"Envelope"`
+		Cube    struct {
+			Cube struct {
+				Time  string    `xml:"time,attr"`
+				Rates []ecbRate `xml:"Cube"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	}
+)
+
+// fetchRates returns the published rates and the day they were published for
+func (e ECBFetcher) fetchRates(ctx context.Context, url string) (map[string]decimal.Decimal, time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	req.Header.Add("Accept", "application/xml")
+
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	defer res.Body.Close()
+
+	if err := handleHTTPStatusCodeError(res); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var data ecbResponse
+
+	if err := xml.NewDecoder(res.Body).Decode(&data); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	createdAt, err := time.Parse(ecbDateFormat, data.Cube.Cube.Time)
+
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	rates := make(map[string]decimal.Decimal, len(data.Cube.Cube.Rates)+1)
+	rates[ecbBaseCurrency] = decimal.NewFromInt(1)
+
+	for _, rate := range data.Cube.Cube.Rates {
+		rates[rate.Currency] = rate.Rate
+	}
+
+	return rates, createdAt, nil
+}
+
+// Fetch downloads the EUR based reference rates published by the ECB
+// and derives every requested pair from them. Pairs that are not based on
+// EUR are calculated as a cross rate (e.g. USD_JPY = EUR_JPY / EUR_USD).
+// Pairs containing a currency that ECB does not publish are skipped.
+// CreatedAt is the day the rates were published for, not the time of the request.
+func (e ECBFetcher) Fetch(currenciesToFetch []string) ([]currencyFetcher.Currency, error) {
+	pairs, err := splitPairs(currenciesToFetch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	url := e.URL
+
+	if url == "" {
+		url = ECBURL
+	}
+
+	ctx := e.Ctx
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	rates, createdAt, err := e.fetchRates(ctx, url)
+
+	if err != nil {
+		return nil, err
+	}
+
+	currencies := crossRates(rates, pairs, currencyFetcher.ECBProvider)
+
+	for i := range currencies {
+		currencies[i].CreatedAt = createdAt
+	}
+
+	return currencies, nil
+}
@@ -0,0 +1,105 @@
+package fetchers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/fetchers"
+	"github.com/malusev998/currency/iso4217"
+)
+
+func ecbServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		http.ServeFile(writer, request, "testdata/eurofxref-daily.xml")
+	}))
+}
+
+func TestECBFetcher_Fetch(t *testing.T) {
+	t.Parallel()
+	server := ecbServer()
+	defer server.Close()
+
+	t.Run("EUR based pairs", func(t *testing.T) {
+		asserts := require.New(t)
+		fetcher := fetchers.ECBFetcher{URL: server.URL}
+
+		currencies, err := fetcher.Fetch([]string{"EUR_USD", "EUR_JPY"})
+
+		asserts.Nil(err)
+		asserts.Len(currencies, 2)
+		asserts.Equal("EUR", currencies[0].From)
+		asserts.Equal("USD", currencies[0].To)
+		asserts.Equal(currency.ECBProvider, currencies[0].Provider)
+		asserts.Equal("1.1708", currencies[0].Rate.String())
+		asserts.Equal("123.46", currencies[1].Rate.String())
+		asserts.True(time.Date(2020, 10, 16, 0, 0, 0, 0, time.UTC).Equal(currencies[0].CreatedAt))
+		asserts.True(currencies[0].CreatedAt.Equal(currencies[1].CreatedAt))
+	})
+
+	t.Run("Derives cross and inverse rates", func(t *testing.T) {
+		asserts := require.New(t)
+		fetcher := fetchers.ECBFetcher{URL: server.URL}
+
+		currencies, err := fetcher.Fetch([]string{"USD_EUR", "USD_JPY", "GBP_USD"})
+
+		asserts.Nil(err)
+		asserts.Len(currencies, 3)
+		asserts.Equal("USD", currencies[0].From)
+		asserts.Equal("EUR", currencies[0].To)
+		asserts.Equal("0.85411684", currencies[0].Rate.String())
+		asserts.Equal("105.44926546", currencies[1].Rate.String())
+		asserts.Equal("1.29123333", currencies[2].Rate.String())
+	})
+
+	t.Run("Skips currencies not published by ECB", func(t *testing.T) {
+		asserts := require.New(t)
+		fetcher := fetchers.ECBFetcher{URL: server.URL}
+
+		currencies, err := fetcher.Fetch([]string{"EUR_RSD", "EUR_USD"})
+
+		asserts.Nil(err)
+		asserts.Len(currencies, 1)
+		asserts.Equal("USD", currencies[0].To)
+	})
+}
+
+func TestECBFetcher_ServerError(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	server := httptest.NewServer(httpStatusHandler(http.StatusServiceUnavailable))
+	defer server.Close()
+
+	currencies, err := fetchers.ECBFetcher{URL: server.URL}.Fetch([]string{"EUR_USD"})
+
+	asserts.Nil(currencies)
+	asserts.True(errors.Is(err, fetchers.ErrServer))
+}
+
+func httpStatusHandler(status int) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(status)
+	})
+}
+
+func TestECBFetcher_InvalidPair(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	fetcher := fetchers.ECBFetcher{URL: server.URL}
+	currencies, err := fetcher.Fetch([]string{"EUR_USD", "EUR-RSD"})
+
+	asserts.Nil(currencies)
+	asserts.True(errors.Is(err, iso4217.ErrInvalidPair))
+	asserts.Zero(requests)
+}
@@ -5,12 +5,17 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/iso4217"
 )
 
+const ExchangeRatesAPIDateFormat = "2006-01-02"
+
 type (
 	ExchangeRatesAPIFetcher struct {
 		Ctx context.Context
@@ -20,11 +25,13 @@ type (
 
 func (e ExchangeRatesAPIFetcher) handleHTTPStatusCodeError(res *http.Response) error {
 	if res.StatusCode != http.StatusOK {
-		switch res.StatusCode {
-		case http.StatusBadRequest:
+		switch {
+		case res.StatusCode == http.StatusBadRequest:
 			return ErrClient
-		case http.StatusInternalServerError:
-			return ErrServer
+		case res.StatusCode == http.StatusTooManyRequests:
+			return withRetryAfter(res, ErrTooManyRequests)
+		case res.StatusCode >= http.StatusInternalServerError:
+			return withRetryAfter(res, ErrServer)
 		default:
 			return ErrUnknown
 		}
@@ -85,6 +92,7 @@ func (e ExchangeRatesAPIFetcher) fetchCurrencies(
 	}
 }
 
+// PrepareISOCurrencies groups target currencies by base currency, malformed pairs are skipped
 func (e ExchangeRatesAPIFetcher) PrepareISOCurrencies(currencies []string) map[string][]string {
 	var cs []string
 	var exists bool
@@ -92,14 +100,19 @@ func (e ExchangeRatesAPIFetcher) PrepareISOCurrencies(currencies []string) map[s
 	mappedCurrencies := make(map[string][]string)
 
 	for _, c := range currencies {
-		isoCurrency := strings.Split(c, "_")
-		if cs, exists = mappedCurrencies[isoCurrency[0]]; exists && len(cs) != 0 {
-			cs = append(cs, isoCurrency[1])
+		pair, err := iso4217.SplitPair(c)
+
+		if err != nil {
+			continue
+		}
+
+		if cs, exists = mappedCurrencies[pair.From]; exists && len(cs) != 0 {
+			cs = append(cs, pair.To)
 		} else {
-			cs = []string{isoCurrency[1]}
+			cs = []string{pair.To}
 		}
 
-		mappedCurrencies[isoCurrency[0]] = cs
+		mappedCurrencies[pair.From] = cs
 	}
 
 	return mappedCurrencies
@@ -107,6 +120,11 @@ func (e ExchangeRatesAPIFetcher) PrepareISOCurrencies(currencies []string) map[s
 
 func (e ExchangeRatesAPIFetcher) Fetch(currenciesToFetch []string) ([]currency.Currency, error) {
 	var wg, appendWg sync.WaitGroup
+
+	if _, err := splitPairs(currenciesToFetch); err != nil {
+		return nil, err
+	}
+
 	currencies := e.PrepareISOCurrencies(currenciesToFetch)
 
 	channel := make(currencyChannel, len(currencies))
@@ -156,3 +174,153 @@ func (e ExchangeRatesAPIFetcher) Fetch(currenciesToFetch []string) ([]currency.C
 		return result, nil
 	}
 }
+
+func (e ExchangeRatesAPIFetcher) baseURL() string {
+	apiURL := e.URL
+
+	if apiURL == "" {
+		apiURL = ExchangeRatesAPIURL
+	}
+
+	return strings.TrimSuffix(strings.TrimRight(apiURL, "/"), "/latest")
+}
+
+func (e ExchangeRatesAPIFetcher) fetchHistorical(
+	ctx context.Context,
+	client *http.Client,
+	apiURL string,
+	baseCurrency string,
+	currenciesToFetch []string,
+	query url.Values,
+	data interface{},
+) error {
+	req, formattedCurrencies, err := getData(ctx, apiURL, currenciesToFetch)
+
+	if err != nil {
+		return err
+	}
+
+	q := req.URL.Query()
+	q.Add("symbols", formattedCurrencies)
+	q.Add("base", baseCurrency)
+
+	for key, values := range query {
+		for _, value := range values {
+			q.Add(key, value)
+		}
+	}
+
+	req.URL.RawQuery = q.Encode()
+	res, err := client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if err := e.handleHTTPStatusCodeError(res); err != nil {
+		return err
+	}
+
+	return json.NewDecoder(res.Body).Decode(data)
+}
+
+// FetchByDate returns the rates ExchangeRatesAPI published for the given date.
+// For dates without published rates (weekends, holidays) the API returns
+// the last published rates, CreatedAt is set to their date.
+func (e ExchangeRatesAPIFetcher) FetchByDate(currenciesToFetch []string, date time.Time) ([]currency.Currency, error) {
+	if _, err := splitPairs(currenciesToFetch); err != nil {
+		return nil, err
+	}
+
+	ctx := e.Ctx
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	client := &http.Client{}
+	apiURL := e.baseURL() + "/" + date.Format(ExchangeRatesAPIDateFormat)
+	result := make([]currency.Currency, 0, len(currenciesToFetch))
+
+	for base, curs := range e.PrepareISOCurrencies(currenciesToFetch) {
+		var data exchangeRateAPIResponse
+
+		if err := e.fetchHistorical(ctx, client, apiURL, base, curs, nil, &data); err != nil {
+			return nil, err
+		}
+
+		createdAt, err := time.Parse(ExchangeRatesAPIDateFormat, data.Date)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for to, rate := range data.Rates {
+			result = append(result, currency.Currency{
+				From:      data.Base,
+				To:        to,
+				Provider:  currency.ExchangeRatesAPIProvider,
+				Rate:      rate,
+				CreatedAt: createdAt,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// FetchByDateRange returns every rate ExchangeRatesAPI published
+// between start and end (both inclusive) using the /history endpoint.
+func (e ExchangeRatesAPIFetcher) FetchByDateRange(currenciesToFetch []string, start, end time.Time) ([]currency.Currency, error) {
+	if start.After(end) {
+		return nil, ErrInvalidDateRange
+	}
+
+	if _, err := splitPairs(currenciesToFetch); err != nil {
+		return nil, err
+	}
+
+	ctx := e.Ctx
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	client := &http.Client{}
+	apiURL := e.baseURL() + "/history"
+	query := url.Values{
+		"start_at": []string{start.Format(ExchangeRatesAPIDateFormat)},
+		"end_at":   []string{end.Format(ExchangeRatesAPIDateFormat)},
+	}
+	result := make([]currency.Currency, 0, len(currenciesToFetch))
+
+	for base, curs := range e.PrepareISOCurrencies(currenciesToFetch) {
+		var data exchangeRateAPIHistoryResponse
+
+		if err := e.fetchHistorical(ctx, client, apiURL, base, curs, query, &data); err != nil {
+			return nil, err
+		}
+
+		for date, rates := range data.Rates {
+			createdAt, err := time.Parse(ExchangeRatesAPIDateFormat, date)
+
+			if err != nil {
+				return nil, err
+			}
+
+			for to, rate := range rates {
+				result = append(result, currency.Currency{
+					From:      data.Base,
+					To:        to,
+					Provider:  currency.ExchangeRatesAPIProvider,
+					Rate:      rate,
+					CreatedAt: createdAt,
+				})
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -2,9 +2,17 @@ package fetchers_test
 
 import (
 	"context"
-	"github.com/malusev998/currency/fetchers"
-	"github.com/stretchr/testify/require"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/fetchers"
 )
 
 func TestExchangeRatesAPIFetcher_PrepareISOCurrencies(t *testing.T) {
@@ -22,3 +30,78 @@ func TestExchangeRatesAPIFetcher_PrepareISOCurrencies(t *testing.T) {
 	assert.EqualValues(result["EUR"], []string{"USD", "RSD"})
 	assert.EqualValues(result["USD"], []string{"EUR", "RSD"})
 }
+
+func exchangeRatesAPIServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		query := request.URL.Query()
+
+		switch request.URL.Path {
+		case "/2020-10-17":
+			_, _ = writer.Write([]byte(`{"rates":{"USD":1.1708},"base":"` + query.Get("base") + `","date":"2020-10-16"}`))
+		case "/history":
+			if query.Get("start_at") != "2020-10-15" || query.Get("end_at") != "2020-10-16" {
+				writer.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			_, _ = writer.Write([]byte(`{"rates":{"2020-10-15":{"USD":1.1704},"2020-10-16":{"USD":1.1708}},"start_at":"2020-10-15","end_at":"2020-10-16","base":"EUR"}`))
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestExchangeRatesAPIFetcher_FetchByDate(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	server := exchangeRatesAPIServer()
+	defer server.Close()
+
+	api := fetchers.ExchangeRatesAPIFetcher{URL: server.URL + "/latest"}
+	currencies, err := api.FetchByDate([]string{"EUR_USD"}, time.Date(2020, 10, 17, 12, 0, 0, 0, time.UTC))
+
+	asserts.Nil(err)
+	asserts.Equal([]currency.Currency{
+		{
+			From:      "EUR",
+			To:        "USD",
+			Provider:  currency.ExchangeRatesAPIProvider,
+			Rate:      decimal.RequireFromString("1.1708"),
+			CreatedAt: time.Date(2020, 10, 16, 0, 0, 0, 0, time.UTC),
+		},
+	}, currencies)
+}
+
+func TestExchangeRatesAPIFetcher_FetchByDateRange(t *testing.T) {
+	t.Parallel()
+	server := exchangeRatesAPIServer()
+	defer server.Close()
+
+	api := fetchers.ExchangeRatesAPIFetcher{URL: server.URL}
+	start := time.Date(2020, 10, 15, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2020, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Rate for every day in range", func(t *testing.T) {
+		asserts := require.New(t)
+		currencies, err := api.FetchByDateRange([]string{"EUR_USD"}, start, end)
+
+		asserts.Nil(err)
+		asserts.Len(currencies, 2)
+
+		rates := make(map[time.Time]string)
+		for _, c := range currencies {
+			rates[c.CreatedAt] = c.Rate.String()
+		}
+
+		asserts.Equal("1.1704", rates[start])
+		asserts.Equal("1.1708", rates[end])
+	})
+
+	t.Run("Invalid range", func(t *testing.T) {
+		asserts := require.New(t)
+		currencies, err := api.FetchByDateRange([]string{"EUR_USD"}, end, start)
+
+		asserts.Nil(currencies)
+		asserts.True(errors.Is(err, fetchers.ErrInvalidDateRange))
+	})
+}
@@ -16,10 +16,17 @@ type (
 		APIKey             string
 		MaxPerHourRequests int
 		MaxPerRequest      int
+		Quota              *QuotaTracker
 	}
 	ExchangeRatesAPIConfig struct {
 		BaseConfig
 	}
+	ECBConfig struct {
+		BaseConfig
+	}
+	NBSConfig struct {
+		BaseConfig
+	}
 )
 
 func NewCurrencyFetcher(provider currencyFetcher.Provider, config interface{}) currencyFetcher.Fetcher {
@@ -33,6 +40,7 @@ func NewCurrencyFetcher(provider currencyFetcher.Provider, config interface{}) c
 			APIKey:        c.APIKey,
 			MaxPerHour:    c.MaxPerHourRequests,
 			MaxPerRequest: c.MaxPerRequest,
+			Quota:         c.Quota,
 		}
 	case currencyFetcher.ExchangeRatesAPIProvider:
 		c := config.(ExchangeRatesAPIConfig)
@@ -41,6 +49,20 @@ func NewCurrencyFetcher(provider currencyFetcher.Provider, config interface{}) c
 			Ctx: c.Ctx,
 			URL: c.URL,
 		}
+	case currencyFetcher.ECBProvider:
+		c := config.(ECBConfig)
+
+		return ECBFetcher{
+			Ctx: c.Ctx,
+			URL: c.URL,
+		}
+	case currencyFetcher.NBSProvider:
+		c := config.(NBSConfig)
+
+		return NBSFetcher{
+			Ctx: c.Ctx,
+			URL: c.URL,
+		}
 	}
 
 	return nil
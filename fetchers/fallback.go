@@ -0,0 +1,152 @@
+package fetchers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	currencyFetcher "github.com/malusev998/currency"
+)
+
+var ErrPairsNotFetched = errors.New("no provider returned rates for pairs")
+
+type (
+	// ProviderFetcher names the fetcher of a provider in composite fetchers
+	ProviderFetcher struct {
+		Provider currencyFetcher.Provider
+		Fetcher  currencyFetcher.Fetcher
+	}
+
+	FallbackConfig struct {
+		// Fetchers in priority order
+		Fetchers []ProviderFetcher
+		// Partial returns rates of the fetched pairs when no provider returned some of them,
+		// otherwise the whole fetch fails with ErrPairsNotFetched
+		Partial bool
+	}
+
+	fallbackFetcher struct {
+		fetchers []ProviderFetcher
+		partial  bool
+	}
+
+	historicalFallbackFetcher struct {
+		fallbackFetcher
+	}
+)
+
+// NewFallbackFetcher asks each fetcher, in order, only for the pairs previous ones did not return,
+// so every pair gets rates from a single provider. It implements HistoricalFetcher
+// when any of the fetchers does, fetchers without historical rates are skipped then.
+func NewFallbackFetcher(c FallbackConfig) currencyFetcher.Fetcher {
+	f := fallbackFetcher{fetchers: c.Fetchers, partial: c.Partial}
+
+	for _, fetcher := range c.Fetchers {
+		if _, ok := fetcher.Fetcher.(currencyFetcher.HistoricalFetcher); ok {
+			return historicalFallbackFetcher{fallbackFetcher: f}
+		}
+	}
+
+	return f
+}
+
+func (f fallbackFetcher) fetch(
+	currenciesToFetch []string,
+	fetch func(fetcher currencyFetcher.Fetcher, pairs []string) ([]currencyFetcher.Currency, error),
+) ([]currencyFetcher.Currency, error) {
+	remaining := make([]string, 0, len(currenciesToFetch))
+	seen := make(map[string]bool, len(currenciesToFetch))
+
+	for _, pair := range currenciesToFetch {
+		if !seen[pair] {
+			seen[pair] = true
+			remaining = append(remaining, pair)
+		}
+	}
+
+	result := make([]currencyFetcher.Currency, 0, len(remaining))
+	errs := make([]string, 0)
+
+	for _, fetcher := range f.fetchers {
+		if len(remaining) == 0 {
+			break
+		}
+
+		currencies, err := fetch(fetcher.Fetcher, remaining)
+
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", fetcher.Provider, err))
+			continue
+		}
+
+		fetched := make(map[string]bool, len(remaining))
+
+		for _, c := range requestedOnly(currencies, remaining) {
+			fetched[c.From+"_"+c.To] = true
+			result = append(result, c)
+		}
+
+		next := make([]string, 0, len(remaining))
+
+		for _, pair := range remaining {
+			if !fetched[pair] {
+				next = append(next, pair)
+			}
+		}
+
+		remaining = next
+	}
+
+	if len(remaining) == 0 || (f.partial && len(result) != 0) {
+		return result, nil
+	}
+
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("%w %s: %s", ErrPairsNotFetched, strings.Join(remaining, ","), strings.Join(errs, "; "))
+	}
+
+	return nil, fmt.Errorf("%w %s", ErrPairsNotFetched, strings.Join(remaining, ","))
+}
+
+func (f fallbackFetcher) Fetch(currenciesToFetch []string) ([]currencyFetcher.Currency, error) {
+	return f.fetch(currenciesToFetch, func(fetcher currencyFetcher.Fetcher, pairs []string) ([]currencyFetcher.Currency, error) {
+		return fetcher.Fetch(pairs)
+	})
+}
+
+func (f historicalFallbackFetcher) historical(
+	currenciesToFetch []string,
+	fetch func(fetcher currencyFetcher.HistoricalFetcher, pairs []string) ([]currencyFetcher.Currency, error),
+) ([]currencyFetcher.Currency, error) {
+	historical := make([]ProviderFetcher, 0, len(f.fetchers))
+
+	for _, fetcher := range f.fetchers {
+		if _, ok := fetcher.Fetcher.(currencyFetcher.HistoricalFetcher); ok {
+			historical = append(historical, fetcher)
+		}
+	}
+
+	return fallbackFetcher{fetchers: historical, partial: f.partial}.fetch(
+		currenciesToFetch,
+		func(fetcher currencyFetcher.Fetcher, pairs []string) ([]currencyFetcher.Currency, error) {
+			return fetch(fetcher.(currencyFetcher.HistoricalFetcher), pairs)
+		},
+	)
+}
+
+func (f historicalFallbackFetcher) FetchByDate(currenciesToFetch []string, date time.Time) ([]currencyFetcher.Currency, error) {
+	return f.historical(currenciesToFetch, func(fetcher currencyFetcher.HistoricalFetcher, pairs []string) ([]currencyFetcher.Currency, error) {
+		return fetcher.FetchByDate(pairs, date)
+	})
+}
+
+func (f historicalFallbackFetcher) FetchByDateRange(currenciesToFetch []string, start, end time.Time) ([]currencyFetcher.Currency, error) {
+	if start.After(end) {
+		return nil, ErrInvalidDateRange
+	}
+
+	return f.historical(currenciesToFetch, func(fetcher currencyFetcher.HistoricalFetcher, pairs []string) ([]currencyFetcher.Currency, error) {
+		return fetcher.FetchByDateRange(pairs, start, end)
+	})
+}
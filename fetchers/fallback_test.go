@@ -0,0 +1,163 @@
+package fetchers_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/fetchers"
+)
+
+type (
+	pairsFetcher struct {
+		provider currency.Provider
+		// rates returned by the provider, keyed by pair
+		rates     map[string]string
+		err       error
+		requested [][]string
+	}
+
+	historicalPairsFetcher struct {
+		*pairsFetcher
+	}
+)
+
+func (f *pairsFetcher) Fetch(pairs []string) ([]currency.Currency, error) {
+	f.requested = append(f.requested, append([]string(nil), pairs...))
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	result := make([]currency.Currency, 0, len(pairs))
+
+	for _, pair := range pairs {
+		if rate, ok := f.rates[pair]; ok {
+			iso := strings.Split(pair, "_")
+			result = append(result, currency.Currency{From: iso[0], To: iso[1], Provider: f.provider, Rate: decimal.RequireFromString(rate)})
+		}
+	}
+
+	return result, nil
+}
+
+func (f historicalPairsFetcher) FetchByDate(pairs []string, _ time.Time) ([]currency.Currency, error) {
+	return f.Fetch(pairs)
+}
+
+func (f historicalPairsFetcher) FetchByDateRange(pairs []string, _, _ time.Time) ([]currency.Currency, error) {
+	return f.Fetch(pairs)
+}
+
+func providers(currencies []currency.Currency) map[string]currency.Provider {
+	result := make(map[string]currency.Provider, len(currencies))
+
+	for _, c := range currencies {
+		result[c.From+"_"+c.To] = c.Provider
+	}
+
+	return result
+}
+
+func TestFallbackFetcher_Fetch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Next provider is asked only for missing pairs", func(t *testing.T) {
+		asserts := require.New(t)
+		freeConv := &pairsFetcher{provider: currency.FreeConvProvider, rates: map[string]string{"EUR_USD": "1.17"}}
+		ecb := &pairsFetcher{provider: currency.ECBProvider, rates: map[string]string{"EUR_USD": "1.18", "EUR_RSD": "117.5", "EUR_JPY": "123.1"}}
+		nbs := &pairsFetcher{provider: currency.NBSProvider}
+
+		fetcher := fetchers.NewFallbackFetcher(fetchers.FallbackConfig{Fetchers: []fetchers.ProviderFetcher{
+			{Provider: currency.FreeConvProvider, Fetcher: freeConv},
+			{Provider: currency.ECBProvider, Fetcher: ecb},
+			{Provider: currency.NBSProvider, Fetcher: nbs},
+		}})
+
+		currencies, err := fetcher.Fetch([]string{"EUR_USD", "EUR_RSD", "EUR_USD"})
+
+		asserts.Nil(err)
+		asserts.Len(currencies, 2)
+		asserts.Equal(map[string]currency.Provider{"EUR_USD": currency.FreeConvProvider, "EUR_RSD": currency.ECBProvider}, providers(currencies))
+		asserts.Equal([][]string{{"EUR_USD", "EUR_RSD"}}, freeConv.requested)
+		asserts.Equal([][]string{{"EUR_RSD"}}, ecb.requested)
+		asserts.Empty(nbs.requested)
+	})
+
+	t.Run("Survives provider outage", func(t *testing.T) {
+		asserts := require.New(t)
+		freeConv := &pairsFetcher{provider: currency.FreeConvProvider, err: fetchers.ErrServer}
+		ecb := &pairsFetcher{provider: currency.ECBProvider, rates: map[string]string{"EUR_USD": "1.18"}}
+
+		fetcher := fetchers.NewFallbackFetcher(fetchers.FallbackConfig{Fetchers: []fetchers.ProviderFetcher{
+			{Provider: currency.FreeConvProvider, Fetcher: freeConv},
+			{Provider: currency.ECBProvider, Fetcher: ecb},
+		}})
+
+		currencies, err := fetcher.Fetch([]string{"EUR_USD"})
+
+		asserts.Nil(err)
+		asserts.Equal(map[string]currency.Provider{"EUR_USD": currency.ECBProvider}, providers(currencies))
+	})
+
+	t.Run("Fails when pairs are missing", func(t *testing.T) {
+		asserts := require.New(t)
+		freeConv := &pairsFetcher{provider: currency.FreeConvProvider, err: fetchers.ErrAPILimitReached}
+		ecb := &pairsFetcher{provider: currency.ECBProvider, rates: map[string]string{"EUR_USD": "1.18"}}
+
+		fetcher := fetchers.NewFallbackFetcher(fetchers.FallbackConfig{Fetchers: []fetchers.ProviderFetcher{
+			{Provider: currency.FreeConvProvider, Fetcher: freeConv},
+			{Provider: currency.ECBProvider, Fetcher: ecb},
+		}})
+
+		_, err := fetcher.Fetch([]string{"EUR_USD", "EUR_XYZ"})
+
+		asserts.True(errors.Is(err, fetchers.ErrPairsNotFetched))
+		asserts.Contains(err.Error(), "EUR_XYZ")
+		asserts.Contains(err.Error(), "FreeCurrConversion: API limit reached")
+	})
+
+	t.Run("Partial returns fetched pairs", func(t *testing.T) {
+		asserts := require.New(t)
+		ecb := &pairsFetcher{provider: currency.ECBProvider, rates: map[string]string{"EUR_USD": "1.18"}}
+
+		fetcher := fetchers.NewFallbackFetcher(fetchers.FallbackConfig{
+			Fetchers: []fetchers.ProviderFetcher{{Provider: currency.ECBProvider, Fetcher: ecb}},
+			Partial:  true,
+		})
+
+		currencies, err := fetcher.Fetch([]string{"EUR_USD", "EUR_XYZ"})
+
+		asserts.Nil(err)
+		asserts.Len(currencies, 1)
+	})
+}
+
+func TestFallbackFetcher_Historical(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	freeConv := &pairsFetcher{provider: currency.FreeConvProvider, rates: map[string]string{"EUR_USD": "1.17"}}
+	ecb := &pairsFetcher{provider: currency.ECBProvider, rates: map[string]string{"EUR_USD": "1.18"}}
+
+	fetcher, ok := fetchers.NewFallbackFetcher(fetchers.FallbackConfig{Fetchers: []fetchers.ProviderFetcher{
+		{Provider: currency.FreeConvProvider, Fetcher: freeConv},
+		{Provider: currency.ECBProvider, Fetcher: historicalPairsFetcher{ecb}},
+	}}).(currency.HistoricalFetcher)
+	asserts.True(ok)
+
+	currencies, err := fetcher.FetchByDate([]string{"EUR_USD"}, time.Now())
+
+	asserts.Nil(err)
+	asserts.Equal(map[string]currency.Provider{"EUR_USD": currency.ECBProvider}, providers(currencies))
+	asserts.Empty(freeConv.requested)
+
+	_, ok = fetchers.NewFallbackFetcher(fetchers.FallbackConfig{Fetchers: []fetchers.ProviderFetcher{
+		{Provider: currency.FreeConvProvider, Fetcher: freeConv},
+	}}).(currency.HistoricalFetcher)
+	asserts.False(ok)
+}
@@ -3,16 +3,24 @@ package fetchers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
 
 	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/iso4217"
 )
 
 const (
 	FreeConvFetchURL    = "https://free.currconv.com/api/v7/convert"
 	ExchangeRatesAPIURL = "https://api.exchangeratesapi.io/latest"
+	ECBURL              = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+	NBSURL              = "https://www.nbs.rs/kursnaListaModul/srednjiKurs.faces?lang=lat&type=xml"
 )
 
 type (
@@ -22,12 +30,23 @@ type (
 	}
 
 	exchangeRateAPIResponse struct {
-		Base  string             `json:"base,omitempty"`
-		Rates map[string]float32 `json:"rates,omitempty"`
-		Date  string             `json:"date,omitempty"`
+		Base  string                     `json:"base,omitempty"`
+		Rates map[string]decimal.Decimal `json:"rates,omitempty"`
+		Date  string                     `json:"date,omitempty"`
+	}
+
+	exchangeRateAPIHistoryResponse struct {
+		Base  string                                `json:"base,omitempty"`
+		Rates map[string]map[string]decimal.Decimal `json:"rates,omitempty"`
 	}
 
 	currencyChannel chan interface{}
+
+	// RetryAfterError wraps the error returned for a response with a Retry-After header
+	RetryAfterError struct {
+		Err   error
+		After time.Duration
+	}
 )
 
 var (
@@ -37,8 +56,44 @@ var (
 	ErrServer            = errors.New("server error")
 	ErrUnknown           = errors.New("unknown error")
 	ErrAPILimitReached   = errors.New("API limit reached")
+	ErrInvalidDateRange  = errors.New("start date cannot be after end date")
+	ErrTooManyRequests   = errors.New("too many requests")
 )
 
+func (e RetryAfterError) Error() string {
+	return fmt.Sprintf("%v, retry after %s", e.Err, e.After)
+}
+
+func (e RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// withRetryAfter wraps err in RetryAfterError when the response has a valid Retry-After header,
+// given either as delay in seconds or as HTTP date
+func withRetryAfter(res *http.Response, err error) error {
+	value := res.Header.Get("Retry-After")
+
+	if err == nil || value == "" {
+		return err
+	}
+
+	if seconds, parseErr := strconv.ParseInt(value, 10, 64); parseErr == nil && seconds >= 0 {
+		return RetryAfterError{Err: err, After: time.Duration(seconds) * time.Second}
+	}
+
+	if date, parseErr := http.ParseTime(value); parseErr == nil {
+		after := time.Until(date)
+
+		if after < 0 {
+			after = 0
+		}
+
+		return RetryAfterError{Err: err, After: after}
+	}
+
+	return err
+}
+
 func getData(ctx context.Context, url string, currencies []string) (*http.Request, string, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 
@@ -58,6 +113,21 @@ func getData(ctx context.Context, url string, currencies []string) (*http.Reques
 	return req, strings.TrimRight(builder.String(), ","), nil
 }
 
+func handleHTTPStatusCodeError(res *http.Response) error {
+	switch {
+	case res.StatusCode == http.StatusOK:
+		return nil
+	case res.StatusCode == http.StatusTooManyRequests:
+		return withRetryAfter(res, ErrTooManyRequests)
+	case res.StatusCode >= http.StatusBadRequest && res.StatusCode < http.StatusInternalServerError:
+		return ErrClient
+	case res.StatusCode >= http.StatusInternalServerError:
+		return withRetryAfter(res, ErrServer)
+	default:
+		return ErrUnknown
+	}
+}
+
 func appendToCurrencies(
 	wg *sync.WaitGroup,
 	c currencyChannel,
@@ -68,13 +138,17 @@ func appendToCurrencies(
 
 	for data := range c {
 		switch casted := data.(type) {
-		case map[string]float32:
+		case map[string]decimal.Decimal:
 			for key, cur := range casted {
-				isoCurrencies := strings.Split(key, "_")
+				pair, err := iso4217.SplitPair(key)
+
+				if err != nil {
+					continue
+				}
 
 				*currencies = append(*currencies, currencyFetcher.Currency{
-					From:     isoCurrencies[0],
-					To:       isoCurrencies[1],
+					From:     pair.From,
+					To:       pair.To,
 					Provider: provider,
 					Rate:     cur,
 				})
@@ -91,3 +165,70 @@ func appendToCurrencies(
 		}
 	}
 }
+
+// crossRates builds the requested pairs from rates quoted against a single
+// base currency, where rates[X] is the amount of X for one unit of the base.
+// Pairs with a currency missing from rates are skipped.
+func crossRates(
+	rates map[string]decimal.Decimal,
+	pairs []iso4217.Pair,
+	provider currencyFetcher.Provider,
+) []currencyFetcher.Currency {
+	currencies := make([]currencyFetcher.Currency, 0, len(pairs))
+
+	for _, pair := range pairs {
+		from, fromExists := rates[pair.From]
+		to, toExists := rates[pair.To]
+
+		if !fromExists || !toExists || from.IsZero() {
+			continue
+		}
+
+		currencies = append(currencies, currencyFetcher.Currency{
+			From:     pair.From,
+			To:       pair.To,
+			Provider: provider,
+			Rate:     to.DivRound(from, 8),
+		})
+	}
+
+	return currencies
+}
+
+// requestedOnly returns rates of the requested pairs.
+// Providers returning more than asked for, like whole rate lists, are trimmed.
+func requestedOnly(currencies []currencyFetcher.Currency, pairs []string) []currencyFetcher.Currency {
+	requested := make(map[string]bool, len(pairs))
+
+	for _, pair := range pairs {
+		requested[pair] = true
+	}
+
+	result := make([]currencyFetcher.Currency, 0, len(currencies))
+
+	for _, c := range currencies {
+		if requested[c.From+"_"+c.To] {
+			result = append(result, c)
+		}
+	}
+
+	return result
+}
+
+// splitPairs rejects malformed pairs before any request is sent, codes are not checked
+// against the catalogue since providers may quote currencies it does not list
+func splitPairs(currenciesToFetch []string) ([]iso4217.Pair, error) {
+	pairs := make([]iso4217.Pair, 0, len(currenciesToFetch))
+
+	for _, c := range currenciesToFetch {
+		pair, err := iso4217.SplitPair(c)
+
+		if err != nil {
+			return nil, err
+		}
+
+		pairs = append(pairs, pair)
+	}
+
+	return pairs, nil
+}
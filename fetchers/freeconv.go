@@ -9,6 +9,8 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/shopspring/decimal"
+
 	currencyFetcher "github.com/malusev998/currency"
 )
 
@@ -18,6 +20,8 @@ type FreeCurrConvFetcher struct {
 	APIKey        string
 	MaxPerHour    int
 	MaxPerRequest int
+	// Quota tracks requests across runs, MaxPerHour is checked only per fetch when it is nil
+	Quota *QuotaTracker
 }
 
 func (f FreeCurrConvFetcher) fetchCurrencies(
@@ -68,7 +72,7 @@ func (f FreeCurrConvFetcher) fetchCurrencies(
 	body, _ = ioutil.ReadAll(res.Body)
 
 	if res.StatusCode == http.StatusOK {
-		data := map[string]float32{}
+		data := map[string]decimal.Decimal{}
 
 		if err := json.Unmarshal(body, &data); err != nil {
 			errorChannel <- err
@@ -98,13 +102,18 @@ func (f FreeCurrConvFetcher) fetchCurrencies(
 		return
 	}
 
+	if res.StatusCode == http.StatusTooManyRequests {
+		errorChannel <- withRetryAfter(res, ErrTooManyRequests)
+		return
+	}
+
 	if res.StatusCode >= http.StatusBadRequest && res.StatusCode < http.StatusInternalServerError {
 		errorChannel <- ErrClient
 		return
 	}
 
 	if res.StatusCode >= http.StatusInternalServerError {
-		errorChannel <- ErrServer
+		errorChannel <- withRetryAfter(res, ErrServer)
 		return
 	}
 	errorChannel <- ErrUnknown
@@ -114,6 +123,10 @@ func (f FreeCurrConvFetcher) Fetch(currenciesToFetch []string) ([]currencyFetche
 	var wg, appendWg sync.WaitGroup
 	var numberOfRequests int
 
+	if _, err := splitPairs(currenciesToFetch); err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -127,6 +140,12 @@ func (f FreeCurrConvFetcher) Fetch(currenciesToFetch []string) ([]currencyFetche
 		return nil, ErrNotEnoughRequests
 	}
 
+	if f.Quota != nil {
+		if err := f.Quota.Reserve(numberOfRequests); err != nil {
+			return nil, err
+		}
+	}
+
 	channel := make(chan interface{})
 	errorChannel := make(chan error)
 
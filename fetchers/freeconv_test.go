@@ -36,7 +36,7 @@ func (h httpHandlerLimitReached) ServeHTTP(writer http.ResponseWriter, _ *http.R
 	_, _ = writer.Write([]byte("{\"error\": \"Free API limit reached.\", \"status\": 400}"))
 }
 
-var Currencies = map[string]float32{"EUR_RSD": 117.4, "USD_EUR": 1.2, "EUR_USD": 0.8, "RSD_EUR": 0.001}
+var Currencies = map[string]string{"EUR_RSD": "117.4", "USD_EUR": "1.2", "EUR_USD": "0.8", "RSD_EUR": "0.001"}
 
 func (h httpHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	apiKey := request.URL.Query().Get("apiKey")
@@ -47,10 +47,10 @@ func (h httpHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request
 	}
 
 	query := strings.Split(request.URL.Query().Get("q"), ",")
-	data := make(map[string]float32)
+	data := make(map[string]json.Number)
 
 	for _, q := range query {
-		data[q] = Currencies[q]
+		data[q] = json.Number(Currencies[q])
 	}
 
 	bytes, _ := json.Marshal(data)
@@ -82,7 +82,7 @@ func TestFreeCurrConvFetcher_Fetch(t *testing.T) {
 			pair := fmt.Sprintf("%s_%s", currencies[i].From, currencies[i].To)
 			asserts.Contains(Currencies, pair)
 			asserts.Equal(currency_fetcher.FreeConvProvider, currencies[i].Provider)
-			asserts.Equal(Currencies[pair], currencies[i].Rate)
+			asserts.Equal(Currencies[pair], currencies[i].Rate.String())
 		}
 	})
 
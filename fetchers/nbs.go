@@ -0,0 +1,138 @@
+package fetchers
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	currencyFetcher "github.com/malusev998/currency"
+)
+
+const (
+	nbsBaseCurrency = "RSD"
+	nbsDateFormat   = "02.01.2006"
+)
+
+type (
+	NBSFetcher struct {
+		Ctx context.Context
+		URL string
+	}
+
+	NBSRate struct {
+		Code        string          `xml:"Code"`
+		Currency    string          `xml:"Currency"`
+		Country     string          `xml:"Country"`
+		Unit        int64           `xml:"Unit"`
+		BuyingRate  decimal.Decimal `xml:"BuyingRate"`
+		MiddleRate  decimal.Decimal `xml:"MiddleRate"`
+		SellingRate decimal.Decimal `xml:"SellingRate"`
+	}
+
+	nbsResponse struct {
+		XMLName xml.Name  `xml:"ExchangeRateList"`
+		Date    string    `xml:"Date"`
+		Items   []NBSRate `xml:"Item"`
+	}
+)
+
+// PerUnit returns the middle rate in RSD for exactly one unit of the currency.
+// NBS quotes some currencies (JPY, HUF...) per 100 units.
+func (r NBSRate) PerUnit() decimal.Decimal {
+	if r.Unit <= 1 {
+		return r.MiddleRate
+	}
+
+	return r.MiddleRate.Div(decimal.NewFromInt(r.Unit))
+}
+
+func (n NBSFetcher) fetchExchangeRateList(ctx context.Context, url string) ([]NBSRate, time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	req.Header.Add("Accept", "application/xml")
+
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	defer res.Body.Close()
+
+	if err := handleHTTPStatusCodeError(res); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var data nbsResponse
+
+	if err := xml.NewDecoder(res.Body).Decode(&data); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	createdAt, err := time.Parse(nbsDateFormat, data.Date)
+
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return data.Items, createdAt, nil
+}
+
+// Fetch downloads the NBS exchange rate list and returns the middle rate
+// for every requested pair, normalised to one unit of the currency.
+// Pairs without RSD are calculated as a cross rate through RSD.
+// CreatedAt is the day of the exchange rate list, not the time of the request.
+func (n NBSFetcher) Fetch(currenciesToFetch []string) ([]currencyFetcher.Currency, error) {
+	pairs, err := splitPairs(currenciesToFetch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	url := n.URL
+
+	if url == "" {
+		url = NBSURL
+	}
+
+	ctx := n.Ctx
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	list, createdAt, err := n.fetchExchangeRateList(ctx, url)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// crossRates expects the amount of each currency for one RSD
+	rates := make(map[string]decimal.Decimal, len(list)+1)
+	rates[nbsBaseCurrency] = decimal.NewFromInt(1)
+
+	for _, item := range list {
+		perUnit := item.PerUnit()
+
+		if perUnit.IsZero() {
+			continue
+		}
+
+		rates[item.Currency] = decimal.NewFromInt(1).DivRound(perUnit, 16)
+	}
+
+	currencies := crossRates(rates, pairs, currencyFetcher.NBSProvider)
+
+	for i := range currencies {
+		currencies[i].CreatedAt = createdAt
+	}
+
+	return currencies, nil
+}
@@ -0,0 +1,77 @@
+package fetchers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/fetchers"
+)
+
+func TestNBSRate_PerUnit(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+
+	rate := fetchers.NBSRate{Unit: 100, MiddleRate: decimal.RequireFromString("95.1806")}
+	asserts.True(decimal.RequireFromString("0.951806").Equal(rate.PerUnit()))
+
+	rate = fetchers.NBSRate{Unit: 1, MiddleRate: decimal.RequireFromString("117.5840")}
+	asserts.True(decimal.RequireFromString("117.584").Equal(rate.PerUnit()))
+}
+
+func TestNBSFetcher_Fetch(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		http.ServeFile(writer, request, "testdata/nbs-exchange-rate-list.xml")
+	}))
+	defer server.Close()
+
+	t.Run("Middle rates per one unit", func(t *testing.T) {
+		asserts := require.New(t)
+		fetcher := fetchers.NBSFetcher{URL: server.URL}
+
+		currencies, err := fetcher.Fetch([]string{"EUR_RSD", "JPY_RSD", "RSD_EUR"})
+
+		asserts.Nil(err)
+		asserts.Len(currencies, 3)
+		asserts.Equal("EUR", currencies[0].From)
+		asserts.Equal("RSD", currencies[0].To)
+		asserts.Equal(currency.NBSProvider, currencies[0].Provider)
+		asserts.Equal("117.584", currencies[0].Rate.String())
+		asserts.Equal("0.951806", currencies[1].Rate.String())
+		asserts.Equal("0.00850456", currencies[2].Rate.String())
+
+		for _, c := range currencies {
+			asserts.Equal(time.Date(2020, 10, 16, 0, 0, 0, 0, time.UTC), c.CreatedAt)
+		}
+	})
+
+	t.Run("Cross rates through RSD", func(t *testing.T) {
+		asserts := require.New(t)
+		fetcher := fetchers.NBSFetcher{URL: server.URL}
+
+		currencies, err := fetcher.Fetch([]string{"EUR_USD", "EUR_HUF", "EUR_GBP"})
+
+		asserts.Nil(err)
+		asserts.Len(currencies, 2)
+		asserts.Equal("1.17072976", currencies[0].Rate.String())
+		asserts.Equal("363.11979643", currencies[1].Rate.String())
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		asserts := require.New(t)
+		errorServer := httptest.NewServer(httpStatusHandler(http.StatusInternalServerError))
+		defer errorServer.Close()
+
+		currencies, err := fetchers.NBSFetcher{URL: errorServer.URL}.Fetch([]string{"EUR_RSD"})
+
+		asserts.Nil(currencies)
+		asserts.True(errors.Is(err, fetchers.ErrServer))
+	})
+}
@@ -0,0 +1,123 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	currencyFetcher "github.com/malusev998/currency"
+)
+
+const DefaultQuotaWindow = time.Hour
+
+type (
+	// QuotaTracker spends requests against a provider quota kept in Storage,
+	// so separate runs and replicas sharing the storage respect the same limit.
+	QuotaTracker struct {
+		Ctx      context.Context
+		Storage  currencyFetcher.QuotaStorage
+		Provider currencyFetcher.Provider
+		// Limit is the number of requests allowed per window
+		Limit int
+		// Window defaults to DefaultQuotaWindow, windows are aligned to its multiples
+		Window time.Duration
+		// MaxDefer is the longest wait for the next window when the quota is spent,
+		// zero refuses the fetch right away
+		MaxDefer time.Duration
+
+		now   func() time.Time
+		sleep func(ctx context.Context, d time.Duration) error
+	}
+
+	QuotaStatus struct {
+		Provider  currencyFetcher.Provider
+		Used      int
+		Limit     int
+		Remaining int
+		ResetsAt  time.Time
+	}
+)
+
+// window returns the current time, the start of its window and the window length
+func (q *QuotaTracker) window() (time.Time, time.Time, time.Duration) {
+	window := q.Window
+
+	if window <= 0 {
+		window = DefaultQuotaWindow
+	}
+
+	now := time.Now()
+
+	if q.now != nil {
+		now = q.now()
+	}
+
+	return now, now.UTC().Truncate(window), window
+}
+
+// Reserve spends n requests from the current window. When the window has not enough
+// requests left it waits for the next one if that is at most MaxDefer away,
+// otherwise ErrQuotaExceeded is returned.
+func (q *QuotaTracker) Reserve(n int) error {
+	if n > q.Limit {
+		return fmt.Errorf("%w: %d requests needed, %s allows %d per window", currencyFetcher.ErrQuotaExceeded, n, q.Provider, q.Limit)
+	}
+
+	now, start, window := q.window()
+	used, err := q.Storage.Reserve(q.Provider, start, n, q.Limit)
+
+	if !errors.Is(err, currencyFetcher.ErrQuotaExceeded) {
+		return err
+	}
+
+	reset := start.Add(window)
+
+	if q.MaxDefer <= 0 || reset.Sub(now) > q.MaxDefer {
+		return fmt.Errorf("%w: %s used %d of %d requests, resets at %s", err, q.Provider, used, q.Limit, reset.Format(time.RFC3339))
+	}
+
+	ctx := q.Ctx
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	sleepFn := sleep
+
+	if q.sleep != nil {
+		sleepFn = q.sleep
+	}
+
+	if err := sleepFn(ctx, reset.Sub(now)); err != nil {
+		return err
+	}
+
+	_, err = q.Storage.Reserve(q.Provider, reset, n, q.Limit)
+
+	return err
+}
+
+// Status returns usage of the current window
+func (q *QuotaTracker) Status() (QuotaStatus, error) {
+	_, start, window := q.window()
+	used, err := q.Storage.Used(q.Provider, start)
+
+	if err != nil {
+		return QuotaStatus{}, err
+	}
+
+	remaining := q.Limit - used
+
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return QuotaStatus{
+		Provider:  q.Provider,
+		Used:      used,
+		Limit:     q.Limit,
+		Remaining: remaining,
+		ResetsAt:  start.Add(window),
+	}, nil
+}
@@ -0,0 +1,119 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/storage"
+)
+
+func newTestQuotaTracker(limit int, maxDefer time.Duration, now time.Time, waits *[]time.Duration) *QuotaTracker {
+	return &QuotaTracker{
+		Storage:  storage.NewMemoryQuotaStorage(),
+		Provider: currencyFetcher.FreeConvProvider,
+		Limit:    limit,
+		MaxDefer: maxDefer,
+		now: func() time.Time {
+			return now
+		},
+		sleep: func(_ context.Context, d time.Duration) error {
+			*waits = append(*waits, d)
+			return nil
+		},
+	}
+}
+
+func TestQuotaTracker_Reserve(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2020, 10, 16, 12, 50, 0, 0, time.UTC)
+
+	t.Run("Refuses when quota is spent", func(t *testing.T) {
+		asserts := require.New(t)
+		var waits []time.Duration
+		quota := newTestQuotaTracker(10, 0, now, &waits)
+
+		asserts.Nil(quota.Reserve(6))
+		asserts.True(errors.Is(quota.Reserve(5), currencyFetcher.ErrQuotaExceeded))
+		asserts.Nil(quota.Reserve(4))
+		asserts.Empty(waits)
+
+		status, err := quota.Status()
+		asserts.Nil(err)
+		asserts.Equal(QuotaStatus{
+			Provider:  currencyFetcher.FreeConvProvider,
+			Used:      10,
+			Limit:     10,
+			Remaining: 0,
+			ResetsAt:  time.Date(2020, 10, 16, 13, 0, 0, 0, time.UTC),
+		}, status)
+	})
+
+	t.Run("Defers to the next window", func(t *testing.T) {
+		asserts := require.New(t)
+		var waits []time.Duration
+		quota := newTestQuotaTracker(10, 15*time.Minute, now, &waits)
+
+		asserts.Nil(quota.Reserve(10))
+		asserts.Nil(quota.Reserve(3))
+		asserts.Equal([]time.Duration{10 * time.Minute}, waits)
+
+		used, err := quota.Storage.Used(currencyFetcher.FreeConvProvider, time.Date(2020, 10, 16, 13, 0, 0, 0, time.UTC))
+		asserts.Nil(err)
+		asserts.Equal(3, used)
+	})
+
+	t.Run("Does not defer past MaxDefer", func(t *testing.T) {
+		asserts := require.New(t)
+		var waits []time.Duration
+		quota := newTestQuotaTracker(10, 5*time.Minute, now, &waits)
+
+		asserts.Nil(quota.Reserve(10))
+		asserts.True(errors.Is(quota.Reserve(1), currencyFetcher.ErrQuotaExceeded))
+		asserts.Empty(waits)
+	})
+
+	t.Run("Request larger than limit", func(t *testing.T) {
+		var waits []time.Duration
+		quota := newTestQuotaTracker(10, time.Hour, now, &waits)
+
+		require.True(t, errors.Is(quota.Reserve(11), currencyFetcher.ErrQuotaExceeded))
+	})
+}
+
+func TestFreeCurrConvFetcher_Quota(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	server := httptest.NewServer(httpHandler{})
+	defer server.Close()
+
+	quota := &QuotaTracker{
+		Storage:  storage.NewMemoryQuotaStorage(),
+		Provider: currencyFetcher.FreeConvProvider,
+		Limit:    3,
+	}
+
+	fetcher := FreeCurrConvFetcher{
+		URL:           server.URL,
+		APIKey:        "1234566789",
+		MaxPerHour:    100,
+		MaxPerRequest: 2,
+		Quota:         quota,
+	}
+
+	_, err := fetcher.Fetch([]string{"EUR_USD", "USD_EUR", "EUR_RSD", "RSD_EUR"})
+	asserts.Nil(err)
+
+	_, err = fetcher.Fetch([]string{"EUR_USD", "USD_EUR", "EUR_RSD", "RSD_EUR"})
+	asserts.True(errors.Is(err, currencyFetcher.ErrQuotaExceeded))
+
+	status, err := quota.Status()
+	asserts.Nil(err)
+	asserts.Equal(2, status.Used)
+	asserts.Equal(1, status.Remaining)
+}
@@ -0,0 +1,252 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	currencyFetcher "github.com/malusev998/currency"
+)
+
+const (
+	DefaultRetryMaxAttempts    = 3
+	DefaultRetryInitialBackoff = time.Second
+	DefaultRetryMaxBackoff     = 30 * time.Second
+	DefaultRetryMultiplier     = 2
+	DefaultRetryMaxRetryAfter  = 5 * time.Minute
+)
+
+var ErrUnknownRetryableError = errors.New("unknown retryable error")
+
+type (
+	// RetryPolicy configures retries of a fetcher, zero values other than Jitter are replaced with defaults.
+	RetryPolicy struct {
+		// MaxAttempts includes the first attempt, 1 disables retries
+		MaxAttempts    int
+		InitialBackoff time.Duration
+		MaxBackoff     time.Duration
+		Multiplier     float64
+		// Jitter is the fraction of backoff randomly taken off each wait,
+		// between 0 and 1, zero disables it
+		Jitter float64
+		// MaxRetryAfter is the longest Retry-After that is waited for,
+		// errors asking to wait longer are returned right away
+		MaxRetryAfter time.Duration
+		// Retryable reports whether err is worth retrying, IsRetryable is used when nil
+		Retryable func(err error) bool
+	}
+
+	RetryConfig struct {
+		Ctx    context.Context
+		Policy RetryPolicy
+	}
+
+	retryFetcher struct {
+		ctx     context.Context
+		fetcher currencyFetcher.Fetcher
+		policy  RetryPolicy
+		sleep   func(ctx context.Context, d time.Duration) error
+	}
+
+	historicalRetryFetcher struct {
+		retryFetcher
+		historical currencyFetcher.HistoricalFetcher
+	}
+)
+
+// retryableErrors maps names used in configuration to errors
+var retryableErrors = map[string]func(err error) bool{
+	"server":          func(err error) bool { return errors.Is(err, ErrServer) },
+	"client":          func(err error) bool { return errors.Is(err, ErrClient) },
+	"unknown":         func(err error) bool { return errors.Is(err, ErrUnknown) },
+	"toomanyrequests": func(err error) bool { return errors.Is(err, ErrTooManyRequests) },
+	"apilimitreached": func(err error) bool { return errors.Is(err, ErrAPILimitReached) },
+	"network":         isNetworkError,
+}
+
+// IsRetryable is the default RetryPolicy.Retryable, server errors,
+// too many requests and network errors are retried
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrServer) || errors.Is(err, ErrTooManyRequests) || isNetworkError(err)
+}
+
+func isNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// ConvertToRetryableFromStrings builds RetryPolicy.Retryable from error names:
+// server, client, unknown, tooManyRequests, apiLimitReached and network.
+// Empty names return nil so the policy falls back to IsRetryable.
+func ConvertToRetryableFromStrings(names []string) (func(err error) bool, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	checks := make([]func(err error) bool, 0, len(names))
+
+	for _, name := range names {
+		check, ok := retryableErrors[strings.ToLower(name)]
+
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownRetryableError, name)
+		}
+
+		checks = append(checks, check)
+	}
+
+	return func(err error) bool {
+		for _, check := range checks {
+			if check(err) {
+				return true
+			}
+		}
+
+		return false
+	}, nil
+}
+
+// NewRetryFetcher retries failed fetches of fetcher according to config.Policy.
+// The returned fetcher implements HistoricalFetcher when fetcher does.
+func NewRetryFetcher(fetcher currencyFetcher.Fetcher, config RetryConfig) currencyFetcher.Fetcher {
+	ctx := config.Ctx
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	r := retryFetcher{
+		ctx:     ctx,
+		fetcher: fetcher,
+		policy:  config.Policy.withDefaults(),
+		sleep:   sleep,
+	}
+
+	if historical, ok := fetcher.(currencyFetcher.HistoricalFetcher); ok {
+		return historicalRetryFetcher{retryFetcher: r, historical: historical}
+	}
+
+	return r
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryMaxAttempts
+	}
+
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = DefaultRetryInitialBackoff
+	}
+
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultRetryMaxBackoff
+	}
+
+	if p.Multiplier < 1 {
+		p.Multiplier = DefaultRetryMultiplier
+	}
+
+	if p.Jitter < 0 {
+		p.Jitter = 0
+	}
+
+	if p.Jitter > 1 {
+		p.Jitter = 1
+	}
+
+	if p.MaxRetryAfter <= 0 {
+		p.MaxRetryAfter = DefaultRetryMaxRetryAfter
+	}
+
+	if p.Retryable == nil {
+		p.Retryable = IsRetryable
+	}
+
+	return p
+}
+
+// backoff returns the wait before the attempt following the given one, starting from 1
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+
+	if backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+
+	return time.Duration(backoff - backoff*p.Jitter*rand.Float64())
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r retryFetcher) retry(fetch func() ([]currencyFetcher.Currency, error)) ([]currencyFetcher.Currency, error) {
+	for attempt := 1; ; attempt++ {
+		currencies, err := fetch()
+
+		if err == nil {
+			return currencies, nil
+		}
+
+		if !r.policy.Retryable(err) {
+			return nil, err
+		}
+
+		if attempt >= r.policy.MaxAttempts {
+			return nil, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		wait := r.policy.backoff(attempt)
+
+		var retryAfter RetryAfterError
+
+		if errors.As(err, &retryAfter) {
+			if retryAfter.After > r.policy.MaxRetryAfter {
+				return nil, err
+			}
+
+			wait = retryAfter.After
+		}
+
+		if sleepErr := r.sleep(r.ctx, wait); sleepErr != nil {
+			return nil, fmt.Errorf("stopped retrying after %d attempts: %w (last error: %v)", attempt, sleepErr, err)
+		}
+	}
+}
+
+func (r retryFetcher) Fetch(currenciesToFetch []string) ([]currencyFetcher.Currency, error) {
+	return r.retry(func() ([]currencyFetcher.Currency, error) {
+		return r.fetcher.Fetch(currenciesToFetch)
+	})
+}
+
+func (r historicalRetryFetcher) FetchByDate(currenciesToFetch []string, date time.Time) ([]currencyFetcher.Currency, error) {
+	return r.retry(func() ([]currencyFetcher.Currency, error) {
+		return r.historical.FetchByDate(currenciesToFetch, date)
+	})
+}
+
+func (r historicalRetryFetcher) FetchByDateRange(currenciesToFetch []string, start, end time.Time) ([]currencyFetcher.Currency, error) {
+	return r.retry(func() ([]currencyFetcher.Currency, error) {
+		return r.historical.FetchByDateRange(currenciesToFetch, start, end)
+	})
+}
@@ -0,0 +1,230 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	currencyFetcher "github.com/malusev998/currency"
+)
+
+type (
+	stubFetcher struct {
+		errs  []error
+		calls int
+	}
+
+	stubHistoricalFetcher struct {
+		*stubFetcher
+	}
+)
+
+func (f *stubFetcher) Fetch([]string) ([]currencyFetcher.Currency, error) {
+	f.calls++
+
+	if len(f.errs) >= f.calls && f.errs[f.calls-1] != nil {
+		return nil, f.errs[f.calls-1]
+	}
+
+	return []currencyFetcher.Currency{{From: "EUR", To: "USD", Rate: decimal.RequireFromString("1.17")}}, nil
+}
+
+func (f stubHistoricalFetcher) FetchByDate(currencies []string, _ time.Time) ([]currencyFetcher.Currency, error) {
+	return f.Fetch(currencies)
+}
+
+func (f stubHistoricalFetcher) FetchByDateRange(currencies []string, _, _ time.Time) ([]currencyFetcher.Currency, error) {
+	return f.Fetch(currencies)
+}
+
+func newTestRetryFetcher(fetcher currencyFetcher.Fetcher, policy RetryPolicy, waits *[]time.Duration) currencyFetcher.Fetcher {
+	f := NewRetryFetcher(fetcher, RetryConfig{Policy: policy})
+	record := func(_ context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+
+	switch casted := f.(type) {
+	case historicalRetryFetcher:
+		casted.sleep = record
+		return casted
+	case retryFetcher:
+		casted.sleep = record
+		return casted
+	}
+
+	return f
+}
+
+func TestRetryFetcher_Fetch(t *testing.T) {
+	t.Parallel()
+	policy := RetryPolicy{MaxAttempts: 4, InitialBackoff: time.Second, MaxBackoff: 3 * time.Second, Multiplier: 2}
+
+	t.Run("Retries with exponential backoff", func(t *testing.T) {
+		asserts := require.New(t)
+		var waits []time.Duration
+		stub := &stubFetcher{errs: []error{ErrServer, ErrServer, ErrServer}}
+
+		currencies, err := newTestRetryFetcher(stub, policy, &waits).Fetch([]string{"EUR_USD"})
+
+		asserts.Nil(err)
+		asserts.Len(currencies, 1)
+		asserts.Equal(4, stub.calls)
+		asserts.Equal([]time.Duration{time.Second, 2 * time.Second, 3 * time.Second}, waits)
+	})
+
+	t.Run("Gives up after max attempts", func(t *testing.T) {
+		asserts := require.New(t)
+		var waits []time.Duration
+		stub := &stubFetcher{errs: []error{ErrServer, ErrServer, ErrServer, ErrServer}}
+
+		_, err := newTestRetryFetcher(stub, policy, &waits).Fetch([]string{"EUR_USD"})
+
+		asserts.True(errors.Is(err, ErrServer))
+		asserts.Equal(4, stub.calls)
+		asserts.Len(waits, 3)
+	})
+
+	t.Run("Does not retry client errors", func(t *testing.T) {
+		asserts := require.New(t)
+		var waits []time.Duration
+		stub := &stubFetcher{errs: []error{ErrAPILimitReached}}
+
+		_, err := newTestRetryFetcher(stub, policy, &waits).Fetch([]string{"EUR_USD"})
+
+		asserts.Equal(ErrAPILimitReached, err)
+		asserts.Equal(1, stub.calls)
+		asserts.Empty(waits)
+	})
+
+	t.Run("Honours Retry-After", func(t *testing.T) {
+		asserts := require.New(t)
+		var waits []time.Duration
+		stub := &stubFetcher{errs: []error{
+			RetryAfterError{Err: ErrTooManyRequests, After: 10 * time.Second},
+			RetryAfterError{Err: ErrTooManyRequests, After: time.Hour},
+		}}
+
+		_, err := newTestRetryFetcher(stub, policy, &waits).Fetch([]string{"EUR_USD"})
+
+		asserts.True(errors.Is(err, ErrTooManyRequests))
+		asserts.Equal(2, stub.calls)
+		asserts.Equal([]time.Duration{10 * time.Second}, waits)
+	})
+
+	t.Run("Jitter shortens backoff", func(t *testing.T) {
+		asserts := require.New(t)
+		p := policy
+		p.Jitter = 0.5
+
+		for attempt := 1; attempt <= 10; attempt++ {
+			backoff := p.withDefaults().backoff(1)
+
+			asserts.True(backoff > time.Second/2 && backoff <= time.Second, backoff.String())
+		}
+	})
+
+	t.Run("Custom retryable errors", func(t *testing.T) {
+		asserts := require.New(t)
+		var waits []time.Duration
+		retryable, err := ConvertToRetryableFromStrings([]string{"apiLimitReached"})
+		asserts.Nil(err)
+
+		p := policy
+		p.Retryable = retryable
+		stub := &stubFetcher{errs: []error{ErrAPILimitReached, ErrServer}}
+
+		_, err = newTestRetryFetcher(stub, p, &waits).Fetch([]string{"EUR_USD"})
+
+		asserts.Equal(ErrServer, err)
+		asserts.Equal(2, stub.calls)
+
+		_, err = ConvertToRetryableFromStrings([]string{"sometimes"})
+		asserts.True(errors.Is(err, ErrUnknownRetryableError))
+	})
+
+	t.Run("Stops waiting when context is done", func(t *testing.T) {
+		asserts := require.New(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		stub := &stubFetcher{errs: []error{ErrServer}}
+
+		_, err := NewRetryFetcher(stub, RetryConfig{Ctx: ctx, Policy: policy}).Fetch([]string{"EUR_USD"})
+
+		asserts.True(errors.Is(err, context.Canceled))
+		asserts.Contains(err.Error(), ErrServer.Error())
+		asserts.Equal(1, stub.calls)
+	})
+
+	t.Run("Keeps historical support", func(t *testing.T) {
+		asserts := require.New(t)
+		var waits []time.Duration
+		stub := &stubFetcher{errs: []error{ErrServer}}
+
+		f, ok := newTestRetryFetcher(stubHistoricalFetcher{stub}, policy, &waits).(currencyFetcher.HistoricalFetcher)
+		asserts.True(ok)
+
+		_, err := f.FetchByDate([]string{"EUR_USD"}, time.Now())
+		asserts.Nil(err)
+		asserts.Equal(2, stub.calls)
+
+		_, ok = NewRetryFetcher(&stubFetcher{}, RetryConfig{}).(currencyFetcher.HistoricalFetcher)
+		asserts.False(ok)
+	})
+}
+
+func TestRetryFetcher_FreeCurrConv(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			writer.Header().Set("Retry-After", "7")
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		httpHandler{}.ServeHTTP(writer, request)
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	fetcher := newTestRetryFetcher(FreeCurrConvFetcher{
+		URL:           server.URL,
+		APIKey:        "1234566789",
+		MaxPerHour:    300,
+		MaxPerRequest: 2,
+	}, RetryPolicy{}, &waits)
+
+	currencies, err := fetcher.Fetch([]string{"EUR_USD"})
+
+	asserts.Nil(err)
+	asserts.Len(currencies, 1)
+	asserts.Equal([]time.Duration{7 * time.Second}, waits)
+}
+
+func TestWithRetryAfter(t *testing.T) {
+	asserts := require.New(t)
+	res := &http.Response{Header: http.Header{}}
+
+	asserts.Equal(ErrServer, withRetryAfter(res, ErrServer))
+
+	res.Header.Set("Retry-After", "120")
+	asserts.Equal(RetryAfterError{Err: ErrServer, After: 2 * time.Minute}, withRetryAfter(res, ErrServer))
+
+	res.Header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	var retryAfter RetryAfterError
+	asserts.True(errors.As(withRetryAfter(res, ErrServer), &retryAfter))
+	asserts.InDelta(float64(time.Hour), float64(retryAfter.After), float64(2*time.Second))
+
+	res.Header.Set("Retry-After", "soon")
+	asserts.Equal(ErrServer, withRetryAfter(res, ErrServer))
+}
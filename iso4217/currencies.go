@@ -0,0 +1,170 @@
+package iso4217
+
+// currencies are the active ISO 4217 currencies, funds and precious metals are left out
+var currencies = []Currency{
+	{Code: "AED", Numeric: "784", Name: "UAE Dirham", MinorUnits: 2, Symbol: "د.إ"},
+	{Code: "AFN", Numeric: "971", Name: "Afghani", MinorUnits: 2, Symbol: "؋"},
+	{Code: "ALL", Numeric: "008", Name: "Lek", MinorUnits: 2, Symbol: "L"},
+	{Code: "AMD", Numeric: "051", Name: "Armenian Dram", MinorUnits: 2, Symbol: "֏"},
+	{Code: "AOA", Numeric: "973", Name: "Kwanza", MinorUnits: 2, Symbol: "Kz"},
+	{Code: "ARS", Numeric: "032", Name: "Argentine Peso", MinorUnits: 2, Symbol: "$"},
+	{Code: "AUD", Numeric: "036", Name: "Australian Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "AWG", Numeric: "533", Name: "Aruban Florin", MinorUnits: 2, Symbol: "ƒ"},
+	{Code: "AZN", Numeric: "944", Name: "Azerbaijan Manat", MinorUnits: 2, Symbol: "₼"},
+	{Code: "BAM", Numeric: "977", Name: "Convertible Mark", MinorUnits: 2, Symbol: "KM"},
+	{Code: "BBD", Numeric: "052", Name: "Barbados Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "BDT", Numeric: "050", Name: "Taka", MinorUnits: 2, Symbol: "৳"},
+	{Code: "BHD", Numeric: "048", Name: "Bahraini Dinar", MinorUnits: 3, Symbol: ".د.ب"},
+	{Code: "BIF", Numeric: "108", Name: "Burundi Franc", MinorUnits: 0, Symbol: "FBu"},
+	{Code: "BMD", Numeric: "060", Name: "Bermudian Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "BND", Numeric: "096", Name: "Brunei Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "BOB", Numeric: "068", Name: "Boliviano", MinorUnits: 2, Symbol: "Bs."},
+	{Code: "BRL", Numeric: "986", Name: "Brazilian Real", MinorUnits: 2, Symbol: "R$"},
+	{Code: "BSD", Numeric: "044", Name: "Bahamian Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "BTN", Numeric: "064", Name: "Ngultrum", MinorUnits: 2, Symbol: "Nu."},
+	{Code: "BWP", Numeric: "072", Name: "Pula", MinorUnits: 2, Symbol: "P"},
+	{Code: "BYN", Numeric: "933", Name: "Belarusian Ruble", MinorUnits: 2, Symbol: "Br"},
+	{Code: "BZD", Numeric: "084", Name: "Belize Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "CAD", Numeric: "124", Name: "Canadian Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "CDF", Numeric: "976", Name: "Congolese Franc", MinorUnits: 2, Symbol: "FC"},
+	{Code: "CHF", Numeric: "756", Name: "Swiss Franc", MinorUnits: 2, Symbol: "CHF"},
+	{Code: "CLP", Numeric: "152", Name: "Chilean Peso", MinorUnits: 0, Symbol: "$"},
+	{Code: "CNY", Numeric: "156", Name: "Yuan Renminbi", MinorUnits: 2, Symbol: "¥"},
+	{Code: "COP", Numeric: "170", Name: "Colombian Peso", MinorUnits: 2, Symbol: "$"},
+	{Code: "CRC", Numeric: "188", Name: "Costa Rican Colon", MinorUnits: 2, Symbol: "₡"},
+	{Code: "CUC", Numeric: "931", Name: "Peso Convertible", MinorUnits: 2, Symbol: "$"},
+	{Code: "CUP", Numeric: "192", Name: "Cuban Peso", MinorUnits: 2, Symbol: "$"},
+	{Code: "CVE", Numeric: "132", Name: "Cabo Verde Escudo", MinorUnits: 2, Symbol: "$"},
+	{Code: "CZK", Numeric: "203", Name: "Czech Koruna", MinorUnits: 2, Symbol: "Kč"},
+	{Code: "DJF", Numeric: "262", Name: "Djibouti Franc", MinorUnits: 0, Symbol: "Fdj"},
+	{Code: "DKK", Numeric: "208", Name: "Danish Krone", MinorUnits: 2, Symbol: "kr"},
+	{Code: "DOP", Numeric: "214", Name: "Dominican Peso", MinorUnits: 2, Symbol: "$"},
+	{Code: "DZD", Numeric: "012", Name: "Algerian Dinar", MinorUnits: 2, Symbol: "د.ج"},
+	{Code: "EGP", Numeric: "818", Name: "Egyptian Pound", MinorUnits: 2, Symbol: "£"},
+	{Code: "ERN", Numeric: "232", Name: "Nakfa", MinorUnits: 2, Symbol: "Nfk"},
+	{Code: "ETB", Numeric: "230", Name: "Ethiopian Birr", MinorUnits: 2, Symbol: "Br"},
+	{Code: "EUR", Numeric: "978", Name: "Euro", MinorUnits: 2, Symbol: "€"},
+	{Code: "FJD", Numeric: "242", Name: "Fiji Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "FKP", Numeric: "238", Name: "Falkland Islands Pound", MinorUnits: 2, Symbol: "£"},
+	{Code: "GBP", Numeric: "826", Name: "Pound Sterling", MinorUnits: 2, Symbol: "£"},
+	{Code: "GEL", Numeric: "981", Name: "Lari", MinorUnits: 2, Symbol: "₾"},
+	{Code: "GHS", Numeric: "936", Name: "Ghana Cedi", MinorUnits: 2, Symbol: "₵"},
+	{Code: "GIP", Numeric: "292", Name: "Gibraltar Pound", MinorUnits: 2, Symbol: "£"},
+	{Code: "GMD", Numeric: "270", Name: "Dalasi", MinorUnits: 2, Symbol: "D"},
+	{Code: "GNF", Numeric: "324", Name: "Guinean Franc", MinorUnits: 0, Symbol: "FG"},
+	{Code: "GTQ", Numeric: "320", Name: "Quetzal", MinorUnits: 2, Symbol: "Q"},
+	{Code: "GYD", Numeric: "328", Name: "Guyana Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "HKD", Numeric: "344", Name: "Hong Kong Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "HNL", Numeric: "340", Name: "Lempira", MinorUnits: 2, Symbol: "L"},
+	{Code: "HTG", Numeric: "332", Name: "Gourde", MinorUnits: 2, Symbol: "G"},
+	{Code: "HUF", Numeric: "348", Name: "Forint", MinorUnits: 2, Symbol: "Ft"},
+	{Code: "IDR", Numeric: "360", Name: "Rupiah", MinorUnits: 2, Symbol: "Rp"},
+	{Code: "ILS", Numeric: "376", Name: "New Israeli Sheqel", MinorUnits: 2, Symbol: "₪"},
+	{Code: "INR", Numeric: "356", Name: "Indian Rupee", MinorUnits: 2, Symbol: "₹"},
+	{Code: "IQD", Numeric: "368", Name: "Iraqi Dinar", MinorUnits: 3, Symbol: "ع.د"},
+	{Code: "IRR", Numeric: "364", Name: "Iranian Rial", MinorUnits: 2, Symbol: "﷼"},
+	{Code: "ISK", Numeric: "352", Name: "Iceland Krona", MinorUnits: 0, Symbol: "kr"},
+	{Code: "JMD", Numeric: "388", Name: "Jamaican Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "JOD", Numeric: "400", Name: "Jordanian Dinar", MinorUnits: 3, Symbol: "د.ا"},
+	{Code: "JPY", Numeric: "392", Name: "Yen", MinorUnits: 0, Symbol: "¥"},
+	{Code: "KES", Numeric: "404", Name: "Kenyan Shilling", MinorUnits: 2, Symbol: "KSh"},
+	{Code: "KGS", Numeric: "417", Name: "Som", MinorUnits: 2, Symbol: "с"},
+	{Code: "KHR", Numeric: "116", Name: "Riel", MinorUnits: 2, Symbol: "៛"},
+	{Code: "KMF", Numeric: "174", Name: "Comorian Franc", MinorUnits: 0, Symbol: "CF"},
+	{Code: "KPW", Numeric: "408", Name: "North Korean Won", MinorUnits: 2, Symbol: "₩"},
+	{Code: "KRW", Numeric: "410", Name: "Won", MinorUnits: 0, Symbol: "₩"},
+	{Code: "KWD", Numeric: "414", Name: "Kuwaiti Dinar", MinorUnits: 3, Symbol: "د.ك"},
+	{Code: "KYD", Numeric: "136", Name: "Cayman Islands Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "KZT", Numeric: "398", Name: "Tenge", MinorUnits: 2, Symbol: "₸"},
+	{Code: "LAK", Numeric: "418", Name: "Lao Kip", MinorUnits: 2, Symbol: "₭"},
+	{Code: "LBP", Numeric: "422", Name: "Lebanese Pound", MinorUnits: 2, Symbol: "ل.ل"},
+	{Code: "LKR", Numeric: "144", Name: "Sri Lanka Rupee", MinorUnits: 2, Symbol: "Rs"},
+	{Code: "LRD", Numeric: "430", Name: "Liberian Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "LSL", Numeric: "426", Name: "Loti", MinorUnits: 2, Symbol: "L"},
+	{Code: "LYD", Numeric: "434", Name: "Libyan Dinar", MinorUnits: 3, Symbol: "ل.د"},
+	{Code: "MAD", Numeric: "504", Name: "Moroccan Dirham", MinorUnits: 2, Symbol: "د.م."},
+	{Code: "MDL", Numeric: "498", Name: "Moldovan Leu", MinorUnits: 2, Symbol: "L"},
+	{Code: "MGA", Numeric: "969", Name: "Malagasy Ariary", MinorUnits: 2, Symbol: "Ar"},
+	{Code: "MKD", Numeric: "807", Name: "Denar", MinorUnits: 2, Symbol: "ден"},
+	{Code: "MMK", Numeric: "104", Name: "Kyat", MinorUnits: 2, Symbol: "K"},
+	{Code: "MNT", Numeric: "496", Name: "Tugrik", MinorUnits: 2, Symbol: "₮"},
+	{Code: "MOP", Numeric: "446", Name: "Pataca", MinorUnits: 2, Symbol: "MOP$"},
+	{Code: "MRU", Numeric: "929", Name: "Ouguiya", MinorUnits: 2, Symbol: "UM"},
+	{Code: "MUR", Numeric: "480", Name: "Mauritius Rupee", MinorUnits: 2, Symbol: "₨"},
+	{Code: "MVR", Numeric: "462", Name: "Rufiyaa", MinorUnits: 2, Symbol: "Rf"},
+	{Code: "MWK", Numeric: "454", Name: "Malawi Kwacha", MinorUnits: 2, Symbol: "MK"},
+	{Code: "MXN", Numeric: "484", Name: "Mexican Peso", MinorUnits: 2, Symbol: "$"},
+	{Code: "MYR", Numeric: "458", Name: "Malaysian Ringgit", MinorUnits: 2, Symbol: "RM"},
+	{Code: "MZN", Numeric: "943", Name: "Mozambique Metical", MinorUnits: 2, Symbol: "MT"},
+	{Code: "NAD", Numeric: "516", Name: "Namibia Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "NGN", Numeric: "566", Name: "Naira", MinorUnits: 2, Symbol: "₦"},
+	{Code: "NIO", Numeric: "558", Name: "Cordoba Oro", MinorUnits: 2, Symbol: "C$"},
+	{Code: "NOK", Numeric: "578", Name: "Norwegian Krone", MinorUnits: 2, Symbol: "kr"},
+	{Code: "NPR", Numeric: "524", Name: "Nepalese Rupee", MinorUnits: 2, Symbol: "₨"},
+	{Code: "NZD", Numeric: "554", Name: "New Zealand Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "OMR", Numeric: "512", Name: "Rial Omani", MinorUnits: 3, Symbol: "ر.ع."},
+	{Code: "PAB", Numeric: "590", Name: "Balboa", MinorUnits: 2, Symbol: "B/."},
+	{Code: "PEN", Numeric: "604", Name: "Sol", MinorUnits: 2, Symbol: "S/"},
+	{Code: "PGK", Numeric: "598", Name: "Kina", MinorUnits: 2, Symbol: "K"},
+	{Code: "PHP", Numeric: "608", Name: "Philippine Peso", MinorUnits: 2, Symbol: "₱"},
+	{Code: "PKR", Numeric: "586", Name: "Pakistan Rupee", MinorUnits: 2, Symbol: "₨"},
+	{Code: "PLN", Numeric: "985", Name: "Zloty", MinorUnits: 2, Symbol: "zł"},
+	{Code: "PYG", Numeric: "600", Name: "Guarani", MinorUnits: 0, Symbol: "₲"},
+	{Code: "QAR", Numeric: "634", Name: "Qatari Rial", MinorUnits: 2, Symbol: "ر.ق"},
+	{Code: "RON", Numeric: "946", Name: "Romanian Leu", MinorUnits: 2, Symbol: "lei"},
+	{Code: "RSD", Numeric: "941", Name: "Serbian Dinar", MinorUnits: 2, Symbol: "дин."},
+	{Code: "RUB", Numeric: "643", Name: "Russian Ruble", MinorUnits: 2, Symbol: "₽"},
+	{Code: "RWF", Numeric: "646", Name: "Rwanda Franc", MinorUnits: 0, Symbol: "FRw"},
+	{Code: "SAR", Numeric: "682", Name: "Saudi Riyal", MinorUnits: 2, Symbol: "ر.س"},
+	{Code: "SBD", Numeric: "090", Name: "Solomon Islands Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "SCR", Numeric: "690", Name: "Seychelles Rupee", MinorUnits: 2, Symbol: "₨"},
+	{Code: "SDG", Numeric: "938", Name: "Sudanese Pound", MinorUnits: 2, Symbol: "ج.س."},
+	{Code: "SEK", Numeric: "752", Name: "Swedish Krona", MinorUnits: 2, Symbol: "kr"},
+	{Code: "SGD", Numeric: "702", Name: "Singapore Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "SHP", Numeric: "654", Name: "Saint Helena Pound", MinorUnits: 2, Symbol: "£"},
+	{Code: "SLE", Numeric: "925", Name: "Leone", MinorUnits: 2, Symbol: "Le"},
+	{Code: "SOS", Numeric: "706", Name: "Somali Shilling", MinorUnits: 2, Symbol: "Sh"},
+	{Code: "SRD", Numeric: "968", Name: "Surinam Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "SSP", Numeric: "728", Name: "South Sudanese Pound", MinorUnits: 2, Symbol: "£"},
+	{Code: "STN", Numeric: "930", Name: "Dobra", MinorUnits: 2, Symbol: "Db"},
+	{Code: "SVC", Numeric: "222", Name: "El Salvador Colon", MinorUnits: 2, Symbol: "₡"},
+	{Code: "SYP", Numeric: "760", Name: "Syrian Pound", MinorUnits: 2, Symbol: "£"},
+	{Code: "SZL", Numeric: "748", Name: "Lilangeni", MinorUnits: 2, Symbol: "E"},
+	{Code: "THB", Numeric: "764", Name: "Baht", MinorUnits: 2, Symbol: "฿"},
+	{Code: "TJS", Numeric: "972", Name: "Somoni", MinorUnits: 2, Symbol: "SM"},
+	{Code: "TMT", Numeric: "934", Name: "Turkmenistan New Manat", MinorUnits: 2, Symbol: "m"},
+	{Code: "TND", Numeric: "788", Name: "Tunisian Dinar", MinorUnits: 3, Symbol: "د.ت"},
+	{Code: "TOP", Numeric: "776", Name: "Pa’anga", MinorUnits: 2, Symbol: "T$"},
+	{Code: "TRY", Numeric: "949", Name: "Turkish Lira", MinorUnits: 2, Symbol: "₺"},
+	{Code: "TTD", Numeric: "780", Name: "Trinidad and Tobago Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "TWD", Numeric: "901", Name: "New Taiwan Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "TZS", Numeric: "834", Name: "Tanzanian Shilling", MinorUnits: 2, Symbol: "Sh"},
+	{Code: "UAH", Numeric: "980", Name: "Hryvnia", MinorUnits: 2, Symbol: "₴"},
+	{Code: "UGX", Numeric: "800", Name: "Uganda Shilling", MinorUnits: 0, Symbol: "USh"},
+	{Code: "USD", Numeric: "840", Name: "US Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "UYU", Numeric: "858", Name: "Peso Uruguayo", MinorUnits: 2, Symbol: "$"},
+	{Code: "UZS", Numeric: "860", Name: "Uzbekistan Sum", MinorUnits: 2, Symbol: "soʻm"},
+	{Code: "VED", Numeric: "926", Name: "Bolívar Soberano", MinorUnits: 2, Symbol: "Bs.D"},
+	{Code: "VES", Numeric: "928", Name: "Bolívar Soberano", MinorUnits: 2, Symbol: "Bs."},
+	{Code: "VND", Numeric: "704", Name: "Dong", MinorUnits: 0, Symbol: "₫"},
+	{Code: "VUV", Numeric: "548", Name: "Vatu", MinorUnits: 0, Symbol: "VT"},
+	{Code: "WST", Numeric: "882", Name: "Tala", MinorUnits: 2, Symbol: "T"},
+	{Code: "XAF", Numeric: "950", Name: "CFA Franc BEAC", MinorUnits: 0, Symbol: "FCFA"},
+	{Code: "XCD", Numeric: "951", Name: "East Caribbean Dollar", MinorUnits: 2, Symbol: "$"},
+	{Code: "XCG", Numeric: "532", Name: "Caribbean Guilder", MinorUnits: 2, Symbol: "Cg"},
+	{Code: "XOF", Numeric: "952", Name: "CFA Franc BCEAO", MinorUnits: 0, Symbol: "CFA"},
+	{Code: "XPF", Numeric: "953", Name: "CFP Franc", MinorUnits: 0, Symbol: "₣"},
+	{Code: "YER", Numeric: "886", Name: "Yemeni Rial", MinorUnits: 2, Symbol: "﷼"},
+	{Code: "ZAR", Numeric: "710", Name: "Rand", MinorUnits: 2, Symbol: "R"},
+	{Code: "ZMW", Numeric: "967", Name: "Zambian Kwacha", MinorUnits: 2, Symbol: "ZK"},
+	{Code: "ZWG", Numeric: "924", Name: "Zimbabwe Gold", MinorUnits: 2, Symbol: "ZiG"},
+}
+
+// withdrawn are currencies removed from ISO 4217, rates stored before they were replaced can still be read
+var withdrawn = []Currency{
+	{Code: "ANG", Numeric: "532", Name: "Netherlands Antillean Guilder", MinorUnits: 2, Symbol: "ƒ"},
+	{Code: "BGN", Numeric: "975", Name: "Bulgarian Lev", MinorUnits: 2, Symbol: "лв"},
+	{Code: "HRK", Numeric: "191", Name: "Kuna", MinorUnits: 2, Symbol: "kn"},
+	{Code: "SLL", Numeric: "694", Name: "Leone", MinorUnits: 2, Symbol: "Le"},
+	{Code: "ZWL", Numeric: "932", Name: "Zimbabwe Dollar", MinorUnits: 2, Symbol: "$"},
+}
@@ -0,0 +1,89 @@
+// Package iso4217 is a catalogue of ISO 4217 currencies and the currency pairs built from them.
+package iso4217
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var ErrUnknownCurrency = errors.New("unknown ISO 4217 currency")
+
+type Currency struct {
+	// Code is the alphabetic code, e.g. EUR
+	Code string
+	// Numeric is the three digit numeric code, e.g. 978
+	Numeric string
+	Name    string
+	// MinorUnits is the number of decimal places of the currency
+	MinorUnits int
+	Symbol     string
+}
+
+var (
+	byCode          = make(map[string]Currency, len(currencies))
+	byNumeric       = make(map[string]Currency, len(currencies))
+	withdrawnByCode = make(map[string]Currency, len(withdrawn))
+)
+
+func init() {
+	for _, c := range currencies {
+		byCode[c.Code] = c
+		byNumeric[c.Numeric] = c
+	}
+
+	for _, c := range withdrawn {
+		withdrawnByCode[c.Code] = c
+	}
+}
+
+// Lookup returns the currency with the alphabetic code, codes are case sensitive
+func Lookup(code string) (Currency, error) {
+	c, ok := byCode[code]
+
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+
+	return c, nil
+}
+
+// LookupNumeric returns the currency with the numeric code
+func LookupNumeric(numeric string) (Currency, error) {
+	c, ok := byNumeric[numeric]
+
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, numeric)
+	}
+
+	return c, nil
+}
+
+// LookupWithdrawn returns the currency removed from ISO 4217 with the alphabetic code, e.g. HRK.
+// Withdrawn currencies are not valid in new pairs, they are kept for rates stored earlier.
+func LookupWithdrawn(code string) (Currency, error) {
+	c, ok := withdrawnByCode[code]
+
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+
+	return c, nil
+}
+
+func IsValid(code string) bool {
+	_, ok := byCode[code]
+	return ok
+}
+
+// All returns every currency in the catalogue ordered by code
+func All() []Currency {
+	all := make([]Currency, len(currencies))
+	copy(all, currencies)
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Code < all[j].Code
+	})
+
+	return all
+}
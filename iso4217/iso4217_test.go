@@ -0,0 +1,78 @@
+package iso4217_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency/iso4217"
+)
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+
+	rsd, err := iso4217.Lookup("RSD")
+	asserts.Nil(err)
+	asserts.Equal(iso4217.Currency{Code: "RSD", Numeric: "941", Name: "Serbian Dinar", MinorUnits: 2, Symbol: "дин."}, rsd)
+
+	jpy, err := iso4217.LookupNumeric("392")
+	asserts.Nil(err)
+	asserts.Equal("JPY", jpy.Code)
+	asserts.Equal(0, jpy.MinorUnits)
+
+	_, err = iso4217.Lookup("eur")
+	asserts.True(errors.Is(err, iso4217.ErrUnknownCurrency))
+
+	asserts.True(iso4217.IsValid("EUR"))
+	asserts.False(iso4217.IsValid("XYZ"))
+}
+
+func TestLookupWithdrawn(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+
+	for _, code := range []string{"SLE", "VED", "ZWG", "XCG"} {
+		asserts.True(iso4217.IsValid(code), code)
+	}
+
+	hrk, err := iso4217.LookupWithdrawn("HRK")
+	asserts.Nil(err)
+	asserts.Equal("Kuna", hrk.Name)
+	asserts.False(iso4217.IsValid("HRK"))
+
+	_, err = iso4217.LookupWithdrawn("EUR")
+	asserts.True(errors.Is(err, iso4217.ErrUnknownCurrency))
+
+	_, err = iso4217.ParsePair("EUR_HRK")
+	asserts.True(errors.Is(err, iso4217.ErrInvalidPair))
+
+	pair, err := iso4217.SplitPair("EUR_HRK")
+	asserts.Nil(err)
+	asserts.Equal("HRK", pair.To)
+}
+
+func TestAll(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+
+	all := iso4217.All()
+	codes := make(map[string]bool, len(all))
+	numeric := make(map[string]bool, len(all))
+
+	for i, c := range all {
+		asserts.Len(c.Code, 3)
+		asserts.Len(c.Numeric, 3)
+		asserts.NotEmpty(c.Name)
+		asserts.False(codes[c.Code], c.Code)
+		asserts.False(numeric[c.Numeric], c.Numeric)
+
+		if i > 0 {
+			asserts.Less(all[i-1].Code, c.Code)
+		}
+
+		codes[c.Code] = true
+		numeric[c.Numeric] = true
+	}
+}
@@ -0,0 +1,94 @@
+package iso4217
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PairSeparator separates currency codes of a pair, e.g. EUR_RSD
+const PairSeparator = "_"
+
+var ErrInvalidPair = errors.New("invalid currency pair")
+
+// Pair is a currency pair, rates of a pair are the amount of To for one unit of From
+type Pair struct {
+	From string
+	To   string
+}
+
+// SplitPair splits FROM_TO into a Pair without checking the codes against the catalogue,
+// used for pairs already stored or returned by providers, which may hold withdrawn currencies
+func SplitPair(value string) (Pair, error) {
+	codes := strings.Split(value, PairSeparator)
+
+	if len(codes) != 2 || codes[0] == "" || codes[1] == "" {
+		return Pair{}, fmt.Errorf("%w %q: expected FROM%sTO, e.g. EUR%sRSD", ErrInvalidPair, value, PairSeparator, PairSeparator)
+	}
+
+	return Pair{From: codes[0], To: codes[1]}, nil
+}
+
+// ParsePair parses FROM_TO, codes are upper cased and both have to be in the catalogue
+func ParsePair(value string) (Pair, error) {
+	p, err := SplitPair(strings.ToUpper(strings.TrimSpace(value)))
+
+	if err != nil {
+		return Pair{}, err
+	}
+
+	if err := p.Validate(); err != nil {
+		return Pair{}, fmt.Errorf("%w %q: %v", ErrInvalidPair, value, err)
+	}
+
+	return p, nil
+}
+
+// ParsePairs parses every value, the first invalid pair is returned as an error
+func ParsePairs(values []string) ([]Pair, error) {
+	pairs := make([]Pair, 0, len(values))
+
+	for _, value := range values {
+		p, err := ParsePair(value)
+
+		if err != nil {
+			return nil, err
+		}
+
+		pairs = append(pairs, p)
+	}
+
+	return pairs, nil
+}
+
+// Validate checks both codes against the catalogue
+func (p Pair) Validate() error {
+	if _, err := Lookup(p.From); err != nil {
+		return err
+	}
+
+	if _, err := Lookup(p.To); err != nil {
+		return err
+	}
+
+	if p.From == p.To {
+		return fmt.Errorf("%w: %s is converted to itself", ErrInvalidPair, p.From)
+	}
+
+	return nil
+}
+
+func (p Pair) String() string {
+	return p.From + PairSeparator + p.To
+}
+
+// Strings formats pairs as FROM_TO
+func Strings(pairs []Pair) []string {
+	values := make([]string, 0, len(pairs))
+
+	for _, p := range pairs {
+		values = append(values, p.String())
+	}
+
+	return values
+}
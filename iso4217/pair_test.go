@@ -0,0 +1,68 @@
+package iso4217_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency/iso4217"
+)
+
+func TestParsePair(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value    string
+		expected iso4217.Pair
+		err      error
+	}{
+		{"EUR_RSD", iso4217.Pair{From: "EUR", To: "RSD"}, nil},
+		{" eur_usd ", iso4217.Pair{From: "EUR", To: "USD"}, nil},
+		{"EUR-RSD", iso4217.Pair{}, iso4217.ErrInvalidPair},
+		{"EUR_RSD_USD", iso4217.Pair{}, iso4217.ErrInvalidPair},
+		{"EUR_", iso4217.Pair{}, iso4217.ErrInvalidPair},
+		{"EUR_XYZ", iso4217.Pair{}, iso4217.ErrInvalidPair},
+		{"EUR_EUR", iso4217.Pair{}, iso4217.ErrInvalidPair},
+	}
+
+	for _, test := range tests {
+		asserts := require.New(t)
+		pair, err := iso4217.ParsePair(test.value)
+
+		if test.err != nil {
+			asserts.True(errors.Is(err, test.err), "%s: %v", test.value, err)
+			continue
+		}
+
+		asserts.Nil(err)
+		asserts.Equal(test.expected, pair)
+	}
+}
+
+func TestParsePairs(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+
+	pairs, err := iso4217.ParsePairs([]string{"EUR_RSD", "rsd_eur"})
+	asserts.Nil(err)
+	asserts.Equal([]string{"EUR_RSD", "RSD_EUR"}, iso4217.Strings(pairs))
+
+	pairs, err = iso4217.ParsePairs([]string{"EUR_RSD", "EUR-RSD"})
+	asserts.Nil(pairs)
+	asserts.True(errors.Is(err, iso4217.ErrInvalidPair))
+	asserts.Contains(err.Error(), `"EUR-RSD"`)
+}
+
+func TestSplitPair(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+
+	pair, err := iso4217.SplitPair("BTC_USD")
+	asserts.Nil(err)
+	asserts.Equal(iso4217.Pair{From: "BTC", To: "USD"}, pair)
+	asserts.Equal("BTC_USD", pair.String())
+
+	_, err = iso4217.SplitPair("EURRSD")
+	asserts.True(errors.Is(err, iso4217.ErrInvalidPair))
+}
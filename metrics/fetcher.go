@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/malusev998/currency"
+)
+
+type (
+	fetcher struct {
+		currency.Fetcher
+		metrics  *Metrics
+		provider string
+	}
+
+	historicalFetcher struct {
+		fetcher
+		historical currency.HistoricalFetcher
+	}
+)
+
+// Fetcher instruments every fetch made by f, provider is used as the label
+func (m *Metrics) Fetcher(provider currency.Provider, f currency.Fetcher) currency.Fetcher {
+	instrumented := fetcher{Fetcher: f, metrics: m, provider: string(provider)}
+
+	if historical, ok := f.(currency.HistoricalFetcher); ok {
+		return historicalFetcher{fetcher: instrumented, historical: historical}
+	}
+
+	return instrumented
+}
+
+func (f fetcher) observe(start time.Time, currencies []currency.Currency, err error) {
+	f.metrics.fetches.WithLabelValues(f.provider).Inc()
+	f.metrics.fetchDuration.WithLabelValues(f.provider).Observe(since(start))
+
+	if err != nil {
+		f.metrics.fetchErrors.WithLabelValues(f.provider, ErrorReason(err)).Inc()
+		return
+	}
+
+	f.metrics.ratesFetched.WithLabelValues(f.provider).Add(float64(len(currencies)))
+}
+
+func (f fetcher) Fetch(currenciesToFetch []string) ([]currency.Currency, error) {
+	start := time.Now()
+	currencies, err := f.Fetcher.Fetch(currenciesToFetch)
+	f.observe(start, currencies, err)
+
+	return currencies, err
+}
+
+func (f historicalFetcher) FetchByDate(currenciesToFetch []string, date time.Time) ([]currency.Currency, error) {
+	start := time.Now()
+	currencies, err := f.historical.FetchByDate(currenciesToFetch, date)
+	f.observe(start, currencies, err)
+
+	return currencies, err
+}
+
+func (f historicalFetcher) FetchByDateRange(currenciesToFetch []string, start, end time.Time) ([]currency.Currency, error) {
+	now := time.Now()
+	currencies, err := f.historical.FetchByDateRange(currenciesToFetch, start, end)
+	f.observe(now, currencies, err)
+
+	return currencies, err
+}
@@ -0,0 +1,190 @@
+// Package metrics instruments services, fetchers and storages with Prometheus collectors.
+//
+// Instrumented values are drop-in replacements, services and fetchers
+// keep implementing the historical interfaces when the wrapped value does.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/fetchers"
+	"github.com/malusev998/currency/services"
+)
+
+const namespace = "currency_fetcher"
+
+type Metrics struct {
+	saves          *prometheus.CounterVec
+	saveErrors     *prometheus.CounterVec
+	saveDuration   *prometheus.HistogramVec
+	fetches        *prometheus.CounterVec
+	fetchErrors    *prometheus.CounterVec
+	fetchDuration  *prometheus.HistogramVec
+	ratesFetched   *prometheus.CounterVec
+	storageOps     *prometheus.CounterVec
+	storageErrors  *prometheus.CounterVec
+	storageLatency *prometheus.HistogramVec
+	ratesStored    *prometheus.CounterVec
+	ratesRejected  *prometheus.CounterVec
+	spread         *prometheus.GaugeVec
+	sources        *prometheus.GaugeVec
+}
+
+// New creates the collectors and registers them on registerer
+func New(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		saves: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "saves_total",
+			Help:      "Number of fetch and store runs by provider.",
+		}, []string{"provider"}),
+		saveErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "save_errors_total",
+			Help:      "Number of failed fetch and store runs by provider and reason.",
+		}, []string{"provider", "reason"}),
+		saveDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "save_duration_seconds",
+			Help:      "Duration of fetch and store runs by provider.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"provider"}),
+		fetches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "fetches_total",
+			Help:      "Number of fetches by provider.",
+		}, []string{"provider"}),
+		fetchErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "fetch_errors_total",
+			Help:      "Number of failed fetches by provider and reason.",
+		}, []string{"provider", "reason"}),
+		fetchDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "fetch_duration_seconds",
+			Help:      "Duration of fetches by provider.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"provider"}),
+		ratesFetched: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rates_fetched_total",
+			Help:      "Number of rates returned by provider.",
+		}, []string{"provider"}),
+		storageOps: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "storage_operations_total",
+			Help:      "Number of storage operations by storage and operation.",
+		}, []string{"storage", "operation"}),
+		storageErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "storage_errors_total",
+			Help:      "Number of failed storage operations by storage, operation and reason.",
+		}, []string{"storage", "operation", "reason"}),
+		storageLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "storage_duration_seconds",
+			Help:      "Duration of storage operations by storage and operation.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"storage", "operation"}),
+		ratesStored: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rates_stored_total",
+			Help:      "Number of rates stored by storage.",
+		}, []string{"storage"}),
+		ratesRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rates_rejected_total",
+			Help:      "Number of fetched rates rejected by validation by provider and reason.",
+		}, []string{"provider", "reason"}),
+		spread: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "consensus_spread",
+			Help:      "Difference between the highest and the lowest provider rate of the last consensus rate by pair.",
+		}, []string{"pair"}),
+		sources: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "consensus_sources",
+			Help:      "Number of providers the last consensus rate was computed from by pair.",
+		}, []string{"pair"}),
+	}
+
+	collectors := []prometheus.Collector{
+		m.saves, m.saveErrors, m.saveDuration,
+		m.fetches, m.fetchErrors, m.fetchDuration, m.ratesFetched,
+		m.storageOps, m.storageErrors, m.storageLatency, m.ratesStored,
+		m.ratesRejected, m.spread, m.sources,
+	}
+
+	for _, collector := range collectors {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// ErrorReason returns the label used for err, sentinel errors get their own reason
+func ErrorReason(err error) string {
+	switch {
+	case errors.Is(err, fetchers.ErrAPILimitReached):
+		return "api_limit_reached"
+	case errors.Is(err, fetchers.ErrTooManyRequests):
+		return "too_many_requests"
+	case errors.Is(err, currency.ErrQuotaExceeded):
+		return "quota_exceeded"
+	case errors.Is(err, fetchers.ErrNotEnoughRequests):
+		return "not_enough_requests"
+	case errors.Is(err, fetchers.ErrUnAuthorized):
+		return "unauthorized"
+	case errors.Is(err, fetchers.ErrClient):
+		return "client"
+	case errors.Is(err, fetchers.ErrServer):
+		return "server"
+	case errors.Is(err, fetchers.ErrUnknown):
+		return "unknown"
+	case errors.Is(err, fetchers.ErrInvalidDateRange):
+		return "invalid_date_range"
+	case errors.Is(err, services.ErrInvalidRate):
+		return "invalid_rate"
+	case errors.Is(err, services.ErrUnknownCurrency):
+		return "unknown_currency"
+	case errors.Is(err, services.ErrRateChange):
+		return "rate_change"
+	case errors.Is(err, services.ErrHistoricalNotSupported):
+		return "historical_not_supported"
+	case errors.Is(err, currency.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.As(err, new(*services.SaveError)):
+		return "save_policy"
+	default:
+		return "other"
+	}
+}
+
+// RejectedRate counts r, it can be used as services.Validator OnReject
+func (m *Metrics) RejectedRate(r services.RejectedRate) {
+	m.ratesRejected.WithLabelValues(string(r.Provider), ErrorReason(r.Err)).Inc()
+}
+
+// ConsensusRate records the spread and sources of r, it can be used as fetchers.ConsensusConfig OnRate
+func (m *Metrics) ConsensusRate(r fetchers.ConsensusRate) {
+	pair := r.From + "_" + r.To
+	spread, _ := r.Spread.Float64()
+
+	m.spread.WithLabelValues(pair).Set(spread)
+	m.sources.WithLabelValues(pair).Set(float64(len(r.Sources)))
+}
+
+func since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
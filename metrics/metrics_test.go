@@ -0,0 +1,278 @@
+package metrics_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/fetchers"
+	"github.com/malusev998/currency/metrics"
+	"github.com/malusev998/currency/services"
+	"github.com/malusev998/currency/storage"
+)
+
+type (
+	stubFetcher struct {
+		currencies []currency.Currency
+		err        error
+	}
+
+	stubHistoricalFetcher struct {
+		stubFetcher
+	}
+)
+
+func (f stubFetcher) Fetch([]string) ([]currency.Currency, error) {
+	return f.currencies, f.err
+}
+
+func (f stubHistoricalFetcher) FetchByDate([]string, time.Time) ([]currency.Currency, error) {
+	return f.currencies, f.err
+}
+
+func (f stubHistoricalFetcher) FetchByDateRange([]string, time.Time, time.Time) ([]currency.Currency, error) {
+	return f.currencies, f.err
+}
+
+func newMetrics(t *testing.T) (*metrics.Metrics, *prometheus.Registry) {
+	registry := prometheus.NewRegistry()
+	m, err := metrics.New(registry)
+	require.Nil(t, err)
+
+	return m, registry
+}
+
+func newStorage(t *testing.T) currency.Storage {
+	st, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+	require.Nil(t, err)
+
+	return st
+}
+
+func TestNew_RegistersOnce(t *testing.T) {
+	asserts := require.New(t)
+	registry := prometheus.NewRegistry()
+
+	_, err := metrics.New(registry)
+	asserts.Nil(err)
+
+	_, err = metrics.New(registry)
+	asserts.Error(err)
+}
+
+func TestErrorReason(t *testing.T) {
+	asserts := require.New(t)
+
+	asserts.Equal("api_limit_reached", metrics.ErrorReason(fetchers.ErrAPILimitReached))
+	asserts.Equal("client", metrics.ErrorReason(fmt.Errorf("%w: invalid pair", fetchers.ErrClient)))
+	asserts.Equal("not_found", metrics.ErrorReason(currency.ErrNotFound))
+	asserts.Equal("other", metrics.ErrorReason(errors.New("connection refused")))
+	asserts.Equal("save_policy", metrics.ErrorReason(&services.SaveError{
+		Policy:   services.SaveAll,
+		Storages: 2,
+		Failed:   []currency.StorageResult{{Storage: "mysql", Err: errors.New("connection refused")}},
+	}))
+}
+
+func TestMetrics_ServiceSaveWithResult(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	m, _ := newMetrics(t)
+
+	service := m.Service(currency.ECBProvider, services.Service{
+		Fetcher: stubFetcher{currencies: []currency.Currency{
+			{From: "EUR", To: "USD", Provider: currency.ECBProvider, Rate: decimal.RequireFromString("1.17"), CreatedAt: time.Now()},
+		}},
+		Storage: []currency.Storage{newStorage(t)},
+	})
+
+	_, ok := service.(currency.ResultService)
+	asserts.True(ok)
+
+	result, err := currency.SaveWithResult(service, []string{"EUR_USD"})
+	asserts.Nil(err)
+	asserts.Len(result.Storages, 1)
+	asserts.Equal("memory", result.Storages[0].Storage)
+	asserts.Len(result.Storages[0].Currencies, 1)
+}
+
+func TestMetrics_Service(t *testing.T) {
+	t.Parallel()
+
+	rates := []currency.Currency{
+		{From: "EUR", To: "USD", Provider: currency.ECBProvider, Rate: decimal.RequireFromString("1.17"), CreatedAt: time.Now()},
+		{From: "EUR", To: "RSD", Provider: currency.ECBProvider, Rate: decimal.RequireFromString("117.58"), CreatedAt: time.Now()},
+	}
+
+	t.Run("Counts fetches and stored rates", func(t *testing.T) {
+		asserts := require.New(t)
+		m, registry := newMetrics(t)
+		st := m.Storage(newStorage(t))
+
+		service := m.Service(currency.ECBProvider, services.Service{
+			Fetcher: m.Fetcher(currency.ECBProvider, stubFetcher{currencies: rates}),
+			Storage: []currency.Storage{st},
+		})
+
+		_, err := service.Save([]string{"EUR_USD", "EUR_RSD"})
+		asserts.Nil(err)
+
+		// Missing rates are not storage errors
+		_, err = currency.LatestRate(st, "EUR", "JPY", currency.EmptyProvider)
+		asserts.True(errors.Is(err, currency.ErrNotFound))
+
+		expected := `
+# HELP currency_fetcher_fetches_total Number of fetches by provider.
+# TYPE currency_fetcher_fetches_total counter
+currency_fetcher_fetches_total{provider="ECB"} 1
+# HELP currency_fetcher_rates_fetched_total Number of rates returned by provider.
+# TYPE currency_fetcher_rates_fetched_total counter
+currency_fetcher_rates_fetched_total{provider="ECB"} 2
+# HELP currency_fetcher_rates_stored_total Number of rates stored by storage.
+# TYPE currency_fetcher_rates_stored_total counter
+currency_fetcher_rates_stored_total{storage="memory"} 2
+# HELP currency_fetcher_saves_total Number of fetch and store runs by provider.
+# TYPE currency_fetcher_saves_total counter
+currency_fetcher_saves_total{provider="ECB"} 1
+`
+		asserts.Nil(testutil.GatherAndCompare(
+			registry,
+			strings.NewReader(expected),
+			"currency_fetcher_fetches_total",
+			"currency_fetcher_rates_fetched_total",
+			"currency_fetcher_rates_stored_total",
+			"currency_fetcher_saves_total",
+			"currency_fetcher_storage_errors_total",
+		))
+	})
+
+	t.Run("Counts copied rates", func(t *testing.T) {
+		asserts := require.New(t)
+		m, registry := newMetrics(t)
+		source := newStorage(t)
+		st := m.Storage(newStorage(t))
+
+		stored, err := source.Store(rates)
+		asserts.Nil(err)
+
+		copied, err := currency.CopyRates(st, stored)
+		asserts.Nil(err)
+		asserts.Equal(stored[0].ID, copied[0].ID)
+
+		asserts.Nil(testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP currency_fetcher_rates_stored_total Number of rates stored by storage.
+# TYPE currency_fetcher_rates_stored_total counter
+currency_fetcher_rates_stored_total{storage="memory"} 2
+`), "currency_fetcher_rates_stored_total"))
+	})
+
+	t.Run("Counts errors by reason", func(t *testing.T) {
+		asserts := require.New(t)
+		m, registry := newMetrics(t)
+
+		service := m.Service(currency.FreeConvProvider, services.Service{
+			Fetcher: m.Fetcher(currency.FreeConvProvider, stubFetcher{err: fetchers.ErrAPILimitReached}),
+			Storage: []currency.Storage{m.Storage(newStorage(t))},
+		})
+
+		_, err := service.Save([]string{"EUR_USD"})
+		asserts.True(errors.Is(err, fetchers.ErrAPILimitReached))
+
+		count, err := testutil.GatherAndCount(registry, "currency_fetcher_fetch_errors_total", "currency_fetcher_save_errors_total")
+		asserts.Nil(err)
+		asserts.Equal(2, count)
+
+		asserts.Nil(testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP currency_fetcher_fetch_errors_total Number of failed fetches by provider and reason.
+# TYPE currency_fetcher_fetch_errors_total counter
+currency_fetcher_fetch_errors_total{provider="FreeCurrConversion",reason="api_limit_reached"} 1
+`), "currency_fetcher_fetch_errors_total"))
+	})
+
+	t.Run("Keeps historical support", func(t *testing.T) {
+		asserts := require.New(t)
+		m, _ := newMetrics(t)
+
+		plain := m.Service(currency.ECBProvider, services.Service{
+			Fetcher: m.Fetcher(currency.ECBProvider, stubFetcher{currencies: rates}),
+		})
+		_, ok := plain.(currency.HistoricalService)
+		asserts.True(ok, "services.Service implements HistoricalService")
+
+		_, ok = m.Fetcher(currency.ECBProvider, stubFetcher{}).(currency.HistoricalFetcher)
+		asserts.False(ok)
+
+		historical := m.Service(currency.ECBProvider, services.Service{
+			Fetcher: m.Fetcher(currency.ECBProvider, stubHistoricalFetcher{stubFetcher{currencies: rates}}),
+			Storage: []currency.Storage{m.Storage(newStorage(t))},
+		}).(currency.HistoricalService)
+
+		saved, err := historical.SaveByDateRange([]string{"EUR_USD"}, time.Now().Add(-24*time.Hour), time.Now())
+		asserts.Nil(err)
+		asserts.Len(saved["memory"], 2)
+	})
+}
+
+func TestMetrics_RejectedRate(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	m, registry := newMetrics(t)
+
+	service := services.Service{
+		Fetcher: stubFetcher{currencies: []currency.Currency{
+			{From: "EUR", To: "RSD", Provider: currency.ECBProvider, Rate: decimal.Zero, CreatedAt: time.Now()},
+			{From: "EUR", To: "USD", Provider: currency.ECBProvider, Rate: decimal.RequireFromString("1.17"), CreatedAt: time.Now()},
+		}},
+		Storage:   []currency.Storage{newStorage(t)},
+		Validator: &services.Validator{OnReject: m.RejectedRate},
+	}
+
+	saved, err := service.Save([]string{"EUR_RSD", "EUR_USD"})
+	asserts.Nil(err)
+	asserts.Len(saved["memory"], 1)
+
+	asserts.Nil(testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP currency_fetcher_rates_rejected_total Number of fetched rates rejected by validation by provider and reason.
+# TYPE currency_fetcher_rates_rejected_total counter
+currency_fetcher_rates_rejected_total{provider="ECB",reason="invalid_rate"} 1
+`), "currency_fetcher_rates_rejected_total"))
+}
+
+func TestMetrics_ConsensusRate(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	m, registry := newMetrics(t)
+
+	fetcher := fetchers.NewConsensusFetcher(fetchers.ConsensusConfig{
+		Fetchers: []fetchers.ProviderFetcher{
+			{Provider: currency.ECBProvider, Fetcher: stubFetcher{currencies: []currency.Currency{
+				{From: "EUR", To: "USD", Provider: currency.ECBProvider, Rate: decimal.RequireFromString("1.17")},
+			}}},
+			{Provider: currency.NBSProvider, Fetcher: stubFetcher{currencies: []currency.Currency{
+				{From: "EUR", To: "USD", Provider: currency.NBSProvider, Rate: decimal.RequireFromString("1.19")},
+			}}},
+		},
+		OnRate: m.ConsensusRate,
+	})
+
+	rates, err := fetcher.Fetch([]string{"EUR_USD"})
+	asserts.Nil(err)
+	asserts.Len(rates, 1)
+
+	asserts.Nil(testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP currency_fetcher_consensus_sources Number of providers the last consensus rate was computed from by pair.
+# TYPE currency_fetcher_consensus_sources gauge
+currency_fetcher_consensus_sources{pair="EUR_USD"} 2
+# HELP currency_fetcher_consensus_spread Difference between the highest and the lowest provider rate of the last consensus rate by pair.
+# TYPE currency_fetcher_consensus_spread gauge
+currency_fetcher_consensus_spread{pair="EUR_USD"} 0.02
+`), "currency_fetcher_consensus_sources", "currency_fetcher_consensus_spread"))
+}
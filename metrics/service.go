@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/malusev998/currency"
+)
+
+type (
+	service struct {
+		currency.Service
+		metrics  *Metrics
+		provider string
+	}
+
+	historicalService struct {
+		service
+		historical currency.HistoricalService
+	}
+)
+
+// Service instruments Save, SaveWithResult and SaveByDateRange of s, provider is used as the label
+func (m *Metrics) Service(provider currency.Provider, s currency.Service) currency.Service {
+	instrumented := service{Service: s, metrics: m, provider: string(provider)}
+
+	if historical, ok := s.(currency.HistoricalService); ok {
+		return historicalService{service: instrumented, historical: historical}
+	}
+
+	return instrumented
+}
+
+func (s service) observe(start time.Time, err error) {
+	s.metrics.saves.WithLabelValues(s.provider).Inc()
+	s.metrics.saveDuration.WithLabelValues(s.provider).Observe(since(start))
+
+	if err != nil {
+		s.metrics.saveErrors.WithLabelValues(s.provider, ErrorReason(err)).Inc()
+	}
+}
+
+func (s service) Save(currenciesToFetch []string) (map[string][]currency.CurrencyWithID, error) {
+	start := time.Now()
+	saved, err := s.Service.Save(currenciesToFetch)
+	s.observe(start, err)
+
+	return saved, err
+}
+
+// SaveWithResult passes per storage results of the wrapped service through
+func (s service) SaveWithResult(currenciesToFetch []string) (currency.SaveResult, error) {
+	start := time.Now()
+	result, err := currency.SaveWithResult(s.Service, currenciesToFetch)
+	s.observe(start, err)
+
+	return result, err
+}
+
+func (s historicalService) SaveByDateRange(currenciesToFetch []string, start, end time.Time) (map[string][]currency.CurrencyWithID, error) {
+	now := time.Now()
+	saved, err := s.historical.SaveByDateRange(currenciesToFetch, start, end)
+	s.observe(now, err)
+
+	return saved, err
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/malusev998/currency"
+)
+
+type storage struct {
+	currency.Storage
+	metrics *Metrics
+	name    string
+}
+
+// Storage instruments reads and writes of s, labeled with its provider name.
+// Close, Migrate and Drop are passed through, rates copied with currency.CopyRates are counted as stored,
+// other optional storage interfaces are reached with Unwrap.
+func (m *Metrics) Storage(s currency.Storage) currency.Storage {
+	return storage{Storage: s, metrics: m, name: s.GetStorageProviderName()}
+}
+
+func (s storage) observe(operation string, start time.Time, err error) {
+	s.metrics.storageOps.WithLabelValues(s.name, operation).Inc()
+	s.metrics.storageLatency.WithLabelValues(s.name, operation).Observe(since(start))
+
+	// Missing rates are expected, e.g. conversions of pairs that were never fetched
+	if err != nil && !errors.Is(err, currency.ErrNotFound) {
+		s.metrics.storageErrors.WithLabelValues(s.name, operation, ErrorReason(err)).Inc()
+	}
+}
+
+func (s storage) Store(currencies []currency.Currency) ([]currency.CurrencyWithID, error) {
+	start := time.Now()
+	stored, err := s.Storage.Store(currencies)
+	s.observe("store", start, err)
+
+	if err == nil {
+		s.metrics.ratesStored.WithLabelValues(s.name).Add(float64(len(stored)))
+	}
+
+	return stored, err
+}
+
+// StoreWithID keeps IDs through currency.CopyRates, so rates copied by import and reconcile are counted
+func (s storage) StoreWithID(rates []currency.CurrencyWithID) ([]currency.CurrencyWithID, error) {
+	start := time.Now()
+	stored, err := currency.CopyRates(s.Storage, rates)
+	s.observe("store_with_id", start, err)
+
+	if err == nil {
+		s.metrics.ratesStored.WithLabelValues(s.name).Add(float64(len(stored)))
+	}
+
+	return stored, err
+}
+
+func (s storage) Get(from, to string, page, perPage int64) ([]currency.CurrencyWithID, error) {
+	start := time.Now()
+	rates, err := s.Storage.Get(from, to, page, perPage)
+	s.observe("get", start, err)
+
+	return rates, err
+}
+
+func (s storage) GetByProvider(from, to string, provider currency.Provider, page, perPage int64) ([]currency.CurrencyWithID, error) {
+	start := time.Now()
+	rates, err := s.Storage.GetByProvider(from, to, provider, page, perPage)
+	s.observe("get_by_provider", start, err)
+
+	return rates, err
+}
+
+func (s storage) GetByDate(from, to string, startDate, endDate time.Time, page, perPage int64) ([]currency.CurrencyWithID, error) {
+	start := time.Now()
+	rates, err := s.Storage.GetByDate(from, to, startDate, endDate, page, perPage)
+	s.observe("get_by_date", start, err)
+
+	return rates, err
+}
+
+func (s storage) GetByDateAndProvider(from, to string, provider currency.Provider, startDate, endDate time.Time, page, perPage int64) ([]currency.CurrencyWithID, error) {
+	start := time.Now()
+	rates, err := s.Storage.GetByDateAndProvider(from, to, provider, startDate, endDate, page, perPage)
+	s.observe("get_by_date_and_provider", start, err)
+
+	return rates, err
+}
+
+func (s storage) Latest(from, to string, provider currency.Provider) (currency.CurrencyWithID, error) {
+	start := time.Now()
+	rate, err := currency.LatestRate(s.Storage, from, to, provider)
+	s.observe("latest", start, err)
+
+	return rate, err
+}
+
+func (s storage) LatestBefore(from, to string, provider currency.Provider, date time.Time) (currency.CurrencyWithID, error) {
+	start := time.Now()
+	rate, err := currency.LatestRateBefore(s.Storage, from, to, provider, date)
+	s.observe("latest_before", start, err)
+
+	return rate, err
+}
+
+func (s storage) Unwrap() currency.Storage {
+	return s.Storage
+}
@@ -1,14 +1,19 @@
 package currency
 
-import "time"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 type (
 	Currency struct {
-		Rate      float32   `json:"rate,omitempty"`
-		CreatedAt time.Time `json:"created_at,omitempty"`
-		Provider  Provider  `json:"provider,omitempty"`
-		From      string    `json:"from,omitempty"`
-		To        string    `json:"to,omitempty"`
+		// Rate is encoded to JSON as a string, so no precision is lost
+		Rate      decimal.Decimal `json:"rate"`
+		CreatedAt time.Time       `json:"created_at,omitempty"`
+		Provider  Provider        `json:"provider,omitempty"`
+		From      string          `json:"from,omitempty"`
+		To        string          `json:"to,omitempty"`
 	}
 
 	CurrencyWithID struct {
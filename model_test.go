@@ -0,0 +1,37 @@
+package currency_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/malusev998/currency"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrency_JSON(t *testing.T) {
+	assert := require.New(t)
+
+	c := currency.Currency{
+		Rate:      decimal.RequireFromString("117.58123456"),
+		CreatedAt: time.Date(2020, 10, 16, 12, 0, 0, 0, time.UTC),
+		Provider:  currency.NBSProvider,
+		From:      "EUR",
+		To:        "RSD",
+	}
+
+	data, err := json.Marshal(c)
+
+	assert.Nil(err)
+	assert.JSONEq(`{"rate":"117.58123456","created_at":"2020-10-16T12:00:00Z","provider":"NBS","from":"EUR","to":"RSD"}`, string(data))
+
+	var decoded currency.Currency
+
+	assert.Nil(json.Unmarshal(data, &decoded))
+	assert.True(c.Rate.Equal(decoded.Rate))
+
+	// Rates encoded as numbers by older versions are still accepted
+	assert.Nil(json.Unmarshal([]byte(`{"rate":1.1708}`), &decoded))
+	assert.Equal("1.1708", decoded.Rate.String())
+}
@@ -10,6 +10,9 @@ type Provider string
 const (
 	FreeConvProvider         Provider = "FreeCurrConversion"
 	ExchangeRatesAPIProvider Provider = "ExchangeRatesAPI"
+	ECBProvider              Provider = "ECB"
+	NBSProvider              Provider = "NBS"
+	ConsensusProvider        Provider = "Consensus" // rates aggregated from several providers
 	EmptyProvider            Provider = ""
 )
 
@@ -34,6 +37,12 @@ func ConvertToProviderFromString(str string) (Provider, error) {
 		return FreeConvProvider, nil
 	case "exchangeratesapi":
 		return ExchangeRatesAPIProvider, nil
+	case "ecb":
+		return ECBProvider, nil
+	case "nbs":
+		return NBSProvider, nil
+	case "consensus":
+		return ConsensusProvider, nil
 	}
 
 	return "", fmt.Errorf("value %s is not valid Provider", str)
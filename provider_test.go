@@ -35,6 +35,9 @@ func TestConvertToProviderFromString(t *testing.T) {
 	}{
 		{"freecurrconversion", currency.FreeConvProvider, nil},
 		{"exchangeratesapi", currency.ExchangeRatesAPIProvider, nil},
+		{"ecb", currency.ECBProvider, nil},
+		{"nbs", currency.NBSProvider, nil},
+		{"Consensus", currency.ConsensusProvider, nil},
 		{"", currency.Provider(""), errors.New("value  is not valid Provider")},
 		{"not-valid-value", currency.Provider(""), errors.New("value not-valid-value is not valid Provider")},
 	}
@@ -0,0 +1,21 @@
+package currency
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrQuotaExceeded = errors.New("request quota exceeded")
+
+// QuotaStorage keeps the number of requests spent against a provider's quota,
+// windows are identified by their start time.
+type QuotaStorage interface {
+	io.Closer
+	// Reserve adds n requests to the window when the total stays within limit
+	// and returns the number of requests used in the window.
+	// ErrQuotaExceeded is returned, with the current usage, when it would not.
+	Reserve(provider Provider, window time.Time, n, limit int) (int, error)
+	// Used returns the number of requests spent in the window
+	Used(provider Provider, window time.Time) (int, error)
+}
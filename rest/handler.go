@@ -0,0 +1,320 @@
+// Package rest exposes stored rates and conversions over a JSON HTTP API.
+//
+//	GET /rates?from=EUR&to=USD[&provider=ecb][&start=...][&end=...][&page=1][&perPage=20][&storage=mysql]
+//	GET /rates/latest?from=EUR&to=USD[&provider=ecb][&storage=mysql]
+//	GET /convert?from=EUR&to=RSD&amount=100[&date=...][&provider=nbs]
+//
+// Dates are accepted as YYYY-MM-DD or RFC 3339, end and date without time cover the whole day.
+// Rates and amounts are encoded as strings.
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/services"
+)
+
+const (
+	DateFormat     = "2006-01-02"
+	DefaultPerPage = 20
+	MaxPerPage     = 500
+)
+
+var (
+	ErrMissingParameter = errors.New("missing required query parameter")
+	ErrInvalidParameter = errors.New("invalid query parameter")
+)
+
+type (
+	handler struct {
+		storages   []currency.Storage
+		conversion services.ConversionService
+	}
+
+	RatesResponse struct {
+		Data    []currency.CurrencyWithID `json:"data"`
+		Page    int64                     `json:"page"`
+		PerPage int64                     `json:"per_page"`
+	}
+
+	ConvertResponse struct {
+		From   string                    `json:"from"`
+		To     string                    `json:"to"`
+		Amount decimal.Decimal           `json:"amount"`
+		Value  decimal.Decimal           `json:"value"`
+		Rate   currency.CurrencyWithID   `json:"rate"`
+		Legs   []currency.CurrencyWithID `json:"legs"`
+	}
+
+	ErrorResponse struct {
+		Error string `json:"error"`
+	}
+)
+
+// NewHandler returns the API handler, rates are read from the first storage
+// unless the storage query parameter names another one.
+// Conversion uses storages when conversion.Storages is empty.
+func NewHandler(storages []currency.Storage, conversion services.ConversionService) http.Handler {
+	if len(conversion.Storages) == 0 {
+		conversion.Storages = storages
+	}
+
+	h := handler{storages: storages, conversion: conversion}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rates", get(h.rates))
+	mux.HandleFunc("/rates/latest", get(h.latest))
+	mux.HandleFunc("/convert", get(h.convert))
+
+	return mux
+}
+
+func get(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (h handler) rates(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	from, to, err := services.ParsePair(query.Get("from"), query.Get("to"))
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	provider, err := services.ParseProvider(query.Get("provider"))
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	start, err := dateParam(query, "start", time.Time{}, false)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	end, err := dateParam(query, "end", time.Now(), true)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	page, err := intParam(query, "page", 1, 1, 0)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	perPage, err := intParam(query, "perPage", DefaultPerPage, 1, MaxPerPage)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	storage, err := services.FindStorage(h.storages, query.Get("storage"))
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if start.After(end) {
+		writeError(w, fmt.Errorf("%w: start cannot be after end", ErrInvalidParameter))
+		return
+	}
+
+	data, err := storage.GetByDateAndProvider(from, to, provider, start, end, page, perPage)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, RatesResponse{Data: data, Page: page, PerPage: perPage})
+}
+
+func (h handler) latest(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	from, to, err := services.ParsePair(query.Get("from"), query.Get("to"))
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	provider, err := services.ParseProvider(query.Get("provider"))
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	storage, err := services.FindStorage(h.storages, query.Get("storage"))
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	rate, err := currency.LatestRate(storage, from, to, provider)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rate)
+}
+
+func (h handler) convert(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	from, to, err := services.ParsePair(query.Get("from"), query.Get("to"))
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	provider, err := services.ParseProvider(query.Get("provider"))
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if query.Get("amount") == "" {
+		writeError(w, fmt.Errorf("%w: amount", ErrMissingParameter))
+		return
+	}
+
+	amount, err := decimal.NewFromString(query.Get("amount"))
+
+	if err != nil {
+		writeError(w, fmt.Errorf("%w: amount: %v", ErrInvalidParameter, err))
+		return
+	}
+
+	// Zero date converts using the latest rate
+	date, err := dateParam(query, "date", time.Time{}, true)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	conversion := h.conversion
+	conversion.Ctx = r.Context()
+
+	result, err := conversion.ConvertWithRate(from, to, provider, amount, date)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ConvertResponse{
+		From:   from,
+		To:     to,
+		Amount: amount,
+		Value:  result.Value,
+		Rate:   result.Rate,
+		Legs:   result.Legs,
+	})
+}
+
+// dateParam parses the date in DateFormat or RFC 3339,
+// dates without time are moved to the end of the day when endOfDay is set.
+func dateParam(query url.Values, key string, defaultValue time.Time, endOfDay bool) (time.Time, error) {
+	value := query.Get(key)
+
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	if date, err := time.Parse(DateFormat, value); err == nil {
+		if endOfDay {
+			return date.Add(24*time.Hour - time.Nanosecond), nil
+		}
+
+		return date, nil
+	}
+
+	date, err := time.Parse(time.RFC3339, value)
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be %s or RFC 3339", ErrInvalidParameter, key, DateFormat)
+	}
+
+	return date, nil
+}
+
+// intParam parses a positive integer, max is ignored when it is zero
+func intParam(query url.Values, key string, defaultValue, min, max int64) (int64, error) {
+	value := query.Get(key)
+
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+
+	if err != nil || n < min || (max != 0 && n > max) {
+		if max != 0 {
+			return 0, fmt.Errorf("%w: %s must be between %d and %d", ErrInvalidParameter, key, min, max)
+		}
+
+		return 0, fmt.Errorf("%w: %s must be at least %d", ErrInvalidParameter, key, min)
+	}
+
+	return n, nil
+}
+
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrMissingParameter), errors.Is(err, ErrInvalidParameter),
+		errors.Is(err, services.ErrMissingPair), errors.Is(err, services.ErrInvalidProvider), errors.Is(err, services.ErrUnknownStorage):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrCurrencyNotFound), errors.Is(err, currency.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrTimeRanOut):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusCode(err), ErrorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,200 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/rest"
+	"github.com/malusev998/currency/services"
+	"github.com/malusev998/currency/storage"
+)
+
+var day = time.Date(2020, 10, 16, 0, 0, 0, 0, time.UTC)
+
+func newServer(t *testing.T) *httptest.Server {
+	st, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+	require.Nil(t, err)
+
+	_, err = st.Store([]currency.Currency{
+		{From: "EUR", To: "RSD", Provider: currency.NBSProvider, Rate: decimal.RequireFromString("117.58"), CreatedAt: day.Add(8 * time.Hour)},
+		{From: "EUR", To: "RSD", Provider: currency.ECBProvider, Rate: decimal.RequireFromString("117.6"), CreatedAt: day.Add(9 * time.Hour)},
+		{From: "EUR", To: "RSD", Provider: currency.NBSProvider, Rate: decimal.RequireFromString("117.59"), CreatedAt: day.Add(32 * time.Hour)},
+		{From: "EUR", To: "USD", Provider: currency.ECBProvider, Rate: decimal.RequireFromString("1.25"), CreatedAt: day.Add(9 * time.Hour)},
+	})
+	require.Nil(t, err)
+
+	server := httptest.NewServer(rest.NewHandler([]currency.Storage{st}, services.ConversionService{Pivot: "EUR"}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func getJSON(t *testing.T, url string, body interface{}) int {
+	res, err := http.Get(url)
+	require.Nil(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	require.Nil(t, json.NewDecoder(res.Body).Decode(body))
+
+	return res.StatusCode
+}
+
+func TestRates(t *testing.T) {
+	t.Parallel()
+	server := newServer(t)
+
+	t.Run("Filters and paginates", func(t *testing.T) {
+		asserts := require.New(t)
+		var body rest.RatesResponse
+
+		status := getJSON(t, server.URL+"/rates?from=eur&to=rsd&provider=nbs&perPage=1&page=2", &body)
+
+		asserts.Equal(http.StatusOK, status)
+		asserts.Equal(int64(2), body.Page)
+		asserts.Equal(int64(1), body.PerPage)
+		asserts.Len(body.Data, 1)
+		asserts.Equal("117.59", body.Data[0].Rate.String())
+		asserts.Equal(currency.NBSProvider, body.Data[0].Provider)
+	})
+
+	t.Run("End date covers the whole day", func(t *testing.T) {
+		asserts := require.New(t)
+		var body rest.RatesResponse
+
+		status := getJSON(t, server.URL+"/rates?from=EUR&to=RSD&start=2020-10-16&end=2020-10-16", &body)
+
+		asserts.Equal(http.StatusOK, status)
+		asserts.Len(body.Data, 2)
+		asserts.True(day.Add(8 * time.Hour).Equal(body.Data[0].CreatedAt))
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		urls := []string{
+			"/rates?from=EUR",
+			"/rates?from=EUR&to=RSD&provider=unknown",
+			"/rates?from=EUR&to=RSD&start=yesterday",
+			"/rates?from=EUR&to=RSD&perPage=0",
+			"/rates?from=EUR&to=RSD&perPage=100000",
+			"/rates?from=EUR&to=RSD&start=2020-10-17&end=2020-10-16",
+			"/rates?from=EUR&to=RSD&storage=mysql",
+		}
+
+		for _, url := range urls {
+			var body rest.ErrorResponse
+
+			require.Equal(t, http.StatusBadRequest, getJSON(t, server.URL+url, &body), url)
+			require.NotEmpty(t, body.Error)
+		}
+	})
+
+	t.Run("Method not allowed", func(t *testing.T) {
+		asserts := require.New(t)
+		res, err := http.Post(server.URL+"/rates?from=EUR&to=RSD", "application/json", nil)
+
+		asserts.Nil(err)
+		res.Body.Close()
+		asserts.Equal(http.StatusMethodNotAllowed, res.StatusCode)
+	})
+}
+
+func TestLatest(t *testing.T) {
+	t.Parallel()
+	server := newServer(t)
+
+	t.Run("Latest from any provider", func(t *testing.T) {
+		asserts := require.New(t)
+		var body currency.CurrencyWithID
+
+		status := getJSON(t, server.URL+"/rates/latest?from=EUR&to=RSD", &body)
+
+		asserts.Equal(http.StatusOK, status)
+		asserts.NotNil(body.ID)
+		asserts.Equal("117.59", body.Rate.String())
+	})
+
+	t.Run("Latest from provider", func(t *testing.T) {
+		asserts := require.New(t)
+		var body currency.CurrencyWithID
+
+		status := getJSON(t, server.URL+"/rates/latest?from=EUR&to=RSD&provider=ecb", &body)
+
+		asserts.Equal(http.StatusOK, status)
+		asserts.Equal("117.6", body.Rate.String())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		var body rest.ErrorResponse
+
+		require.Equal(t, http.StatusNotFound, getJSON(t, server.URL+"/rates/latest?from=EUR&to=JPY", &body))
+	})
+}
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+	server := newServer(t)
+
+	t.Run("Latest rate", func(t *testing.T) {
+		asserts := require.New(t)
+		var body rest.ConvertResponse
+
+		status := getJSON(t, server.URL+"/convert?from=EUR&to=RSD&amount=100", &body)
+
+		asserts.Equal(http.StatusOK, status)
+		asserts.Equal("100", body.Amount.String())
+		asserts.Equal("11759", body.Value.String())
+		asserts.Len(body.Legs, 1)
+	})
+
+	t.Run("Rate for the date", func(t *testing.T) {
+		asserts := require.New(t)
+		var body rest.ConvertResponse
+
+		status := getJSON(t, server.URL+"/convert?from=EUR&to=RSD&amount=100&date=2020-10-16&provider=nbs", &body)
+
+		asserts.Equal(http.StatusOK, status)
+		asserts.Equal("11758", body.Value.String())
+		asserts.True(day.Add(8 * time.Hour).Equal(body.Rate.CreatedAt))
+	})
+
+	t.Run("Triangulated through pivot", func(t *testing.T) {
+		asserts := require.New(t)
+		var body rest.ConvertResponse
+
+		status := getJSON(t, server.URL+"/convert?from=USD&to=RSD&amount=10&provider=ecb", &body)
+
+		asserts.Equal(http.StatusOK, status)
+		asserts.Equal("940.8", body.Value.String())
+		asserts.Len(body.Legs, 2)
+	})
+
+	t.Run("Encodes decimals as strings", func(t *testing.T) {
+		asserts := require.New(t)
+		var body map[string]interface{}
+
+		getJSON(t, server.URL+"/convert?from=EUR&to=RSD&amount=100", &body)
+
+		asserts.Equal("100", body["amount"])
+		asserts.Equal("11759", body["value"])
+	})
+
+	t.Run("Invalid amount", func(t *testing.T) {
+		var body rest.ErrorResponse
+
+		require.Equal(t, http.StatusBadRequest, getJSON(t, server.URL+"/convert?from=EUR&to=RSD&amount=ten", &body))
+		require.Equal(t, http.StatusBadRequest, getJSON(t, server.URL+"/convert?from=EUR&to=RSD", &body))
+	})
+
+	t.Run("Rate not found", func(t *testing.T) {
+		var body rest.ErrorResponse
+
+		require.Equal(t, http.StatusNotFound, getJSON(t, server.URL+"/convert?from=EUR&to=JPY&amount=1", &body))
+	})
+}
@@ -0,0 +1,241 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion7
+
+// CurrencyServiceClient is the client API for CurrencyService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CurrencyServiceClient interface {
+	// GetRates lists rates for a pair sorted by created_at ascending
+	GetRates(ctx context.Context, in *GetRatesRequest, opts ...grpc.CallOption) (*GetRatesResponse, error)
+	// GetLatestRate returns the most recent rate for a pair
+	GetLatestRate(ctx context.Context, in *GetLatestRateRequest, opts ...grpc.CallOption) (*Rate, error)
+	// Convert converts the amount using ConversionService
+	Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error)
+	// WatchRates sends the latest rate of every pair and then every newer rate as it is stored
+	WatchRates(ctx context.Context, in *WatchRatesRequest, opts ...grpc.CallOption) (CurrencyService_WatchRatesClient, error)
+}
+
+type currencyServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCurrencyServiceClient(cc grpc.ClientConnInterface) CurrencyServiceClient {
+	return &currencyServiceClient{cc}
+}
+
+func (c *currencyServiceClient) GetRates(ctx context.Context, in *GetRatesRequest, opts ...grpc.CallOption) (*GetRatesResponse, error) {
+	out := new(GetRatesResponse)
+	err := c.cc.Invoke(ctx, "/currency.v1.CurrencyService/GetRates", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *currencyServiceClient) GetLatestRate(ctx context.Context, in *GetLatestRateRequest, opts ...grpc.CallOption) (*Rate, error) {
+	out := new(Rate)
+	err := c.cc.Invoke(ctx, "/currency.v1.CurrencyService/GetLatestRate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *currencyServiceClient) Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error) {
+	out := new(ConvertResponse)
+	err := c.cc.Invoke(ctx, "/currency.v1.CurrencyService/Convert", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *currencyServiceClient) WatchRates(ctx context.Context, in *WatchRatesRequest, opts ...grpc.CallOption) (CurrencyService_WatchRatesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_CurrencyService_serviceDesc.Streams[0], "/currency.v1.CurrencyService/WatchRates", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &currencyServiceWatchRatesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CurrencyService_WatchRatesClient interface {
+	Recv() (*Rate, error)
+	grpc.ClientStream
+}
+
+type currencyServiceWatchRatesClient struct {
+	grpc.ClientStream
+}
+
+func (x *currencyServiceWatchRatesClient) Recv() (*Rate, error) {
+	m := new(Rate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CurrencyServiceServer is the server API for CurrencyService service.
+// All implementations must embed UnimplementedCurrencyServiceServer
+// for forward compatibility
+type CurrencyServiceServer interface {
+	// GetRates lists rates for a pair sorted by created_at ascending
+	GetRates(context.Context, *GetRatesRequest) (*GetRatesResponse, error)
+	// GetLatestRate returns the most recent rate for a pair
+	GetLatestRate(context.Context, *GetLatestRateRequest) (*Rate, error)
+	// Convert converts the amount using ConversionService
+	Convert(context.Context, *ConvertRequest) (*ConvertResponse, error)
+	// WatchRates sends the latest rate of every pair and then every newer rate as it is stored
+	WatchRates(*WatchRatesRequest, CurrencyService_WatchRatesServer) error
+	mustEmbedUnimplementedCurrencyServiceServer()
+}
+
+// UnimplementedCurrencyServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedCurrencyServiceServer struct {
+}
+
+func (UnimplementedCurrencyServiceServer) GetRates(context.Context, *GetRatesRequest) (*GetRatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRates not implemented")
+}
+func (UnimplementedCurrencyServiceServer) GetLatestRate(context.Context, *GetLatestRateRequest) (*Rate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLatestRate not implemented")
+}
+func (UnimplementedCurrencyServiceServer) Convert(context.Context, *ConvertRequest) (*ConvertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Convert not implemented")
+}
+func (UnimplementedCurrencyServiceServer) WatchRates(*WatchRatesRequest, CurrencyService_WatchRatesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchRates not implemented")
+}
+func (UnimplementedCurrencyServiceServer) mustEmbedUnimplementedCurrencyServiceServer() {}
+
+// UnsafeCurrencyServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CurrencyServiceServer will
+// result in compilation errors.
+type UnsafeCurrencyServiceServer interface {
+	mustEmbedUnimplementedCurrencyServiceServer()
+}
+
+func RegisterCurrencyServiceServer(s grpc.ServiceRegistrar, srv CurrencyServiceServer) {
+	s.RegisterService(&_CurrencyService_serviceDesc, srv)
+}
+
+func _CurrencyService_GetRates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CurrencyServiceServer).GetRates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/currency.v1.CurrencyService/GetRates",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CurrencyServiceServer).GetRates(ctx, req.(*GetRatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CurrencyService_GetLatestRate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLatestRateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CurrencyServiceServer).GetLatestRate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/currency.v1.CurrencyService/GetLatestRate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CurrencyServiceServer).GetLatestRate(ctx, req.(*GetLatestRateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CurrencyService_Convert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CurrencyServiceServer).Convert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/currency.v1.CurrencyService/Convert",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CurrencyServiceServer).Convert(ctx, req.(*ConvertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CurrencyService_WatchRates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRatesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CurrencyServiceServer).WatchRates(m, &currencyServiceWatchRatesServer{stream})
+}
+
+type CurrencyService_WatchRatesServer interface {
+	Send(*Rate) error
+	grpc.ServerStream
+}
+
+type currencyServiceWatchRatesServer struct {
+	grpc.ServerStream
+}
+
+func (x *currencyServiceWatchRatesServer) Send(m *Rate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _CurrencyService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "currency.v1.CurrencyService",
+	HandlerType: (*CurrencyServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetRates",
+			Handler:    _CurrencyService_GetRates_Handler,
+		},
+		{
+			MethodName: "GetLatestRate",
+			Handler:    _CurrencyService_GetLatestRate_Handler,
+		},
+		{
+			MethodName: "Convert",
+			Handler:    _CurrencyService_Convert_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchRates",
+			Handler:       _CurrencyService_WatchRates_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "currency/v1/currency.proto",
+}
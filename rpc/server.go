@@ -0,0 +1,363 @@
+// Package rpc implements the gRPC CurrencyService defined in rpc/proto.
+//
+// Generated code lives in rpc/pb, regenerate it with `task proto`.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/iso4217"
+	"github.com/malusev998/currency/rpc/pb"
+	"github.com/malusev998/currency/services"
+)
+
+const (
+	DefaultPerPage      = 20
+	MaxPerPage          = 500
+	DefaultPollInterval = 5 * time.Second
+)
+
+type (
+	Config struct {
+		Storages []currency.Storage
+		// Conversion uses Storages when its Storages are empty
+		Conversion services.ConversionService
+		// PollInterval is how often WatchRates checks storage for new rates
+		PollInterval time.Duration
+	}
+
+	Server struct {
+		pb.UnimplementedCurrencyServiceServer
+		storages     []currency.Storage
+		conversion   services.ConversionService
+		pollInterval time.Duration
+	}
+
+	watchedPair struct {
+		from, to string
+		last     time.Time
+		// IDs of the rates sent with CreatedAt equal to last
+		sent map[string]bool
+	}
+)
+
+func NewServer(c Config) *Server {
+	conversion := c.Conversion
+
+	if len(conversion.Storages) == 0 {
+		conversion.Storages = c.Storages
+	}
+
+	pollInterval := c.PollInterval
+
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+
+	return &Server{
+		storages:     c.Storages,
+		conversion:   conversion,
+		pollInterval: pollInterval,
+	}
+}
+
+// Register registers the server on the gRPC server
+func (s *Server) Register(server *grpc.Server) {
+	pb.RegisterCurrencyServiceServer(server, s)
+}
+
+func (s *Server) GetRates(_ context.Context, req *pb.GetRatesRequest) (*pb.GetRatesResponse, error) {
+	from, to, err := services.ParsePair(req.From, req.To)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	provider, err := services.ParseProvider(req.Provider)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	storage, err := services.FindStorage(s.storages, req.Storage)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	start := time.Time{}
+	end := time.Now()
+
+	if req.Start != nil {
+		start = req.Start.AsTime()
+	}
+
+	if req.End != nil {
+		end = req.End.AsTime()
+	}
+
+	if start.After(end) {
+		return nil, status.Error(codes.InvalidArgument, "start cannot be after end")
+	}
+
+	page := req.Page
+	perPage := req.PerPage
+
+	if page == 0 {
+		page = 1
+	}
+
+	if perPage == 0 {
+		perPage = DefaultPerPage
+	}
+
+	if page < 1 || perPage < 1 || perPage > MaxPerPage {
+		return nil, status.Errorf(codes.InvalidArgument, "page must be at least 1 and per_page between 1 and %d", MaxPerPage)
+	}
+
+	rates, err := storage.GetByDateAndProvider(from, to, provider, start, end, page, perPage)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.GetRatesResponse{Rates: toRates(rates), Page: page, PerPage: perPage}, nil
+}
+
+func (s *Server) GetLatestRate(_ context.Context, req *pb.GetLatestRateRequest) (*pb.Rate, error) {
+	from, to, err := services.ParsePair(req.From, req.To)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	provider, err := services.ParseProvider(req.Provider)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	storage, err := services.FindStorage(s.storages, req.Storage)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	rate, err := currency.LatestRate(storage, from, to, provider)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toRate(rate), nil
+}
+
+func (s *Server) Convert(ctx context.Context, req *pb.ConvertRequest) (*pb.ConvertResponse, error) {
+	from, to, err := services.ParsePair(req.From, req.To)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	provider, err := services.ParseProvider(req.Provider)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	amount, err := decimal.NewFromString(req.Amount)
+
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid amount %q: %v", req.Amount, err)
+	}
+
+	// Zero date converts using the latest rate
+	date := time.Time{}
+
+	if req.Date != nil {
+		date = req.Date.AsTime()
+	}
+
+	conversion := s.conversion
+	conversion.Ctx = ctx
+
+	result, err := conversion.ConvertWithRate(from, to, provider, amount, date)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.ConvertResponse{
+		Value: result.Value.String(),
+		Rate:  toRate(result.Rate),
+		Legs:  toRates(result.Legs),
+	}, nil
+}
+
+func (s *Server) WatchRates(req *pb.WatchRatesRequest, stream pb.CurrencyService_WatchRatesServer) error {
+	if len(req.Pairs) == 0 {
+		return status.Error(codes.InvalidArgument, "at least one pair is required")
+	}
+
+	provider, err := services.ParseProvider(req.Provider)
+
+	if err != nil {
+		return toStatus(err)
+	}
+
+	storage, err := services.FindStorage(s.storages, req.Storage)
+
+	if err != nil {
+		return toStatus(err)
+	}
+
+	pairs := make([]*watchedPair, 0, len(req.Pairs))
+
+	for _, p := range req.Pairs {
+		parsed, err := iso4217.SplitPair(strings.ToUpper(p))
+
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		pairs = append(pairs, &watchedPair{from: parsed.From, to: parsed.To, sent: make(map[string]bool)})
+	}
+
+	for _, p := range pairs {
+		rate, err := currency.LatestRate(storage, p.from, p.to, provider)
+
+		if errors.Is(err, currency.ErrNotFound) {
+			// Only rates stored after the watch started are sent
+			p.last = time.Now()
+			continue
+		}
+
+		if err != nil {
+			return toStatus(err)
+		}
+
+		if err := p.send(stream, rate); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+			for _, p := range pairs {
+				if err := p.sendNewer(stream, storage, provider); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// sendNewer sends every rate stored since the last one sent
+func (p *watchedPair) sendNewer(stream pb.CurrencyService_WatchRatesServer, storage currency.Storage, provider currency.Provider) error {
+	// send moves p.last forward, pages are counted from the rate sent last before this poll
+	since, end := p.last, time.Now()
+
+	for page := int64(1); ; page++ {
+		rates, err := storage.GetByDateAndProvider(p.from, p.to, provider, since, end, page, MaxPerPage)
+
+		if err != nil {
+			return toStatus(err)
+		}
+
+		for _, rate := range rates {
+			if rate.CreatedAt.Before(p.last) || (rate.CreatedAt.Equal(p.last) && p.sent[id(rate.ID)]) {
+				continue
+			}
+
+			if err := p.send(stream, rate); err != nil {
+				return err
+			}
+		}
+
+		if len(rates) < MaxPerPage {
+			return nil
+		}
+	}
+}
+
+func (p *watchedPair) send(stream pb.CurrencyService_WatchRatesServer, rate currency.CurrencyWithID) error {
+	if err := stream.Send(toRate(rate)); err != nil {
+		return err
+	}
+
+	if rate.CreatedAt.After(p.last) {
+		p.last = rate.CreatedAt
+		p.sent = make(map[string]bool)
+	}
+
+	p.sent[id(rate.ID)] = true
+
+	return nil
+}
+
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, services.ErrMissingPair), errors.Is(err, services.ErrInvalidProvider), errors.Is(err, services.ErrUnknownStorage):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, services.ErrNoStorageProvided):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, services.ErrCurrencyNotFound), errors.Is(err, currency.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, services.ErrTimeRanOut), errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// id formats IDs returned by storages, Mongo ObjectIDs are formatted as hex
+func id(value interface{}) string {
+	switch casted := value.(type) {
+	case nil:
+		return ""
+	case interface{ Hex() string }:
+		return casted.Hex()
+	default:
+		return fmt.Sprint(casted)
+	}
+}
+
+func toRate(rate currency.CurrencyWithID) *pb.Rate {
+	return &pb.Rate{
+		Id:        id(rate.ID),
+		From:      rate.From,
+		To:        rate.To,
+		Provider:  string(rate.Provider),
+		Rate:      rate.Rate.String(),
+		CreatedAt: timestamppb.New(rate.CreatedAt),
+	}
+}
+
+func toRates(rates []currency.CurrencyWithID) []*pb.Rate {
+	result := make([]*pb.Rate, 0, len(rates))
+
+	for _, rate := range rates {
+		result = append(result, toRate(rate))
+	}
+
+	return result
+}
@@ -0,0 +1,306 @@
+package rpc_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/rpc"
+	"github.com/malusev998/currency/rpc/pb"
+	"github.com/malusev998/currency/services"
+	"github.com/malusev998/currency/storage"
+)
+
+var day = time.Date(2020, 10, 16, 0, 0, 0, 0, time.UTC)
+
+func newClient(t *testing.T) (pb.CurrencyServiceClient, currency.Storage) {
+	st, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+	require.Nil(t, err)
+
+	_, err = st.Store([]currency.Currency{
+		{From: "EUR", To: "RSD", Provider: currency.NBSProvider, Rate: decimal.RequireFromString("117.58"), CreatedAt: day.Add(8 * time.Hour)},
+		{From: "EUR", To: "RSD", Provider: currency.ECBProvider, Rate: decimal.RequireFromString("117.6"), CreatedAt: day.Add(9 * time.Hour)},
+		{From: "EUR", To: "RSD", Provider: currency.NBSProvider, Rate: decimal.RequireFromString("117.59"), CreatedAt: day.Add(32 * time.Hour)},
+		{From: "EUR", To: "USD", Provider: currency.ECBProvider, Rate: decimal.RequireFromString("1.25"), CreatedAt: day.Add(9 * time.Hour)},
+	})
+	require.Nil(t, err)
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+
+	rpc.NewServer(rpc.Config{
+		Storages:     []currency.Storage{st},
+		Conversion:   services.ConversionService{Pivot: "EUR"},
+		PollInterval: 10 * time.Millisecond,
+	}).Register(server)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+	require.Nil(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		server.Stop()
+	})
+
+	return pb.NewCurrencyServiceClient(conn), st
+}
+
+func TestServer_GetRates(t *testing.T) {
+	t.Parallel()
+	client, _ := newClient(t)
+	ctx := context.Background()
+
+	t.Run("Filters and paginates", func(t *testing.T) {
+		asserts := require.New(t)
+
+		res, err := client.GetRates(ctx, &pb.GetRatesRequest{From: "eur", To: "rsd", Provider: "nbs", Page: 2, PerPage: 1})
+
+		asserts.Nil(err)
+		asserts.Equal(int64(2), res.Page)
+		asserts.Equal(int64(1), res.PerPage)
+		asserts.Len(res.Rates, 1)
+		asserts.Equal("117.59", res.Rates[0].Rate)
+		asserts.Equal(string(currency.NBSProvider), res.Rates[0].Provider)
+		asserts.NotEmpty(res.Rates[0].Id)
+	})
+
+	t.Run("Date range", func(t *testing.T) {
+		asserts := require.New(t)
+
+		res, err := client.GetRates(ctx, &pb.GetRatesRequest{
+			From:  "EUR",
+			To:    "RSD",
+			Start: timestamppb.New(day),
+			End:   timestamppb.New(day.Add(24*time.Hour - time.Nanosecond)),
+		})
+
+		asserts.Nil(err)
+		asserts.Len(res.Rates, 2)
+		asserts.True(day.Add(8 * time.Hour).Equal(res.Rates[0].CreatedAt.AsTime()))
+	})
+
+	t.Run("Invalid arguments", func(t *testing.T) {
+		requests := []*pb.GetRatesRequest{
+			{From: "EUR"},
+			{From: "EUR", To: "RSD", Provider: "unknown"},
+			{From: "EUR", To: "RSD", PerPage: 100000},
+			{From: "EUR", To: "RSD", Start: timestamppb.New(day.Add(time.Hour)), End: timestamppb.New(day)},
+			{From: "EUR", To: "RSD", Storage: "mysql"},
+		}
+
+		for _, req := range requests {
+			_, err := client.GetRates(ctx, req)
+
+			require.Equal(t, codes.InvalidArgument, status.Code(err), req.String())
+		}
+	})
+}
+
+func TestServer_GetLatestRate(t *testing.T) {
+	t.Parallel()
+	client, _ := newClient(t)
+	ctx := context.Background()
+
+	t.Run("Latest from any provider", func(t *testing.T) {
+		asserts := require.New(t)
+
+		rate, err := client.GetLatestRate(ctx, &pb.GetLatestRateRequest{From: "EUR", To: "RSD"})
+
+		asserts.Nil(err)
+		asserts.Equal("117.59", rate.Rate)
+	})
+
+	t.Run("Latest from provider", func(t *testing.T) {
+		asserts := require.New(t)
+
+		rate, err := client.GetLatestRate(ctx, &pb.GetLatestRateRequest{From: "EUR", To: "RSD", Provider: "ecb"})
+
+		asserts.Nil(err)
+		asserts.Equal("117.6", rate.Rate)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		_, err := client.GetLatestRate(ctx, &pb.GetLatestRateRequest{From: "EUR", To: "JPY"})
+
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestServer_Convert(t *testing.T) {
+	t.Parallel()
+	client, _ := newClient(t)
+	ctx := context.Background()
+
+	t.Run("Latest rate", func(t *testing.T) {
+		asserts := require.New(t)
+
+		res, err := client.Convert(ctx, &pb.ConvertRequest{From: "EUR", To: "RSD", Amount: "100"})
+
+		asserts.Nil(err)
+		asserts.Equal("11759", res.Value)
+		asserts.Len(res.Legs, 1)
+	})
+
+	t.Run("Rate for the date", func(t *testing.T) {
+		asserts := require.New(t)
+
+		res, err := client.Convert(ctx, &pb.ConvertRequest{
+			From:     "EUR",
+			To:       "RSD",
+			Provider: "nbs",
+			Amount:   "100",
+			Date:     timestamppb.New(day.Add(24*time.Hour - time.Nanosecond)),
+		})
+
+		asserts.Nil(err)
+		asserts.Equal("11758", res.Value)
+		asserts.True(day.Add(8 * time.Hour).Equal(res.Rate.CreatedAt.AsTime()))
+	})
+
+	t.Run("Triangulated through pivot", func(t *testing.T) {
+		asserts := require.New(t)
+
+		res, err := client.Convert(ctx, &pb.ConvertRequest{From: "USD", To: "RSD", Provider: "ecb", Amount: "10"})
+
+		asserts.Nil(err)
+		asserts.Equal("940.8", res.Value)
+		asserts.Len(res.Legs, 2)
+		asserts.Empty(res.Rate.Id)
+	})
+
+	t.Run("Invalid amount", func(t *testing.T) {
+		_, err := client.Convert(ctx, &pb.ConvertRequest{From: "EUR", To: "RSD", Amount: "ten"})
+
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Rate not found", func(t *testing.T) {
+		_, err := client.Convert(ctx, &pb.ConvertRequest{From: "EUR", To: "JPY", Amount: "1"})
+
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestServer_WatchRates(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Sends latest and newly stored rates", func(t *testing.T) {
+		asserts := require.New(t)
+		client, st := newClient(t)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		stream, err := client.WatchRates(ctx, &pb.WatchRatesRequest{Pairs: []string{"EUR_RSD", "usd_jpy"}})
+		asserts.Nil(err)
+
+		rate, err := stream.Recv()
+		asserts.Nil(err)
+		asserts.Equal("117.59", rate.Rate)
+
+		now := time.Now()
+		_, err = st.Store([]currency.Currency{
+			{From: "EUR", To: "RSD", Provider: currency.NBSProvider, Rate: decimal.RequireFromString("117.61"), CreatedAt: now},
+			{From: "USD", To: "JPY", Provider: currency.ECBProvider, Rate: decimal.RequireFromString("105.44"), CreatedAt: now},
+		})
+		asserts.Nil(err)
+
+		received := make(map[string]string)
+
+		for len(received) < 2 {
+			rate, err := stream.Recv()
+			asserts.Nil(err)
+
+			_, exists := received[rate.From+"_"+rate.To]
+			asserts.False(exists, "rate sent twice")
+			received[rate.From+"_"+rate.To] = rate.Rate
+		}
+
+		asserts.Equal(map[string]string{"EUR_RSD": "117.61", "USD_JPY": "105.44"}, received)
+	})
+
+	t.Run("Sends every rate stored between polls", func(t *testing.T) {
+		asserts := require.New(t)
+		client, st := newClient(t)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		stream, err := client.WatchRates(ctx, &pb.WatchRatesRequest{Pairs: []string{"EUR_RSD"}})
+		asserts.Nil(err)
+
+		_, err = stream.Recv()
+		asserts.Nil(err)
+
+		count := 2*rpc.MaxPerPage + 10
+		rates := make([]currency.Currency, 0, count)
+		start := time.Now().Add(-time.Minute)
+
+		for i := 0; i < count; i++ {
+			rates = append(rates, currency.Currency{
+				From:      "EUR",
+				To:        "RSD",
+				Provider:  currency.NBSProvider,
+				Rate:      decimal.NewFromInt(int64(i)),
+				CreatedAt: start.Add(time.Duration(i) * time.Millisecond),
+			})
+		}
+
+		_, err = st.Store(rates)
+		asserts.Nil(err)
+
+		for i := 0; i < count; i++ {
+			rate, err := stream.Recv()
+			asserts.Nil(err)
+			asserts.Equal(decimal.NewFromInt(int64(i)).String(), rate.Rate)
+		}
+	})
+
+	t.Run("Stops when client cancels", func(t *testing.T) {
+		asserts := require.New(t)
+		client, _ := newClient(t)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		stream, err := client.WatchRates(ctx, &pb.WatchRatesRequest{Pairs: []string{"EUR_USD"}})
+		asserts.Nil(err)
+
+		_, err = stream.Recv()
+		asserts.Nil(err)
+
+		cancel()
+		_, err = stream.Recv()
+
+		asserts.NotEqual(io.EOF, err)
+		asserts.Equal(codes.Canceled, status.Code(err))
+	})
+
+	t.Run("Invalid pair", func(t *testing.T) {
+		client, _ := newClient(t)
+
+		stream, err := client.WatchRates(context.Background(), &pb.WatchRatesRequest{Pairs: []string{"EURUSD"}})
+		require.Nil(t, err)
+
+		_, err = stream.Recv()
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -1,13 +1,87 @@
 package currency
 
-import "time"
+import (
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 type (
 	Service interface {
 		Save(currenciesToFetch []string) (map[string][]CurrencyWithID, error)
 	}
 
+	HistoricalService interface {
+		Service
+		SaveByDateRange(currenciesToFetch []string, start, end time.Time) (map[string][]CurrencyWithID, error)
+	}
+
+	// ResultService reports the outcome of storing fetched rates in every storage
+	ResultService interface {
+		Service
+		SaveWithResult(currenciesToFetch []string) (SaveResult, error)
+	}
+
+	StorageResult struct {
+		// Storage is the name returned by GetStorageProviderName
+		Storage    string
+		Currencies []CurrencyWithID
+		Err        error
+	}
+
+	SaveResult struct {
+		Storages []StorageResult
+	}
+
 	Conversion interface {
-		Convert(from, to, provider string, value float32, date time.Time) (float32, error)
+		Convert(from, to, provider string, value decimal.Decimal, date time.Time) (decimal.Decimal, error)
 	}
 )
+
+// Currencies returns rates stored by every storage that succeeded, keyed by storage name
+func (r SaveResult) Currencies() map[string][]CurrencyWithID {
+	data := make(map[string][]CurrencyWithID, len(r.Storages))
+
+	for _, s := range r.Storages {
+		if s.Err == nil {
+			data[s.Storage] = s.Currencies
+		}
+	}
+
+	return data
+}
+
+// Failed returns results of storages that returned an error
+func (r SaveResult) Failed() []StorageResult {
+	failed := make([]StorageResult, 0)
+
+	for _, s := range r.Storages {
+		if s.Err != nil {
+			failed = append(failed, s)
+		}
+	}
+
+	return failed
+}
+
+// SaveWithResult calls SaveWithResult when s implements ResultService,
+// otherwise results are built from rates returned by Save
+func SaveWithResult(s Service, currenciesToFetch []string) (SaveResult, error) {
+	if resultService, ok := s.(ResultService); ok {
+		return resultService.SaveWithResult(currenciesToFetch)
+	}
+
+	saved, err := s.Save(currenciesToFetch)
+	result := SaveResult{Storages: make([]StorageResult, 0, len(saved))}
+
+	for storage, currencies := range saved {
+		result.Storages = append(result.Storages, StorageResult{Storage: storage, Currencies: currencies})
+	}
+
+	sort.Slice(result.Storages, func(i, j int) bool {
+		return result.Storages[i].Storage < result.Storages[j].Storage
+	})
+
+	return result, err
+}
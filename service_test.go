@@ -0,0 +1,59 @@
+package currency_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency"
+)
+
+type serviceStub struct {
+	saved map[string][]currency.CurrencyWithID
+	err   error
+}
+
+func (s serviceStub) Save([]string) (map[string][]currency.CurrencyWithID, error) {
+	return s.saved, s.err
+}
+
+func TestSaveWithResult(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+
+	// Services without per storage results are reported by the rates Save returned
+	result, err := currency.SaveWithResult(serviceStub{saved: map[string][]currency.CurrencyWithID{
+		"mysql":   {{ID: 1}},
+		"mongodb": {{ID: 2}, {ID: 3}},
+	}}, []string{"EUR_USD"})
+
+	asserts.Nil(err)
+	asserts.Len(result.Storages, 2)
+	asserts.Equal("mongodb", result.Storages[0].Storage)
+	asserts.Len(result.Storages[0].Currencies, 2)
+	asserts.Equal("mysql", result.Storages[1].Storage)
+	asserts.Empty(result.Failed())
+	asserts.Len(result.Currencies(), 2)
+
+	fetchErr := errors.New("fetch failed")
+	result, err = currency.SaveWithResult(serviceStub{err: fetchErr}, []string{"EUR_USD"})
+
+	asserts.Equal(fetchErr, err)
+	asserts.Empty(result.Storages)
+	asserts.NotNil(result.Currencies())
+}
+
+func TestSaveResult_Failed(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	storeErr := errors.New("connection refused")
+
+	result := currency.SaveResult{Storages: []currency.StorageResult{
+		{Storage: "mysql", Err: storeErr},
+		{Storage: "mongodb", Currencies: []currency.CurrencyWithID{{ID: 1}}},
+	}}
+
+	asserts.Equal([]currency.StorageResult{{Storage: "mysql", Err: storeErr}}, result.Failed())
+	asserts.Equal(map[string][]currency.CurrencyWithID{"mongodb": {{ID: 1}}}, result.Currencies())
+}
@@ -3,7 +3,6 @@ package services
 import (
 	"context"
 	"errors"
-	"math"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -21,73 +20,103 @@ type (
 	ConversionService struct {
 		Ctx      context.Context
 		Storages []currencyFetcher.Storage
+		// Policy selects the rate used when the date is not zero
+		Policy LookupPolicy
+		// MaxStaleness limits how far from the date nearest lookups search,
+		// DefaultMaxStaleness is used when it is not set
+		MaxStaleness time.Duration
+		// Pivot is the currency used to triangulate pairs that are not stored,
+		// triangulation is disabled when it is empty
+		Pivot string
+		// MaxRateSkew limits how far apart the rates used for triangulation can be stored,
+		// DefaultMaxRateSkew is used when it is not set
+		MaxRateSkew time.Duration
 	}
 
-	fetchCurrencies struct {
-		currencies []currencyFetcher.CurrencyWithID
-		error      error
+	// ConversionResult holds the converted value and the rate used for it.
+	// Rate has no ID when it is inverted or triangulated, Legs are the stored rates it is derived from.
+	ConversionResult struct {
+		Value decimal.Decimal
+		Rate  currencyFetcher.CurrencyWithID
+		Legs  []currencyFetcher.CurrencyWithID
+	}
+
+	fetchCurrency struct {
+		currency currencyFetcher.CurrencyWithID
+		legs     []currencyFetcher.CurrencyWithID
+		error    error
 	}
 )
 
-func (c ConversionService) Convert(from, to string, provider currencyFetcher.Provider, value float32, date time.Time) (float32, error) {
-	decimalValue := decimal.NewFromFloat32(value)
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+// Convert converts value using the rate stored for the given date,
+// zero date converts using the latest stored rate.
+func (c ConversionService) Convert(from, to string, provider currencyFetcher.Provider, value decimal.Decimal, date time.Time) (decimal.Decimal, error) {
+	result, err := c.ConvertWithRate(from, to, provider, value, date)
 
-	if len(c.Storages) == 0 {
-		return 0.0, ErrNoStorageProvided
+	if err != nil {
+		return decimal.Zero, err
 	}
 
-	// Optimization when there is only one storage provider
-	if len(c.Storages) == 1 {
-		currencies, err := c.Storages[0].GetByDateAndProvider(from, to, provider, startOfDay, date, 1, 1)
+	return result.Value, nil
+}
 
-		if err != nil {
-			return 0.0, err
-		}
+// ConvertWithRate works like Convert, but also returns the rate used,
+// so its CreatedAt can be audited.
+func (c ConversionService) ConvertWithRate(from, to string, provider currencyFetcher.Provider, value decimal.Decimal, date time.Time) (ConversionResult, error) {
+	rate, legs, err := c.findRate(from, to, provider, date)
+
+	if err != nil {
+		return ConversionResult{}, err
+	}
+
+	return ConversionResult{Value: value.Mul(rate.Rate), Rate: rate, Legs: legs}, nil
+}
+
+func (c ConversionService) findRate(from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, []currencyFetcher.CurrencyWithID, error) {
+	if len(c.Storages) == 0 {
+		return currencyFetcher.CurrencyWithID{}, nil, ErrNoStorageProvided
+	}
 
-		if len(currencies) == 0 {
-			return 0, ErrCurrencyNotFound
-		}
+	lookup := rateLookup{
+		policy:       c.Policy,
+		maxStaleness: c.MaxStaleness,
+		pivot:        c.Pivot,
+		maxRateSkew:  c.MaxRateSkew,
+	}
 
-		return convert(decimalValue, currencies[0].Rate)
+	// Optimization when there is only one storage provider
+	if len(c.Storages) == 1 {
+		return lookup.resolve(c.Storages[0], from, to, provider, date)
 	}
 
 	// If there are more storage providers
 	// first one that returns a value, that one will be used
 
-	currenciesChannel := make(chan fetchCurrencies)
-	defer close(currenciesChannel)
+	ctx := c.Ctx
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// Buffered so storages that respond after the first one do not block forever
+	currencyChannel := make(chan fetchCurrency, len(c.Storages))
 
 	for _, storage := range c.Storages {
 		go func(storage currencyFetcher.Storage) {
-			currencies, err := storage.GetByDateAndProvider(from, to, provider, startOfDay, date, 1, 1)
-			currenciesChannel <- fetchCurrencies{
-				currencies: currencies,
-				error:      err,
+			rate, legs, err := lookup.resolve(storage, from, to, provider, date)
+			currencyChannel <- fetchCurrency{
+				currency: rate,
+				legs:     legs,
+				error:    err,
 			}
 		}(storage)
 	}
 
 	select {
-	case <-c.Ctx.Done():
-		return 0.0, ErrTimeRanOut
-
-	case data := <-currenciesChannel:
-		if data.error != nil {
-			return 0.0, data.error
-		}
-
-		if len(data.currencies) == 0 {
-			return 0, ErrCurrencyNotFound
-		}
+	case <-ctx.Done():
+		return currencyFetcher.CurrencyWithID{}, nil, ErrTimeRanOut
 
-		return convert(decimalValue, data.currencies[0].Rate)
+	case data := <-currencyChannel:
+		return data.currency, data.legs, data.error
 	}
 }
-
-func convert(value decimal.Decimal, rate float32) (float32, error) {
-	rateDecimal := decimal.NewFromFloat32(rate)
-	floatValue, _ := value.Mul(rateDecimal).Float64()
-
-	return float32(math.Round(floatValue*1_000_000) / 1_000_000), nil
-}
@@ -6,10 +6,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/storage"
 )
 
 type mockStorage struct {
@@ -41,6 +43,16 @@ func (m *mockTimeoutStorage) GetByDateAndProvider(from, to string, provider curr
 	return nil, nil
 }
 
+func (m *mockTimeoutStorage) Latest(from, to string, provider currencyFetcher.Provider) (currencyFetcher.CurrencyWithID, error) {
+	time.Sleep(time.Duration(10) * time.Second)
+	return currencyFetcher.CurrencyWithID{}, nil
+}
+
+func (m *mockTimeoutStorage) LatestBefore(from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, error) {
+	time.Sleep(time.Duration(10) * time.Second)
+	return currencyFetcher.CurrencyWithID{}, nil
+}
+
 func (m *mockTimeoutStorage) Drop() error {
 	return nil
 }
@@ -78,6 +90,16 @@ func (m *mockStorage) GetByDateAndProvider(from, to string, provider currencyFet
 	return args.Get(0).([]currencyFetcher.CurrencyWithID), args.Error(1)
 }
 
+func (m *mockStorage) Latest(from, to string, provider currencyFetcher.Provider) (currencyFetcher.CurrencyWithID, error) {
+	args := m.Called(from, to, provider)
+	return args.Get(0).(currencyFetcher.CurrencyWithID), args.Error(1)
+}
+
+func (m *mockStorage) LatestBefore(from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, error) {
+	args := m.Called(from, to, provider, date)
+	return args.Get(0).(currencyFetcher.CurrencyWithID), args.Error(1)
+}
+
 func (m *mockStorage) Drop() error {
 	return nil
 }
@@ -110,7 +132,7 @@ func TestConversionService_Convert(t *testing.T) {
 						From:      "EUR",
 						To:        "USD",
 						Provider:  provider,
-						Rate:      1.2564421,
+						Rate:      decimal.RequireFromString("1.2564421"),
 						CreatedAt: time.Time{},
 					},
 					ID: 1,
@@ -118,18 +140,51 @@ func TestConversionService_Convert(t *testing.T) {
 			}, nil)
 
 		service := ConversionService{Ctx: context.Background(), Storages: []currencyFetcher.Storage{storage}}
-		value, err := service.Convert("EUR", "USD", provider, 1.531454, now)
+		value, err := service.Convert("EUR", "USD", provider, decimal.RequireFromString("1.531454"), now)
 		asserts.Nil(err)
-		asserts.Equal(float32(1.924183), value)
+		asserts.Equal("1.9241832798134", value.String())
+	})
+
+	t.Run("LatestRateWhenDateIsZero", func(t *testing.T) {
+		storage := &mockStorage{}
+		storage.On("Latest", "EUR", "USD", provider).
+			Return(currencyFetcher.CurrencyWithID{
+				Currency: currencyFetcher.Currency{
+					From:     "EUR",
+					To:       "USD",
+					Provider: provider,
+					Rate:     decimal.NewFromInt(2),
+				},
+				ID: 1,
+			}, nil)
+
+		service := ConversionService{Ctx: context.Background(), Storages: []currencyFetcher.Storage{storage}}
+		value, err := service.Convert("EUR", "USD", provider, decimal.RequireFromString("1.5"), time.Time{})
+		asserts.Nil(err)
+		asserts.Equal("3", value.String())
+		storage.AssertNotCalled(t, "GetByDateAndProvider")
+	})
+
+	t.Run("LatestRateNotFound", func(t *testing.T) {
+		storage := &mockStorage{}
+		storage.On("Latest", "EUR", "USD", provider).
+			Return(currencyFetcher.CurrencyWithID{}, currencyFetcher.ErrNotFound)
+		storage.On("Latest", "USD", "EUR", provider).
+			Return(currencyFetcher.CurrencyWithID{}, currencyFetcher.ErrNotFound)
+
+		service := ConversionService{Ctx: context.Background(), Storages: []currencyFetcher.Storage{storage}}
+		value, err := service.Convert("EUR", "USD", provider, decimal.RequireFromString("1.5"), time.Time{})
+		asserts.True(errors.Is(err, ErrCurrencyNotFound))
+		asserts.True(value.IsZero())
 	})
 
 	t.Run("NoStorageProvider", func(t *testing.T) {
 		service := ConversionService{Ctx: context.Background()}
-		value, err := service.Convert("EUR", "USD", "TestProvider", 1.531454, now)
+		value, err := service.Convert("EUR", "USD", "TestProvider", decimal.RequireFromString("1.531454"), now)
 
 		asserts.NotNil(err)
 		asserts.True(errors.Is(err, ErrNoStorageProvided))
-		asserts.Equal(float32(0.0), value)
+		asserts.True(value.IsZero())
 	})
 
 	t.Run("StorageTimeOut", func(t *testing.T) {
@@ -141,9 +196,162 @@ func TestConversionService_Convert(t *testing.T) {
 			cancel()
 		}()
 		service := ConversionService{Ctx: ctx, Storages: []currencyFetcher.Storage{storage1, storage2}}
-		value, err := service.Convert("EUR", "USD", "TestProvider", 1.531454, now)
+		value, err := service.Convert("EUR", "USD", "TestProvider", decimal.RequireFromString("1.531454"), now)
 		asserts.NotNil(err)
 		asserts.True(errors.Is(err, ErrTimeRanOut))
-		asserts.Equal(float32(0.0), value)
+		asserts.True(value.IsZero())
+	})
+}
+
+func TestConversionService_ConvertWithRate(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	provider := currencyFetcher.Provider("TestProvider")
+	midnight := time.Date(2020, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	memory, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+	asserts.NoError(err)
+
+	// Hourly fetcher, last rate of the previous day and first of the next hour
+	_, err = memory.Store([]currencyFetcher.Currency{
+		{From: "EUR", To: "USD", Provider: provider, Rate: decimal.NewFromInt(1), CreatedAt: midnight.Add(-2 * time.Hour)},
+		{From: "EUR", To: "USD", Provider: provider, Rate: decimal.NewFromInt(2), CreatedAt: midnight.Add(-time.Hour)},
+		{From: "EUR", To: "USD", Provider: provider, Rate: decimal.NewFromInt(3), CreatedAt: midnight.Add(time.Hour)},
+	})
+	asserts.NoError(err)
+
+	date := midnight.Add(30 * time.Minute)
+
+	t.Run("StartOfDayDoesNotFindRate", func(t *testing.T) {
+		service := ConversionService{Storages: []currencyFetcher.Storage{memory}}
+		_, err := service.ConvertWithRate("EUR", "USD", provider, decimal.NewFromInt(10), date.Add(-time.Minute))
+
+		asserts.True(errors.Is(err, ErrCurrencyNotFound))
+	})
+
+	t.Run("NearestBefore", func(t *testing.T) {
+		service := ConversionService{Storages: []currencyFetcher.Storage{memory}, Policy: LookupNearestBefore}
+		result, err := service.ConvertWithRate("EUR", "USD", provider, decimal.NewFromInt(10), date)
+
+		asserts.NoError(err)
+		asserts.Equal("20", result.Value.String())
+		asserts.True(midnight.Add(-time.Hour).Equal(result.Rate.CreatedAt))
+	})
+
+	t.Run("NearestBeforeStale", func(t *testing.T) {
+		service := ConversionService{
+			Storages:     []currencyFetcher.Storage{memory},
+			Policy:       LookupNearestBefore,
+			MaxStaleness: time.Hour,
+		}
+		_, err := service.ConvertWithRate("EUR", "USD", provider, decimal.NewFromInt(10), date)
+
+		asserts.True(errors.Is(err, ErrCurrencyNotFound))
+	})
+
+	t.Run("Nearest", func(t *testing.T) {
+		service := ConversionService{Storages: []currencyFetcher.Storage{memory}, Policy: LookupNearest}
+		result, err := service.ConvertWithRate("EUR", "USD", provider, decimal.NewFromInt(10), date.Add(time.Minute))
+
+		asserts.NoError(err)
+		asserts.Equal("30", result.Value.String())
+		asserts.True(midnight.Add(time.Hour).Equal(result.Rate.CreatedAt))
+	})
+
+	t.Run("NearestPrefersRateBefore", func(t *testing.T) {
+		service := ConversionService{Storages: []currencyFetcher.Storage{memory}, Policy: LookupNearest}
+		result, err := service.ConvertWithRate("EUR", "USD", provider, decimal.NewFromInt(10), midnight)
+
+		asserts.NoError(err)
+		asserts.Equal("20", result.Value.String())
+		asserts.True(midnight.Add(-time.Hour).Equal(result.Rate.CreatedAt))
+	})
+}
+
+func TestConversionService_Triangulation(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	provider := currencyFetcher.Provider("TestProvider")
+	otherProvider := currencyFetcher.Provider("OtherProvider")
+	now := time.Date(2020, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	memory, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+	asserts.NoError(err)
+
+	stored, err := memory.Store([]currencyFetcher.Currency{
+		{From: "EUR", To: "RSD", Provider: provider, Rate: decimal.NewFromInt(125), CreatedAt: now},
+		{From: "EUR", To: "USD", Provider: provider, Rate: decimal.RequireFromString("1.25"), CreatedAt: now.Add(-10 * time.Minute)},
+		{From: "EUR", To: "GBP", Provider: otherProvider, Rate: decimal.RequireFromString("0.9"), CreatedAt: now},
+		{From: "EUR", To: "CHF", Provider: provider, Rate: decimal.RequireFromString("1.1"), CreatedAt: now.Add(-3 * time.Hour)},
+	})
+	asserts.NoError(err)
+
+	service := ConversionService{Storages: []currencyFetcher.Storage{memory}, Pivot: "EUR"}
+
+	t.Run("Inversion", func(t *testing.T) {
+		result, err := service.ConvertWithRate("RSD", "EUR", provider, decimal.NewFromInt(250), now)
+
+		asserts.NoError(err)
+		asserts.Equal("2", result.Value.String())
+		asserts.Nil(result.Rate.ID)
+		asserts.Equal("RSD", result.Rate.From)
+		asserts.Equal("EUR", result.Rate.To)
+		asserts.Len(result.Legs, 1)
+		asserts.Equal(stored[0].ID, result.Legs[0].ID)
 	})
+
+	t.Run("ThroughPivot", func(t *testing.T) {
+		result, err := service.ConvertWithRate("USD", "RSD", provider, decimal.NewFromInt(10), now)
+
+		asserts.NoError(err)
+		asserts.Equal("1000", result.Value.String())
+		asserts.Equal(provider, result.Rate.Provider)
+		asserts.True(now.Add(-10 * time.Minute).Equal(result.Rate.CreatedAt))
+		asserts.Len(result.Legs, 2)
+		asserts.Equal(stored[1].ID, result.Legs[0].ID)
+		asserts.Equal(stored[0].ID, result.Legs[1].ID)
+	})
+
+	t.Run("LegsFromDifferentProviders", func(t *testing.T) {
+		_, err := service.ConvertWithRate("USD", "GBP", currencyFetcher.EmptyProvider, decimal.NewFromInt(10), time.Time{})
+
+		asserts.True(errors.Is(err, ErrCurrencyNotFound))
+	})
+
+	t.Run("LegsTooFarApart", func(t *testing.T) {
+		_, err := service.ConvertWithRate("CHF", "RSD", provider, decimal.NewFromInt(10), time.Time{})
+
+		asserts.True(errors.Is(err, ErrCurrencyNotFound))
+	})
+
+	t.Run("WithoutPivot", func(t *testing.T) {
+		service := ConversionService{Storages: []currencyFetcher.Storage{memory}}
+		_, err := service.ConvertWithRate("USD", "RSD", provider, decimal.NewFromInt(10), now)
+
+		asserts.True(errors.Is(err, ErrCurrencyNotFound))
+	})
+}
+
+func TestConvertToLookupPolicyFromString(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+
+	values := []struct {
+		value    string
+		expected LookupPolicy
+		isError  bool
+	}{
+		{"", LookupStartOfDay, false},
+		{"startOfDay", LookupStartOfDay, false},
+		{"nearestbefore", LookupNearestBefore, false},
+		{"Nearest", LookupNearest, false},
+		{"closest", LookupStartOfDay, true},
+	}
+
+	for _, v := range values {
+		policy, err := ConvertToLookupPolicyFromString(v.value)
+
+		asserts.Equal(v.expected, policy)
+		asserts.Equal(v.isError, err != nil)
+	}
 }
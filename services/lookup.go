@@ -0,0 +1,243 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	currencyFetcher "github.com/malusev998/currency"
+)
+
+// LookupPolicy decides which stored rate is used for a conversion at a given time
+type LookupPolicy int
+
+const (
+	// LookupStartOfDay uses the first rate stored between the start of the day and the requested time
+	LookupStartOfDay LookupPolicy = iota
+	// LookupNearestBefore uses the last rate stored at or before the requested time
+	LookupNearestBefore
+	// LookupNearest uses the rate closest to the requested time, before or after it.
+	// When two rates are equally close, the one before is used.
+	LookupNearest
+)
+
+const (
+	// DefaultMaxStaleness is used by nearest lookups when ConversionService.MaxStaleness is not set
+	DefaultMaxStaleness = 24 * time.Hour
+	// DefaultMaxRateSkew is used by triangulation when ConversionService.MaxRateSkew is not set
+	DefaultMaxRateSkew = time.Hour
+
+	invertedRatePrecision = 16
+)
+
+func ConvertToLookupPolicyFromString(str string) (LookupPolicy, error) {
+	switch strings.ToLower(str) {
+	case "", "startofday":
+		return LookupStartOfDay, nil
+	case "nearestbefore":
+		return LookupNearestBefore, nil
+	case "nearest":
+		return LookupNearest, nil
+	}
+
+	return LookupStartOfDay, fmt.Errorf("value %s is not valid lookup policy", str)
+}
+
+type rateLookup struct {
+	policy       LookupPolicy
+	maxStaleness time.Duration
+	pivot        string
+	maxRateSkew  time.Duration
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
+
+// resolve returns the rate for from_to together with the stored rates it is derived from.
+// When the pair is not stored, the opposite pair is inverted, and when neither is stored
+// the rate is triangulated through the pivot currency using rates from the same provider.
+func (l rateLookup) resolve(storage currencyFetcher.Storage, from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, []currencyFetcher.CurrencyWithID, error) {
+	rate, leg, err := l.direct(storage, from, to, provider, date)
+
+	if err == nil {
+		return rate, []currencyFetcher.CurrencyWithID{leg}, nil
+	}
+
+	if !errors.Is(err, ErrCurrencyNotFound) || l.pivot == "" || from == l.pivot || to == l.pivot {
+		return currencyFetcher.CurrencyWithID{}, nil, err
+	}
+
+	first, firstLeg, err := l.direct(storage, from, l.pivot, provider, date)
+
+	if err != nil {
+		return currencyFetcher.CurrencyWithID{}, nil, err
+	}
+
+	// Both legs have to come from the same provider
+	second, secondLeg, err := l.direct(storage, l.pivot, to, first.Provider, date)
+
+	if err != nil {
+		return currencyFetcher.CurrencyWithID{}, nil, err
+	}
+
+	if absDuration(first.CreatedAt.Sub(second.CreatedAt)) > l.rateSkew() {
+		return currencyFetcher.CurrencyWithID{}, nil, ErrCurrencyNotFound
+	}
+
+	createdAt := first.CreatedAt
+
+	if second.CreatedAt.Before(createdAt) {
+		createdAt = second.CreatedAt
+	}
+
+	rate = currencyFetcher.CurrencyWithID{
+		Currency: currencyFetcher.Currency{
+			From:      from,
+			To:        to,
+			Provider:  first.Provider,
+			Rate:      first.Rate.Mul(second.Rate),
+			CreatedAt: createdAt,
+		},
+	}
+
+	return rate, []currencyFetcher.CurrencyWithID{firstLeg, secondLeg}, nil
+}
+
+// direct returns the stored from_to rate, or the inverted to_from rate.
+// The second value is the stored rate used.
+func (l rateLookup) direct(storage currencyFetcher.Storage, from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, currencyFetcher.CurrencyWithID, error) {
+	rate, err := l.find(storage, from, to, provider, date)
+
+	if err == nil {
+		return rate, rate, nil
+	}
+
+	if !errors.Is(err, ErrCurrencyNotFound) {
+		return currencyFetcher.CurrencyWithID{}, currencyFetcher.CurrencyWithID{}, err
+	}
+
+	opposite, err := l.find(storage, to, from, provider, date)
+
+	if err != nil {
+		return currencyFetcher.CurrencyWithID{}, currencyFetcher.CurrencyWithID{}, err
+	}
+
+	if opposite.Rate.IsZero() {
+		return currencyFetcher.CurrencyWithID{}, currencyFetcher.CurrencyWithID{}, ErrCurrencyNotFound
+	}
+
+	rate = currencyFetcher.CurrencyWithID{
+		Currency: currencyFetcher.Currency{
+			From:      from,
+			To:        to,
+			Provider:  opposite.Provider,
+			Rate:      decimal.NewFromInt(1).DivRound(opposite.Rate, invertedRatePrecision),
+			CreatedAt: opposite.CreatedAt,
+		},
+	}
+
+	return rate, opposite, nil
+}
+
+// find returns the latest rate when date is zero, otherwise the rate selected by the policy
+func (l rateLookup) find(storage currencyFetcher.Storage, from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, error) {
+	if date.IsZero() {
+		rate, err := currencyFetcher.LatestRate(storage, from, to, provider)
+
+		if errors.Is(err, currencyFetcher.ErrNotFound) {
+			return rate, ErrCurrencyNotFound
+		}
+
+		return rate, err
+	}
+
+	switch l.policy {
+	case LookupNearestBefore:
+		return l.nearest(storage, from, to, provider, date, date.Add(-l.staleness()), date)
+	case LookupNearest:
+		return l.nearest(storage, from, to, provider, date, date.Add(-l.staleness()), date.Add(l.staleness()))
+	default:
+		return l.startOfDay(storage, from, to, provider, date)
+	}
+}
+
+func (l rateLookup) staleness() time.Duration {
+	if l.maxStaleness <= 0 {
+		return DefaultMaxStaleness
+	}
+
+	return l.maxStaleness
+}
+
+func (l rateLookup) rateSkew() time.Duration {
+	if l.maxRateSkew <= 0 {
+		return DefaultMaxRateSkew
+	}
+
+	return l.maxRateSkew
+}
+
+func (l rateLookup) startOfDay(storage currencyFetcher.Storage, from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, error) {
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	currencies, err := storage.GetByDateAndProvider(from, to, provider, startOfDay, date, 1, 1)
+
+	if err != nil {
+		return currencyFetcher.CurrencyWithID{}, err
+	}
+
+	if len(currencies) == 0 {
+		return currencyFetcher.CurrencyWithID{}, ErrCurrencyNotFound
+	}
+
+	return currencies[0], nil
+}
+
+// nearest returns the rate stored between start and end closest to date, preferring the rate before it.
+// Only the last rate at or before date and the first rate after it are read.
+func (l rateLookup) nearest(
+	storage currencyFetcher.Storage,
+	from, to string,
+	provider currencyFetcher.Provider,
+	date, start, end time.Time,
+) (currencyFetcher.CurrencyWithID, error) {
+	before, err := currencyFetcher.LatestRateBefore(storage, from, to, provider, date)
+
+	if err != nil && !errors.Is(err, currencyFetcher.ErrNotFound) {
+		return currencyFetcher.CurrencyWithID{}, err
+	}
+
+	found := err == nil && !before.CreatedAt.Before(start)
+
+	if !end.After(date) {
+		if !found {
+			return currencyFetcher.CurrencyWithID{}, ErrCurrencyNotFound
+		}
+
+		return before, nil
+	}
+
+	// Rates are sorted ascending, the first one is the nearest after date
+	after, err := storage.GetByDateAndProvider(from, to, provider, date, end, 1, 1)
+
+	if err != nil {
+		return currencyFetcher.CurrencyWithID{}, err
+	}
+
+	if len(after) != 0 && (!found || after[0].CreatedAt.Sub(date) < date.Sub(before.CreatedAt)) {
+		return after[0], nil
+	}
+
+	if !found {
+		return currencyFetcher.CurrencyWithID{}, ErrCurrencyNotFound
+	}
+
+	return before, nil
+}
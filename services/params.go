@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	currencyFetcher "github.com/malusev998/currency"
+)
+
+var (
+	ErrUnknownStorage  = errors.New("unknown storage")
+	ErrMissingPair     = errors.New("from and to are required")
+	ErrInvalidProvider = errors.New("invalid provider")
+)
+
+// FindStorage returns the storage with the name, compared case insensitively,
+// the first storage when name is empty
+func FindStorage(storages []currencyFetcher.Storage, name string) (currencyFetcher.Storage, error) {
+	if len(storages) == 0 {
+		return nil, ErrNoStorageProvided
+	}
+
+	if name == "" {
+		return storages[0], nil
+	}
+
+	for _, storage := range storages {
+		if strings.EqualFold(storage.GetStorageProviderName(), name) {
+			return storage, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownStorage, name)
+}
+
+// ParsePair returns upper cased currency codes, both have to be set
+func ParsePair(from, to string) (string, string, error) {
+	if from == "" || to == "" {
+		return "", "", ErrMissingPair
+	}
+
+	return strings.ToUpper(from), strings.ToUpper(to), nil
+}
+
+// ParseProvider returns currencyFetcher.EmptyProvider, matching every provider, when value is empty
+func ParseProvider(value string) (currencyFetcher.Provider, error) {
+	if value == "" {
+		return currencyFetcher.EmptyProvider, nil
+	}
+
+	provider, err := currencyFetcher.ConvertToProviderFromString(value)
+
+	if err != nil {
+		return currencyFetcher.EmptyProvider, fmt.Errorf("%w: %v", ErrInvalidProvider, err)
+	}
+
+	return provider, nil
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/storage"
+)
+
+func TestFindStorage(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+
+	_, err := FindStorage(nil, "")
+	asserts.True(errors.Is(err, ErrNoStorageProvided))
+
+	memory, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+	asserts.Nil(err)
+
+	storages := []currencyFetcher.Storage{&MockStorage{}, memory}
+
+	found, err := FindStorage(storages, "")
+	asserts.Nil(err)
+	asserts.Equal(storages[0], found)
+
+	found, err = FindStorage(storages, "Memory")
+	asserts.Nil(err)
+	asserts.Equal(memory, found)
+
+	_, err = FindStorage(storages, "redis")
+	asserts.True(errors.Is(err, ErrUnknownStorage))
+}
+
+func TestParsePairAndProvider(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+
+	from, to, err := ParsePair("eur", "Rsd")
+	asserts.Nil(err)
+	asserts.Equal("EUR", from)
+	asserts.Equal("RSD", to)
+
+	_, _, err = ParsePair("EUR", "")
+	asserts.True(errors.Is(err, ErrMissingPair))
+
+	provider, err := ParseProvider("")
+	asserts.Nil(err)
+	asserts.Equal(currencyFetcher.EmptyProvider, provider)
+
+	provider, err = ParseProvider("NBS")
+	asserts.Nil(err)
+	asserts.Equal(currencyFetcher.NBSProvider, provider)
+
+	_, err = ParseProvider("bank")
+	asserts.True(errors.Is(err, ErrInvalidProvider))
+}
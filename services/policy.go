@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	currencyFetcher "github.com/malusev998/currency"
+)
+
+const (
+	// SaveAll requires every storage to store rates
+	SaveAll SavePolicy = "all"
+	// SaveAtLeastOne requires one storage to store rates
+	SaveAtLeastOne SavePolicy = "atleastone"
+	// SaveQuorum requires more than half of storages to store rates
+	SaveQuorum SavePolicy = "quorum"
+)
+
+var ErrUnknownSavePolicy = errors.New("unknown save policy")
+
+type (
+	SavePolicy string
+
+	// SaveError is returned when storages failed to store rates and the policy is not met
+	SaveError struct {
+		Policy SavePolicy
+		// Storages is the number of configured storages, Failed holds the ones that did not store rates
+		Storages int
+		Failed   []currencyFetcher.StorageResult
+	}
+)
+
+// ConvertToSavePolicyFromString converts the case insensitive name of a policy, SaveAll is returned for empty names
+func ConvertToSavePolicyFromString(policy string) (SavePolicy, error) {
+	switch p := SavePolicy(strings.ToLower(strings.ReplaceAll(policy, "-", ""))); p {
+	case "":
+		return SaveAll, nil
+	case SaveAll, SaveAtLeastOne, SaveQuorum:
+		return p, nil
+	}
+
+	return "", fmt.Errorf("%w %q, expected %s, %s or %s", ErrUnknownSavePolicy, policy, SaveAll, SaveAtLeastOne, SaveQuorum)
+}
+
+// Met reports whether storing rates in succeeded of total storages is enough
+func (p SavePolicy) Met(succeeded, total int) bool {
+	switch p {
+	case SaveAtLeastOne:
+		return succeeded > 0 || total == 0
+	case SaveQuorum:
+		return succeeded > total/2 || total == 0
+	}
+
+	return succeeded == total
+}
+
+// check returns a *SaveError when the result does not meet the policy
+func (p SavePolicy) check(result currencyFetcher.SaveResult) error {
+	failed := result.Failed()
+
+	if len(failed) == 0 || p.Met(len(result.Storages)-len(failed), len(result.Storages)) {
+		return nil
+	}
+
+	if p == "" {
+		p = SaveAll
+	}
+
+	return &SaveError{Policy: p, Storages: len(result.Storages), Failed: failed}
+}
+
+func (e *SaveError) Error() string {
+	messages := make([]string, 0, len(e.Failed))
+
+	for _, f := range e.Failed {
+		messages = append(messages, fmt.Sprintf("%s: %v", f.Storage, f.Err))
+	}
+
+	return fmt.Sprintf("%d of %d storages failed (policy %s): %s", len(e.Failed), e.Storages, e.Policy, strings.Join(messages, "; "))
+}
+
+// Is reports whether the error of any failed storage matches target
+func (e *SaveError) Is(target error) bool {
+	for _, f := range e.Failed {
+		if errors.Is(f.Err, target) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	currencyFetcher "github.com/malusev998/currency"
+)
+
+func TestConvertToSavePolicyFromString(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+
+	for value, expected := range map[string]SavePolicy{
+		"":             SaveAll,
+		"all":          SaveAll,
+		"atLeastOne":   SaveAtLeastOne,
+		"at-least-one": SaveAtLeastOne,
+		"QUORUM":       SaveQuorum,
+	} {
+		policy, err := ConvertToSavePolicyFromString(value)
+		asserts.Nil(err)
+		asserts.Equal(expected, policy)
+	}
+
+	_, err := ConvertToSavePolicyFromString("most")
+	asserts.True(errors.Is(err, ErrUnknownSavePolicy))
+}
+
+func TestSavePolicy_Met(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		policy           SavePolicy
+		succeeded, total int
+		expected         bool
+	}{
+		{SaveAll, 2, 2, true},
+		{SaveAll, 1, 2, false},
+		{"", 1, 2, false},
+		{SaveAtLeastOne, 1, 3, true},
+		{SaveAtLeastOne, 0, 3, false},
+		{SaveQuorum, 2, 3, true},
+		{SaveQuorum, 1, 2, false},
+		{SaveQuorum, 1, 3, false},
+		{SaveQuorum, 0, 0, true},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.expected, test.policy.Met(test.succeeded, test.total), "%s %d of %d", test.policy, test.succeeded, test.total)
+	}
+}
+
+func TestSaveError(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+
+	err := SaveAll.check(currencyFetcher.SaveResult{Storages: []currencyFetcher.StorageResult{
+		{Storage: "mysql", Err: context.DeadlineExceeded},
+		{Storage: "mongodb", Currencies: []currencyFetcher.CurrencyWithID{}},
+		{Storage: "postgres", Err: errors.New("connection refused")},
+	}})
+
+	var saveErr *SaveError
+
+	asserts.True(errors.As(err, &saveErr))
+	asserts.Len(saveErr.Failed, 2)
+	asserts.Equal("2 of 3 storages failed (policy all): mysql: context deadline exceeded; postgres: connection refused", err.Error())
+	asserts.True(errors.Is(err, context.DeadlineExceeded))
+	asserts.False(errors.Is(err, context.Canceled))
+
+	asserts.NotNil(SaveAtLeastOne.check(currencyFetcher.SaveResult{Storages: saveErr.Failed[:1]}))
+	asserts.Nil(SaveAll.check(currencyFetcher.SaveResult{}))
+}
@@ -0,0 +1,307 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/iso4217"
+)
+
+const DefaultReconcilePageSize = 1000
+
+var ErrNoPairsToReconcile = errors.New("storages cannot list stored pairs, pairs have to be set")
+
+type (
+	// RateDifference holds the same rate, matched by pair, provider and CreatedAt,
+	// stored with a different value in each storage
+	RateDifference struct {
+		Left  currencyFetcher.CurrencyWithID
+		Right currencyFetcher.CurrencyWithID
+	}
+
+	ReconcileReport struct {
+		Pairs []string
+		// Matched is the number of rates stored with the same value in both storages
+		Matched int
+		// MissingLeft holds rates stored only in the right storage
+		MissingLeft []currencyFetcher.CurrencyWithID
+		// MissingRight holds rates stored only in the left storage
+		MissingRight []currencyFetcher.CurrencyWithID
+		Different    []RateDifference
+		// CopiedLeft and CopiedRight are the numbers of missing rates copied by Repair
+		CopiedLeft  int
+		CopiedRight int
+	}
+
+	// Reconciler compares rates of two storages, e.g. after Service.Save failed on one of them.
+	// Rates are matched by pair, provider and CreatedAt truncated to seconds, IDs differ between storages.
+	// Rates created in the same second are matched by value first.
+	Reconciler struct {
+		Left  currencyFetcher.Storage
+		Right currencyFetcher.Storage
+		// Pairs to compare, e.g. EUR_RSD, every pair stored in either storage when empty
+		// if the storages implement currencyFetcher.PairLister
+		Pairs []string
+		// Provider limits compared rates to one provider, every provider when empty
+		Provider currencyFetcher.Provider
+		// PageSize is the number of rates read per query, DefaultReconcilePageSize when zero
+		PageSize int64
+		// Repair copies missing rates to the other storage, rates with different values are only reported
+		Repair bool
+	}
+)
+
+// Consistent reports whether both storages hold the same rates, copied rates included
+func (r ReconcileReport) Consistent() bool {
+	return len(r.Different) == 0 &&
+		len(r.MissingLeft) == r.CopiedLeft &&
+		len(r.MissingRight) == r.CopiedRight
+}
+
+func rateKey(c currencyFetcher.CurrencyWithID) string {
+	return fmt.Sprintf("%s_%s_%s_%d", c.From, c.To, c.Provider, c.CreatedAt.Unix())
+}
+
+func storedPairs(s currencyFetcher.Storage) ([]string, bool, error) {
+	lister, ok := s.(currencyFetcher.PairLister)
+
+	if !ok {
+		lister, ok = currencyFetcher.UnwrapStorage(s).(currencyFetcher.PairLister)
+	}
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	pairs, err := lister.Pairs()
+
+	return pairs, true, err
+}
+
+// pairs returns configured pairs or pairs stored in either storage
+func (r *Reconciler) pairs() ([]string, error) {
+	if len(r.Pairs) != 0 {
+		return r.Pairs, nil
+	}
+
+	set := make(map[string]struct{})
+
+	for _, s := range []currencyFetcher.Storage{r.Left, r.Right} {
+		pairs, ok, err := storedPairs(s)
+
+		if err != nil {
+			return nil, fmt.Errorf("error while listing pairs of %s: %w", s.GetStorageProviderName(), err)
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNoPairsToReconcile, s.GetStorageProviderName())
+		}
+
+		for _, pair := range pairs {
+			set[pair] = struct{}{}
+		}
+	}
+
+	pairs := make([]string, 0, len(set))
+
+	for pair := range set {
+		pairs = append(pairs, pair)
+	}
+
+	sort.Strings(pairs)
+
+	return pairs, nil
+}
+
+// read returns rates of the pair stored in s between start and end grouped by rateKey
+func (r *Reconciler) read(s currencyFetcher.Storage, pair iso4217.Pair, start, end time.Time) (map[string][]currencyFetcher.CurrencyWithID, error) {
+	rates := make(map[string][]currencyFetcher.CurrencyWithID)
+
+	for page := int64(1); ; page++ {
+		result, err := s.GetByDateAndProvider(pair.From, pair.To, r.Provider, start, end, page, r.PageSize)
+
+		if err != nil {
+			return nil, fmt.Errorf("error while reading %s rates from %s: %w", pair, s.GetStorageProviderName(), err)
+		}
+
+		for _, c := range result {
+			key := rateKey(c)
+			rates[key] = append(rates[key], c)
+		}
+
+		if int64(len(result)) < r.PageSize {
+			return rates, nil
+		}
+	}
+}
+
+// match counts rates of one key stored with the same value in both storages and returns the rest,
+// storages order rates created in the same second by their own IDs
+func matchRates(report *ReconcileReport, left, right []currencyFetcher.CurrencyWithID) ([]currencyFetcher.CurrencyWithID, []currencyFetcher.CurrencyWithID) {
+	matched := make([]bool, len(right))
+	unmatched := make([]currencyFetcher.CurrencyWithID, 0, len(left))
+
+	for _, c := range left {
+		found := false
+
+		for i, other := range right {
+			if !matched[i] && c.Rate.Equal(other.Rate) {
+				matched[i], found = true, true
+				break
+			}
+		}
+
+		if found {
+			report.Matched++
+			continue
+		}
+
+		unmatched = append(unmatched, c)
+	}
+
+	rest := make([]currencyFetcher.CurrencyWithID, 0, len(right))
+
+	for i, c := range right {
+		if !matched[i] {
+			rest = append(rest, c)
+		}
+	}
+
+	return unmatched, rest
+}
+
+func (r *Reconciler) compare(report *ReconcileReport, pair iso4217.Pair, start, end time.Time) error {
+	left, err := r.read(r.Left, pair, start, end)
+
+	if err != nil {
+		return err
+	}
+
+	right, err := r.read(r.Right, pair, start, end)
+
+	if err != nil {
+		return err
+	}
+
+	for key, leftRates := range left {
+		leftRates, rightRates := matchRates(report, leftRates, right[key])
+
+		for i, c := range leftRates {
+			if i >= len(rightRates) {
+				report.MissingRight = append(report.MissingRight, c)
+				continue
+			}
+
+			report.Different = append(report.Different, RateDifference{Left: c, Right: rightRates[i]})
+		}
+
+		if len(rightRates) > len(leftRates) {
+			report.MissingLeft = append(report.MissingLeft, rightRates[len(leftRates):]...)
+		}
+	}
+
+	for key, rightRates := range right {
+		if _, ok := left[key]; !ok {
+			report.MissingLeft = append(report.MissingLeft, rightRates...)
+		}
+	}
+
+	return nil
+}
+
+// copy stores rates in s in batches of PageSize, it returns the number of copied rates
+func (r *Reconciler) copy(s currencyFetcher.Storage, rates []currencyFetcher.CurrencyWithID) (int, error) {
+	copied := 0
+
+	for copied < len(rates) {
+		end := copied + int(r.PageSize)
+
+		if end > len(rates) {
+			end = len(rates)
+		}
+
+		if _, err := currencyFetcher.CopyRates(s, rates[copied:end]); err != nil {
+			return copied, fmt.Errorf("error while copying rates to %s: %w", s.GetStorageProviderName(), err)
+		}
+
+		copied = end
+	}
+
+	return copied, nil
+}
+
+// rateLess orders rates by pair, CreatedAt and provider
+func rateLess(a, b currencyFetcher.CurrencyWithID) bool {
+	if a.From+a.To != b.From+b.To {
+		return a.From+a.To < b.From+b.To
+	}
+
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+
+	return a.Provider < b.Provider
+}
+
+// Reconcile compares rates stored between start and end, both inclusive.
+// With Repair set missing rates are copied, the report holds rates as they were before copying.
+func (r *Reconciler) Reconcile(start, end time.Time) (ReconcileReport, error) {
+	report := ReconcileReport{
+		MissingLeft:  make([]currencyFetcher.CurrencyWithID, 0),
+		MissingRight: make([]currencyFetcher.CurrencyWithID, 0),
+		Different:    make([]RateDifference, 0),
+	}
+
+	if r.Left == nil || r.Right == nil {
+		return report, ErrNoStorageProvided
+	}
+
+	if r.PageSize <= 0 {
+		r.PageSize = DefaultReconcilePageSize
+	}
+
+	pairs, err := r.pairs()
+
+	if err != nil {
+		return report, err
+	}
+
+	report.Pairs = pairs
+
+	for _, value := range pairs {
+		pair, err := iso4217.SplitPair(value)
+
+		if err != nil {
+			return report, err
+		}
+
+		if err := r.compare(&report, pair, start, end); err != nil {
+			return report, err
+		}
+	}
+
+	sort.SliceStable(report.MissingLeft, func(i, j int) bool {
+		return rateLess(report.MissingLeft[i], report.MissingLeft[j])
+	})
+	sort.SliceStable(report.MissingRight, func(i, j int) bool {
+		return rateLess(report.MissingRight[i], report.MissingRight[j])
+	})
+	sort.SliceStable(report.Different, func(i, j int) bool {
+		return rateLess(report.Different[i].Left, report.Different[j].Left)
+	})
+
+	if !r.Repair {
+		return report, nil
+	}
+
+	if report.CopiedLeft, err = r.copy(r.Left, report.MissingLeft); err != nil {
+		return report, err
+	}
+
+	report.CopiedRight, err = r.copy(r.Right, report.MissingRight)
+
+	return report, err
+}
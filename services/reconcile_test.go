@@ -0,0 +1,149 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/storage"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2020, 10, 1, 14, 0, 0, 0, time.UTC)
+	rate := func(from, to string, provider currencyFetcher.Provider, value string, day int) currencyFetcher.Currency {
+		return currencyFetcher.Currency{From: from, To: to, Provider: provider, Rate: decimal.RequireFromString(value), CreatedAt: start.AddDate(0, 0, day)}
+	}
+
+	newStorages := func(t *testing.T) (currencyFetcher.Storage, currencyFetcher.Storage) {
+		left, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+		require.Nil(t, err)
+		right, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+		require.Nil(t, err)
+
+		both := []currencyFetcher.Currency{
+			rate("EUR", "RSD", currencyFetcher.NBSProvider, "117.5", 0),
+			rate("EUR", "RSD", currencyFetcher.NBSProvider, "117.6", 1),
+			rate("EUR", "USD", currencyFetcher.ECBProvider, "1.17", 0),
+		}
+
+		_, err = left.Store(append(both,
+			rate("EUR", "RSD", currencyFetcher.NBSProvider, "117.7", 2),
+			rate("EUR", "USD", currencyFetcher.ECBProvider, "1.18", 1),
+		))
+		require.Nil(t, err)
+
+		_, err = right.Store(append(both,
+			rate("EUR", "USD", currencyFetcher.ECBProvider, "1.19", 1),
+			rate("USD", "EUR", currencyFetcher.ECBProvider, "0.85", 1),
+			rate("EUR", "RSD", currencyFetcher.NBSProvider, "117.8", 10),
+		))
+		require.Nil(t, err)
+
+		return left, right
+	}
+
+	t.Run("Report", func(t *testing.T) {
+		t.Parallel()
+		asserts := require.New(t)
+		left, right := newStorages(t)
+
+		reconciler := Reconciler{Left: left, Right: right, PageSize: 2}
+		report, err := reconciler.Reconcile(start, start.AddDate(0, 0, 5))
+
+		asserts.Nil(err)
+		asserts.Equal([]string{"EUR_RSD", "EUR_USD", "USD_EUR"}, report.Pairs)
+		asserts.Equal(3, report.Matched)
+		asserts.False(report.Consistent())
+
+		asserts.Len(report.MissingRight, 1)
+		asserts.Equal("117.7", report.MissingRight[0].Rate.String())
+
+		// The rate stored after the end date is not compared
+		asserts.Len(report.MissingLeft, 1)
+		asserts.Equal("USD", report.MissingLeft[0].From)
+
+		asserts.Len(report.Different, 1)
+		asserts.Equal("1.18", report.Different[0].Left.Rate.String())
+		asserts.Equal("1.19", report.Different[0].Right.Rate.String())
+
+		asserts.Zero(report.CopiedLeft)
+		asserts.Zero(report.CopiedRight)
+	})
+
+	t.Run("Repair", func(t *testing.T) {
+		t.Parallel()
+		asserts := require.New(t)
+		left, right := newStorages(t)
+
+		reconciler := Reconciler{Left: left, Right: right, Pairs: []string{"EUR_RSD", "USD_EUR"}, Repair: true}
+		report, err := reconciler.Reconcile(start, start.AddDate(0, 0, 20))
+
+		asserts.Nil(err)
+		asserts.Equal(2, report.CopiedLeft)
+		asserts.Equal(1, report.CopiedRight)
+		asserts.True(report.Consistent())
+
+		stored, err := right.GetByDate("EUR", "RSD", start.AddDate(0, 0, 2), start.AddDate(0, 0, 2), 1, 10)
+		asserts.Nil(err)
+		asserts.Len(stored, 1)
+		asserts.Equal(report.MissingRight[0].ID, stored[0].ID)
+
+		report, err = reconciler.Reconcile(start, start.AddDate(0, 0, 20))
+		asserts.Nil(err)
+		asserts.Empty(report.MissingLeft)
+		asserts.Empty(report.MissingRight)
+		asserts.Equal(5, report.Matched)
+	})
+
+	t.Run("Provider", func(t *testing.T) {
+		t.Parallel()
+		asserts := require.New(t)
+		left, right := newStorages(t)
+
+		reconciler := Reconciler{Left: left, Right: right, Provider: currencyFetcher.NBSProvider}
+		report, err := reconciler.Reconcile(start, start.AddDate(0, 0, 5))
+
+		asserts.Nil(err)
+		asserts.Equal(2, report.Matched)
+		asserts.Empty(report.Different)
+		asserts.Empty(report.MissingLeft)
+		asserts.Len(report.MissingRight, 1)
+	})
+
+	t.Run("SameSecond", func(t *testing.T) {
+		t.Parallel()
+		asserts := require.New(t)
+		left, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+		asserts.Nil(err)
+		right, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+		asserts.Nil(err)
+
+		first := rate("EUR", "RSD", currencyFetcher.NBSProvider, "117.5", 0)
+		second := rate("EUR", "RSD", currencyFetcher.NBSProvider, "117.6", 0)
+
+		_, err = left.Store([]currencyFetcher.Currency{first, second})
+		asserts.Nil(err)
+		// Rates created at the same time are returned in a different order
+		_, err = right.Store([]currencyFetcher.Currency{second, first})
+		asserts.Nil(err)
+
+		report, err := (&Reconciler{Left: left, Right: right}).Reconcile(start, start.AddDate(0, 0, 1))
+
+		asserts.Nil(err)
+		asserts.Equal(2, report.Matched)
+		asserts.True(report.Consistent())
+	})
+
+	t.Run("NoStorage", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := (&Reconciler{}).Reconcile(start, start)
+		require.True(t, errors.Is(err, ErrNoStorageProvided))
+	})
+}
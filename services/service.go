@@ -1,74 +1,109 @@
 package services
 
 import (
+	"errors"
 	"sync"
+	"time"
 
 	currencyFetcher "github.com/malusev998/currency"
 )
 
+var ErrHistoricalNotSupported = errors.New("fetcher does not support fetching historical rates")
+
 type Service struct {
 	Fetcher currencyFetcher.Fetcher
 	Storage []currencyFetcher.Storage
-}
-
-
-type currencyCh struct {
-	StorageName string
-	Currency []currencyFetcher.CurrencyWithID
+	// Validator filters fetched rates before they are stored, all rates are stored when nil
+	Validator *Validator
+	// Policy decides how many storages have to store rates for Save to succeed, SaveAll when empty
+	Policy SavePolicy
 }
 
 func saveToStorage(
 	wg *sync.WaitGroup,
 	currencies []currencyFetcher.Currency,
 	storage currencyFetcher.Storage,
-	cs chan<- currencyCh,
-	errorChannel chan<- error,
+	result *currencyFetcher.StorageResult,
 ) {
 	defer wg.Done()
-	c, err := storage.Store(currencies)
 
+	result.Storage = storage.GetStorageProviderName()
+	result.Currencies, result.Err = storage.Store(currencies)
+}
+
+// Save fetches and stores rates. Rates stored by storages that succeeded are returned
+// together with a *SaveError when Policy is not met.
+func (f Service) Save(currenciesToFetch []string) (map[string][]currencyFetcher.CurrencyWithID, error) {
+	return saved(f.SaveWithResult(currenciesToFetch))
+}
+
+// SaveWithResult fetches and stores rates, the result holds rates or an error of every storage.
+// The error is the fetch error or a *SaveError when Policy is not met.
+func (f Service) SaveWithResult(currenciesToFetch []string) (currencyFetcher.SaveResult, error) {
+	fetchedCurrencies, err := f.Fetcher.Fetch(currenciesToFetch)
 	if err != nil {
-		errorChannel <- err
-		return
+		return currencyFetcher.SaveResult{}, err
 	}
 
-	cs <- currencyCh{
-		StorageName: storage.GetStorageProviderName(),
-		Currency: c,
-	}
+	return f.store(fetchedCurrencies)
 }
 
-func (f Service) Save(currenciesToFetch []string) (map[string][]currencyFetcher.CurrencyWithID, error) {
-	var wg sync.WaitGroup
-	fetchedCurrencies, err := f.Fetcher.Fetch(currenciesToFetch)
+// SaveByDateRange fetches rates published between start and end and stores them
+// with the date the provider published them for. Fetcher has to implement
+// currencyFetcher.HistoricalFetcher, otherwise ErrHistoricalNotSupported is returned.
+func (f Service) SaveByDateRange(currenciesToFetch []string, start, end time.Time) (map[string][]currencyFetcher.CurrencyWithID, error) {
+	fetcher, ok := f.Fetcher.(currencyFetcher.HistoricalFetcher)
+
+	if !ok {
+		return nil, ErrHistoricalNotSupported
+	}
+
+	fetchedCurrencies, err := fetcher.FetchByDateRange(currenciesToFetch, start, end)
 	if err != nil {
 		return nil, err
 	}
 
-	cs := make(chan currencyCh, len(currenciesToFetch))
-	errorChannel := make(chan error)
-	data := make(map[string][]currencyFetcher.CurrencyWithID)
+	return saved(f.store(fetchedCurrencies))
+}
 
-	wg.Add(len(f.Storage))
+// saved returns rates of storages that succeeded, nil when none did and there is an error
+func saved(result currencyFetcher.SaveResult, err error) (map[string][]currencyFetcher.CurrencyWithID, error) {
+	data := result.Currencies()
 
-	for _, storage := range f.Storage {
-		go saveToStorage(&wg, fetchedCurrencies, storage, cs, errorChannel)
+	if err != nil && len(data) == 0 {
+		return nil, err
 	}
 
-	go func(wg *sync.WaitGroup, cs chan currencyCh, errorChannel chan error) {
-		wg.Wait()
-		close(errorChannel)
-		close(cs)
-	}(&wg, cs, errorChannel)
+	return data, err
+}
+
+func (f Service) store(fetchedCurrencies []currencyFetcher.Currency) (currencyFetcher.SaveResult, error) {
+	var wg sync.WaitGroup
 
+	if f.Validator != nil {
+		accepted, _, err := f.Validator.Validate(fetchedCurrencies)
 
-	for item := range cs {
-		data[item.StorageName] = item.Currency
+		if err != nil {
+			return currencyFetcher.SaveResult{}, err
+		}
+
+		if len(accepted) == 0 && len(fetchedCurrencies) != 0 {
+			return currencyFetcher.SaveResult{}, nil
+		}
+
+		fetchedCurrencies = accepted
 	}
 
-	if err, more := <-errorChannel; more {
-		return nil, err
+	// Every goroutine writes its own result, results keep the order of storages
+	result := currencyFetcher.SaveResult{Storages: make([]currencyFetcher.StorageResult, len(f.Storage))}
+
+	wg.Add(len(f.Storage))
+
+	for i, storage := range f.Storage {
+		go saveToStorage(&wg, fetchedCurrencies, storage, &result.Storages[i])
 	}
 
-	return data, nil
+	wg.Wait()
+
+	return result, f.Policy.check(result)
 }
@@ -7,10 +7,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/storage"
 )
 
 type (
@@ -18,11 +20,32 @@ type (
 		mock.Mock
 	}
 
+	MockHistoricalFetcher struct {
+		MockFetcher
+	}
+
 	MockStorage struct {
 		mock.Mock
 	}
 )
 
+func (m *MockHistoricalFetcher) FetchByDate(currenciesToFetch []string, date time.Time) ([]currencyFetcher.Currency, error) {
+	args := m.Called(currenciesToFetch, date)
+
+	return args.Get(0).([]currencyFetcher.Currency), args.Error(1)
+}
+
+func (m *MockHistoricalFetcher) FetchByDateRange(currenciesToFetch []string, start, end time.Time) ([]currencyFetcher.Currency, error) {
+	args := m.Called(currenciesToFetch, start, end)
+	return1 := args.Get(0)
+
+	if return1 == nil {
+		return nil, args.Error(1)
+	}
+
+	return return1.([]currencyFetcher.Currency), args.Error(1)
+}
+
 func (m *MockStorage) Store(currencies []currencyFetcher.Currency) ([]currencyFetcher.CurrencyWithID, error) {
 	args := m.Called(currencies)
 
@@ -58,6 +81,18 @@ func (m *MockStorage) GetByDateAndProvider(from, to string, provider currencyFet
 	return args.Get(0).([]currencyFetcher.CurrencyWithID), args.Error(1)
 }
 
+func (m *MockStorage) Latest(from, to string, provider currencyFetcher.Provider) (currencyFetcher.CurrencyWithID, error) {
+	args := m.Called(from, to, provider)
+
+	return args.Get(0).(currencyFetcher.CurrencyWithID), args.Error(1)
+}
+
+func (m *MockStorage) LatestBefore(from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, error) {
+	args := m.Called(from, to, provider, date)
+
+	return args.Get(0).(currencyFetcher.CurrencyWithID), args.Error(1)
+}
+
 func (m *MockStorage) GetStorageProviderName() string {
 	return "MockStorage"
 }
@@ -93,7 +128,7 @@ func TestFreeConvService(t *testing.T) {
 	currenciesFetched := make([]currencyFetcher.Currency, 0, len(currenciesToFetch))
 	for i, c := range currenciesToFetch {
 		isoCurrencies := strings.Split(c, "_")
-		rate := rand.Float32()
+		rate := decimal.NewFromFloat32(rand.Float32())
 		currenciesWithId = append(currenciesWithId, currencyFetcher.CurrencyWithID{
 			Currency: currencyFetcher.Currency{
 				From:      isoCurrencies[0],
@@ -164,3 +199,129 @@ func TestFreeConvService(t *testing.T) {
 		asserts.NotNil(err)
 	})
 }
+
+func TestService_SaveByDateRange(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2020, 10, 7, 0, 0, 0, 0, time.UTC)
+	currenciesToFetch := []string{"EUR_USD"}
+
+	t.Run("StoresWithProviderDate", func(t *testing.T) {
+		asserts := require.New(t)
+		fetcher := &MockHistoricalFetcher{}
+		storage := &MockStorage{}
+		service := Service{
+			Fetcher: fetcher,
+			Storage: []currencyFetcher.Storage{storage},
+		}
+
+		fetched := []currencyFetcher.Currency{
+			{From: "EUR", To: "USD", Provider: "MockProvider", Rate: decimal.RequireFromString("1.17"), CreatedAt: start},
+		}
+		stored := []currencyFetcher.CurrencyWithID{{Currency: fetched[0], ID: uint64(1)}}
+
+		fetcher.On("FetchByDateRange", currenciesToFetch, start, end).Return(fetched, nil)
+		storage.On("Store", fetched).Return(stored, nil)
+
+		savedCurrencies, err := service.SaveByDateRange(currenciesToFetch, start, end)
+
+		asserts.Nil(err)
+		asserts.Equal(stored, savedCurrencies["MockStorage"])
+		asserts.Equal(start, savedCurrencies["MockStorage"][0].CreatedAt)
+	})
+
+	t.Run("FetcherNotHistorical", func(t *testing.T) {
+		asserts := require.New(t)
+		service := Service{
+			Fetcher: &MockFetcher{},
+			Storage: []currencyFetcher.Storage{&MockStorage{}},
+		}
+
+		savedCurrencies, err := service.SaveByDateRange(currenciesToFetch, start, end)
+
+		asserts.Nil(savedCurrencies)
+		asserts.True(errors.Is(err, ErrHistoricalNotSupported))
+	})
+}
+
+func TestService_SaveToMemoryStorage(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	fetcher := &MockFetcher{}
+	memory, _ := storage.NewMemoryStorage(storage.MemoryConfig{})
+	service := Service{
+		Fetcher: fetcher,
+		Storage: []currencyFetcher.Storage{memory},
+	}
+
+	fetcher.On("Fetch", []string{"EUR_USD"}).
+		Return([]currencyFetcher.Currency{{From: "EUR", To: "USD", Provider: "MockProvider", Rate: decimal.RequireFromString("1.17")}}, nil)
+
+	savedCurrencies, err := service.Save([]string{"EUR_USD"})
+
+	asserts.Nil(err)
+	asserts.Len(savedCurrencies[storage.MemoryStorageProviderName], 1)
+
+	stored, err := memory.GetByProvider("EUR", "USD", "MockProvider", 1, 10)
+
+	asserts.Nil(err)
+	asserts.Len(stored, 1)
+	asserts.Equal(savedCurrencies[storage.MemoryStorageProviderName][0].ID, stored[0].ID)
+}
+
+func TestService_SaveWithResult(t *testing.T) {
+	t.Parallel()
+	fetched := []currencyFetcher.Currency{{From: "EUR", To: "USD", Provider: "MockProvider", Rate: decimal.RequireFromString("1.17")}}
+	storeErr := errors.New("error while inserting into storage")
+
+	newService := func(policy SavePolicy) Service {
+		fetcher := &MockFetcher{}
+		failing := &MockStorage{}
+		memory, _ := storage.NewMemoryStorage(storage.MemoryConfig{})
+
+		fetcher.On("Fetch", []string{"EUR_USD"}).Return(fetched, nil)
+		failing.On("Store", fetched).Return(nil, storeErr)
+
+		return Service{
+			Fetcher: fetcher,
+			Storage: []currencyFetcher.Storage{failing, memory},
+			Policy:  policy,
+		}
+	}
+
+	t.Run("PolicyNotMet", func(t *testing.T) {
+		asserts := require.New(t)
+
+		result, err := newService(SaveAll).SaveWithResult([]string{"EUR_USD"})
+
+		var saveErr *SaveError
+		asserts.True(errors.As(err, &saveErr))
+		asserts.True(errors.Is(err, storeErr))
+		asserts.Equal(2, saveErr.Storages)
+		asserts.Len(saveErr.Failed, 1)
+		asserts.Equal("MockStorage", saveErr.Failed[0].Storage)
+
+		asserts.Len(result.Storages, 2)
+		asserts.Equal("MockStorage", result.Storages[0].Storage)
+		asserts.Equal(storeErr, result.Storages[0].Err)
+		asserts.Equal(storage.MemoryStorageProviderName, result.Storages[1].Storage)
+		asserts.Nil(result.Storages[1].Err)
+		asserts.Len(result.Storages[1].Currencies, 1)
+
+		// Rates stored by other storages are still returned
+		saved, err := newService(SaveQuorum).Save([]string{"EUR_USD"})
+		asserts.NotNil(err)
+		asserts.Len(saved, 1)
+		asserts.Len(saved[storage.MemoryStorageProviderName], 1)
+	})
+
+	t.Run("PolicyMet", func(t *testing.T) {
+		asserts := require.New(t)
+
+		result, err := newService(SaveAtLeastOne).SaveWithResult([]string{"EUR_USD"})
+
+		asserts.Nil(err)
+		asserts.Len(result.Failed(), 1)
+		asserts.Len(result.Currencies(), 1)
+	})
+}
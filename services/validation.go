@@ -0,0 +1,189 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/iso4217"
+)
+
+var (
+	ErrInvalidRate     = errors.New("rate has to be positive")
+	ErrUnknownCurrency = errors.New("unknown currency code")
+	ErrRateChange      = errors.New("rate moved more than allowed")
+
+	hundred = decimal.NewFromInt(100)
+)
+
+type (
+	RejectedRate struct {
+		currencyFetcher.Currency
+		// Previous is the rate compared against, zero when there was none
+		Previous decimal.Decimal
+		Err      error
+	}
+
+	// Validator rejects fetched rates before they reach the storages.
+	// Rates have to be positive and use known currency codes, with MaxChange set
+	// they are also compared to the rate of the pair and provider stored last before
+	// their CreatedAt, so historical rates are not compared to today's rate.
+	Validator struct {
+		// Storage holds the last rates, usually one of the service storages
+		Storage currencyFetcher.Storage
+		// MaxChange is the largest allowed move from the last rate in percent, zero disables the check
+		MaxChange float64
+		// Currencies are the accepted currency codes, codes in the iso4217 catalogue are accepted when empty
+		Currencies []string
+		// Quarantine stores rejected rates when set, so they can be reviewed later
+		Quarantine currencyFetcher.Storage
+		// OnReject is called for every rejected rate
+		OnReject func(RejectedRate)
+	}
+)
+
+func (r RejectedRate) Error() string {
+	if r.Previous.IsZero() {
+		return fmt.Sprintf("%s_%s from %s rejected (rate %s): %v", r.From, r.To, r.Provider, r.Rate, r.Err)
+	}
+
+	return fmt.Sprintf("%s_%s from %s rejected (rate %s, previous %s): %v", r.From, r.To, r.Provider, r.Rate, r.Previous, r.Err)
+}
+
+func (r RejectedRate) Unwrap() error {
+	return r.Err
+}
+
+func (v *Validator) knownCurrency(code string) bool {
+	if len(v.Currencies) == 0 {
+		return iso4217.IsValid(code)
+	}
+
+	for _, c := range v.Currencies {
+		if strings.EqualFold(c, code) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// previous returns the rate of the pair from the provider stored last at or before CreatedAt,
+// the latest rate when CreatedAt is not set and zero rate when there is none
+func (v *Validator) previous(c currencyFetcher.Currency) (currencyFetcher.Currency, error) {
+	var stored currencyFetcher.CurrencyWithID
+	var err error
+
+	if c.CreatedAt.IsZero() {
+		stored, err = currencyFetcher.LatestRate(v.Storage, c.From, c.To, c.Provider)
+	} else {
+		stored, err = currencyFetcher.LatestRateBefore(v.Storage, c.From, c.To, c.Provider, c.CreatedAt)
+	}
+
+	if errors.Is(err, currencyFetcher.ErrNotFound) {
+		return currencyFetcher.Currency{}, nil
+	}
+
+	if err != nil {
+		return currencyFetcher.Currency{}, err
+	}
+
+	return stored.Currency, nil
+}
+
+// Validate returns accepted rates in the order of currencies and rejected rates.
+// Rates are checked in the order of CreatedAt, a rate is compared to the previously accepted
+// rate of the same pair when it is more recent than the stored one.
+// Decimal rates cannot hold NaN, fetchers fail on such values while parsing.
+func (v *Validator) Validate(currencies []currencyFetcher.Currency) ([]currencyFetcher.Currency, []RejectedRate, error) {
+	accepted := make([]currencyFetcher.Currency, 0, len(currencies))
+	rejected := make([]RejectedRate, 0)
+	last := make(map[string]currencyFetcher.Currency)
+	isRejected := make([]bool, len(currencies))
+	order := make([]int, len(currencies))
+
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return currencies[order[i]].CreatedAt.Before(currencies[order[j]].CreatedAt)
+	})
+
+	for _, i := range order {
+		var err error
+
+		c := currencies[i]
+
+		previous := decimal.Zero
+
+		switch {
+		case !v.knownCurrency(c.From):
+			err = fmt.Errorf("%w %s", ErrUnknownCurrency, c.From)
+		case !v.knownCurrency(c.To):
+			err = fmt.Errorf("%w %s", ErrUnknownCurrency, c.To)
+		case !c.Rate.IsPositive():
+			err = ErrInvalidRate
+		case v.MaxChange > 0 && v.Storage != nil:
+			key := c.From + "_" + c.To + "_" + string(c.Provider)
+			p, previousErr := v.previous(c)
+
+			if previousErr != nil {
+				return nil, nil, previousErr
+			}
+
+			if l, ok := last[key]; ok && (p.Rate.IsZero() || !l.CreatedAt.Before(p.CreatedAt)) {
+				p = l
+			}
+
+			previous = p.Rate
+
+			if !previous.IsZero() {
+				change := c.Rate.Sub(previous).Abs().Div(previous).Mul(hundred)
+
+				if change.GreaterThan(decimal.NewFromFloat(v.MaxChange)) {
+					err = fmt.Errorf("%w: %s%% over %v%%", ErrRateChange, change.StringFixed(2), v.MaxChange)
+				}
+			}
+
+			if err == nil {
+				last[key] = c
+			}
+		}
+
+		if err != nil {
+			isRejected[i] = true
+			rejected = append(rejected, RejectedRate{Currency: c, Previous: previous, Err: err})
+		}
+	}
+
+	for i, c := range currencies {
+		if !isRejected[i] {
+			accepted = append(accepted, c)
+		}
+	}
+
+	if len(rejected) != 0 && v.Quarantine != nil {
+		quarantined := make([]currencyFetcher.Currency, 0, len(rejected))
+
+		for _, r := range rejected {
+			quarantined = append(quarantined, r.Currency)
+		}
+
+		if _, err := v.Quarantine.Store(quarantined); err != nil {
+			return nil, nil, fmt.Errorf("error while quarantining rejected rates: %w", err)
+		}
+	}
+
+	if v.OnReject != nil {
+		for _, r := range rejected {
+			v.OnReject(r)
+		}
+	}
+
+	return accepted, rejected, nil
+}
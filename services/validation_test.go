@@ -0,0 +1,199 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/storage"
+)
+
+func TestValidator_Validate(t *testing.T) {
+	t.Parallel()
+
+	newRate := func(from, to, rate string) currencyFetcher.Currency {
+		return currencyFetcher.Currency{From: from, To: to, Provider: "MockProvider", Rate: decimal.RequireFromString(rate), CreatedAt: time.Now()}
+	}
+
+	newStorage := func(asserts *require.Assertions) currencyFetcher.Storage {
+		memory, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+		asserts.Nil(err)
+
+		_, err = memory.Store([]currencyFetcher.Currency{newRate("EUR", "RSD", "117.5")})
+		asserts.Nil(err)
+
+		return memory
+	}
+
+	t.Run("RejectsNonPositiveRates", func(t *testing.T) {
+		asserts := require.New(t)
+		v := Validator{}
+
+		accepted, rejected, err := v.Validate([]currencyFetcher.Currency{
+			newRate("EUR", "USD", "0"),
+			newRate("EUR", "GBP", "-1.2"),
+			newRate("EUR", "CHF", "1.07"),
+		})
+
+		asserts.Nil(err)
+		asserts.Len(accepted, 1)
+		asserts.Equal("CHF", accepted[0].To)
+		asserts.Len(rejected, 2)
+
+		for _, r := range rejected {
+			asserts.True(errors.Is(r.Err, ErrInvalidRate))
+		}
+	})
+
+	t.Run("RejectsUnknownCurrencies", func(t *testing.T) {
+		asserts := require.New(t)
+		v := Validator{Currencies: []string{"EUR", "USD"}}
+
+		accepted, rejected, err := v.Validate([]currencyFetcher.Currency{
+			newRate("EUR", "USD", "1.17"),
+			newRate("EUR", "XYZ", "2"),
+		})
+
+		asserts.Nil(err)
+		asserts.Len(accepted, 1)
+		asserts.Len(rejected, 1)
+		asserts.True(errors.Is(rejected[0], ErrUnknownCurrency))
+
+		_, rejected, err = (&Validator{}).Validate([]currencyFetcher.Currency{newRate("eur", "USD", "1.17")})
+
+		asserts.Nil(err)
+		asserts.Len(rejected, 1)
+	})
+
+	t.Run("RejectsLargeChanges", func(t *testing.T) {
+		asserts := require.New(t)
+		var onReject []RejectedRate
+		v := Validator{
+			Storage:   newStorage(asserts),
+			MaxChange: 5,
+			OnReject: func(r RejectedRate) {
+				onReject = append(onReject, r)
+			},
+		}
+
+		accepted, rejected, err := v.Validate([]currencyFetcher.Currency{
+			newRate("EUR", "RSD", "0.0001"),
+			newRate("EUR", "RSD", "118"),
+			newRate("EUR", "RSD", "130"),
+			newRate("EUR", "USD", "1.17"),
+		})
+
+		asserts.Nil(err)
+		asserts.Len(accepted, 2)
+		asserts.Equal("118", accepted[0].Rate.String())
+		asserts.Equal("USD", accepted[1].To)
+		asserts.Len(rejected, 2)
+		asserts.Equal(rejected, onReject)
+		asserts.True(errors.Is(rejected[0].Err, ErrRateChange))
+		asserts.Equal("117.5", rejected[0].Previous.String())
+		asserts.Equal("118", rejected[1].Previous.String())
+	})
+
+	t.Run("QuarantinesRejectedRates", func(t *testing.T) {
+		asserts := require.New(t)
+		quarantine, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+		asserts.Nil(err)
+
+		v := Validator{Storage: newStorage(asserts), MaxChange: 5, Quarantine: quarantine}
+
+		_, rejected, err := v.Validate([]currencyFetcher.Currency{newRate("EUR", "RSD", "0.0001")})
+
+		asserts.Nil(err)
+		asserts.Len(rejected, 1)
+
+		quarantined, err := currencyFetcher.LatestRate(quarantine, "EUR", "RSD", "MockProvider")
+
+		asserts.Nil(err)
+		asserts.Equal("0.0001", quarantined.Rate.String())
+	})
+}
+
+func TestService_SaveWithValidator(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	fetcher := &MockFetcher{}
+	storage := &MockStorage{}
+	valid := currencyFetcher.Currency{From: "EUR", To: "USD", Provider: "MockProvider", Rate: decimal.RequireFromString("1.17")}
+	service := Service{
+		Fetcher:   fetcher,
+		Storage:   []currencyFetcher.Storage{storage},
+		Validator: &Validator{},
+	}
+
+	fetcher.On("Fetch", []string{"EUR_USD", "EUR_RSD"}).Return([]currencyFetcher.Currency{
+		valid,
+		{From: "EUR", To: "RSD", Provider: "MockProvider", Rate: decimal.Zero},
+	}, nil)
+	storage.On("Store", []currencyFetcher.Currency{valid}).Return([]currencyFetcher.CurrencyWithID{{Currency: valid, ID: uint64(1)}}, nil)
+
+	saved, err := service.Save([]string{"EUR_USD", "EUR_RSD"})
+
+	asserts.Nil(err)
+	asserts.Len(saved["MockStorage"], 1)
+	storage.AssertExpectations(t)
+
+	fetcher = &MockFetcher{}
+	service.Fetcher = fetcher
+	fetcher.On("Fetch", []string{"EUR_RSD"}).Return([]currencyFetcher.Currency{
+		{From: "EUR", To: "RSD", Provider: "MockProvider", Rate: decimal.Zero},
+	}, nil)
+
+	saved, err = service.Save([]string{"EUR_RSD"})
+
+	asserts.Nil(err)
+	asserts.Empty(saved)
+}
+
+func TestService_SaveByDateRangeWithValidator(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	start := time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 2)
+	rate := func(value string, createdAt time.Time) currencyFetcher.Currency {
+		return currencyFetcher.Currency{From: "EUR", To: "RSD", Provider: "MockProvider", Rate: decimal.RequireFromString(value), CreatedAt: createdAt}
+	}
+
+	memory, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+	asserts.Nil(err)
+
+	// Today's rate is far from rates stored years ago
+	_, err = memory.Store([]currencyFetcher.Currency{
+		rate("117.5", start.AddDate(0, 0, -1)),
+		rate("100", time.Now()),
+	})
+	asserts.Nil(err)
+
+	fetcher := &MockHistoricalFetcher{}
+	fetcher.On("FetchByDateRange", []string{"EUR_RSD"}, start, end).Return([]currencyFetcher.Currency{
+		rate("130", end),
+		rate("117.6", start),
+		rate("117.7", start.AddDate(0, 0, 1)),
+	}, nil)
+
+	service := Service{
+		Fetcher:   fetcher,
+		Storage:   []currencyFetcher.Storage{memory},
+		Validator: &Validator{Storage: memory, MaxChange: 5},
+	}
+
+	saved, err := service.SaveByDateRange([]string{"EUR_RSD"}, start, end)
+
+	asserts.Nil(err)
+	asserts.Len(saved["memory"], 2)
+	asserts.Equal("117.6", saved["memory"][0].Rate.String())
+	asserts.Equal("117.7", saved["memory"][1].Rate.String())
+
+	// 130 is compared to 117.7 accepted for the day before
+	stored, err := memory.GetByDate("EUR", "RSD", end, end, 1, 10)
+	asserts.Nil(err)
+	asserts.Empty(stored)
+}
@@ -1,10 +1,16 @@
 package currency
 
 import (
+	"errors"
 	"io"
 	"time"
 )
 
+// latestPageSize is the number of rates read per query by storages without LatestStorage
+const latestPageSize = 500
+
+var ErrNotFound = errors.New("currency rate not found")
+
 type Storage interface {
 	io.Closer
 	Store([]Currency) ([]CurrencyWithID, error)
@@ -16,3 +22,122 @@ type Storage interface {
 	Migrate() error
 	Drop() error
 }
+
+// PairLister is implemented by storages that can list every stored pair, e.g. EUR_RSD
+type PairLister interface {
+	Pairs() ([]string, error)
+}
+
+// LatestStorage is implemented by storages that can query the most recent rate of a pair directly
+type LatestStorage interface {
+	// Latest returns the most recent rate for the pair, from any provider when provider is EmptyProvider.
+	// ErrNotFound is returned when there is no rate for the pair.
+	Latest(from, to string, provider Provider) (CurrencyWithID, error)
+	// LatestBefore returns the most recent rate for the pair stored at or before date.
+	// ErrNotFound is returned when there is no such rate.
+	LatestBefore(from, to string, provider Provider, date time.Time) (CurrencyWithID, error)
+}
+
+// IDStorage is implemented by storages that can keep IDs of rates copied from another storage.
+// IDs the storage cannot use are replaced with generated ones, returned rates hold the stored IDs.
+type IDStorage interface {
+	StoreWithID([]CurrencyWithID) ([]CurrencyWithID, error)
+}
+
+// Unwrapper is implemented by storages wrapping another one, so optional interfaces
+// of the wrapped storage can still be found
+type Unwrapper interface {
+	Unwrap() Storage
+}
+
+// UnwrapStorage returns the innermost storage of s
+func UnwrapStorage(s Storage) Storage {
+	for {
+		u, ok := s.(Unwrapper)
+
+		if !ok {
+			return s
+		}
+
+		s = u.Unwrap()
+	}
+}
+
+// CopyRates stores rates read from another storage in s. IDs are kept when s or the storage
+// it wraps implements IDStorage, wrapped storages are written directly in that case.
+func CopyRates(s Storage, rates []CurrencyWithID) ([]CurrencyWithID, error) {
+	if idStorage, ok := s.(IDStorage); ok {
+		return idStorage.StoreWithID(rates)
+	}
+
+	if idStorage, ok := UnwrapStorage(s).(IDStorage); ok {
+		return idStorage.StoreWithID(rates)
+	}
+
+	currencies := make([]Currency, 0, len(rates))
+
+	for _, rate := range rates {
+		currencies = append(currencies, rate.Currency)
+	}
+
+	return s.Store(currencies)
+}
+
+func latestStorage(s Storage) (LatestStorage, bool) {
+	if latest, ok := s.(LatestStorage); ok {
+		return latest, true
+	}
+
+	latest, ok := UnwrapStorage(s).(LatestStorage)
+
+	return latest, ok
+}
+
+// LatestRate returns the most recent rate for the pair stored in s, see LatestStorage.
+// Storages that do not implement LatestStorage are paged through up to now.
+func LatestRate(s Storage, from, to string, provider Provider) (CurrencyWithID, error) {
+	if latest, ok := latestStorage(s); ok {
+		return latest.Latest(from, to, provider)
+	}
+
+	return lastRate(s, from, to, provider, time.Now())
+}
+
+// LatestRateBefore returns the most recent rate for the pair stored in s at or before date, see LatestStorage.
+// Storages that do not implement LatestStorage are paged through up to date.
+func LatestRateBefore(s Storage, from, to string, provider Provider, date time.Time) (CurrencyWithID, error) {
+	if latest, ok := latestStorage(s); ok {
+		return latest.LatestBefore(from, to, provider, date)
+	}
+
+	return lastRate(s, from, to, provider, date)
+}
+
+// lastRate pages through rates stored up to end and returns the last one
+func lastRate(s Storage, from, to string, provider Provider, end time.Time) (CurrencyWithID, error) {
+	var last CurrencyWithID
+
+	found := false
+
+	for page := int64(1); ; page++ {
+		rates, err := s.GetByDateAndProvider(from, to, provider, time.Time{}, end, page, latestPageSize)
+
+		if err != nil {
+			return CurrencyWithID{}, err
+		}
+
+		if len(rates) != 0 {
+			last, found = rates[len(rates)-1], true
+		}
+
+		if len(rates) < latestPageSize {
+			break
+		}
+	}
+
+	if !found {
+		return CurrencyWithID{}, ErrNotFound
+	}
+
+	return last, nil
+}
@@ -0,0 +1,87 @@
+package storage_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/storage"
+	"github.com/malusev998/currency/storage/storagetest"
+)
+
+func postgresConnectionString() string {
+	host := "localhost"
+
+	if os.Getenv("RUNNING_IN_DOCKER") != "" {
+		host = "postgres"
+	}
+
+	return "postgres://currency:currency@" + host + ":5432/currencydb?sslmode=disable"
+}
+
+func TestConformance_MySQL(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) currency.Storage {
+		st, err := storage.NewMySQLStorage(storage.MySQLConfig{
+			BaseConfig: storage.BaseConfig{
+				Cxt:     context.Background(),
+				Migrate: true,
+			},
+			ConnectionString: mysqlConnectionString(),
+			TableName:        "currency_conformance_test",
+		})
+		require.NoError(t, err)
+
+		return st
+	})
+}
+
+func TestConformance_MongoDB(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) currency.Storage {
+		st, err := storage.NewMongoStorage(storage.MongoDBConfig{
+			BaseConfig: storage.BaseConfig{
+				Cxt:     context.Background(),
+				Migrate: true,
+			},
+			ConnectionString: getMongoURI(),
+			Database:         "currency_fetcher_conformance",
+			Collection:       "currency",
+		})
+		require.NoError(t, err)
+
+		return st
+	})
+}
+
+func TestConformance_PostgreSQL(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) currency.Storage {
+		st, err := storage.NewPostgreSQLStorage(storage.PostgreSQLConfig{
+			BaseConfig: storage.BaseConfig{
+				Cxt:     context.Background(),
+				Migrate: true,
+			},
+			ConnectionString: postgresConnectionString(),
+			TableName:        "currency_conformance_test",
+		})
+		require.NoError(t, err)
+
+		return st
+	})
+}
+
+func TestConformance_SQLite(t *testing.T) {
+	t.Parallel()
+	storagetest.Run(t, newSQLiteStorage)
+}
+
+func TestConformance_Memory(t *testing.T) {
+	t.Parallel()
+	storagetest.Run(t, func(t *testing.T) currency.Storage {
+		st, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+		require.NoError(t, err)
+
+		return st
+	})
+}
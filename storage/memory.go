@@ -0,0 +1,301 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	currencyFetcher "github.com/malusev998/currency"
+)
+
+const MemoryStorageProviderName = "memory"
+
+var ErrDuplicateID = errors.New("rate with the same id is already stored")
+
+type (
+	memoryIndex struct {
+		all        []currencyFetcher.CurrencyWithID
+		byProvider map[currencyFetcher.Provider][]currencyFetcher.CurrencyWithID
+	}
+
+	memoryEntry struct {
+		pair     string
+		provider currencyFetcher.Provider
+		id       interface{}
+	}
+
+	memoryStorage struct {
+		mutex       sync.RWMutex
+		idGenerator IDGenerator
+		maxEntries  int
+		pairs       map[string]*memoryIndex
+		ids         map[interface{}]struct{}
+		// insertion order, used to evict the oldest entries when maxEntries is reached
+		entries []memoryEntry
+	}
+)
+
+func insertSorted(currencies []currencyFetcher.CurrencyWithID, c currencyFetcher.CurrencyWithID) []currencyFetcher.CurrencyWithID {
+	i := sort.Search(len(currencies), func(i int) bool {
+		return currencies[i].CreatedAt.After(c.CreatedAt)
+	})
+
+	currencies = append(currencies, currencyFetcher.CurrencyWithID{})
+	copy(currencies[i+1:], currencies[i:])
+	currencies[i] = c
+
+	return currencies
+}
+
+func removeByID(currencies []currencyFetcher.CurrencyWithID, id interface{}) []currencyFetcher.CurrencyWithID {
+	for i, c := range currencies {
+		if c.ID == id {
+			return append(currencies[:i], currencies[i+1:]...)
+		}
+	}
+
+	return currencies
+}
+
+func (m *memoryStorage) evict() {
+	for m.maxEntries > 0 && len(m.entries) > m.maxEntries {
+		entry := m.entries[0]
+		m.entries = m.entries[1:]
+
+		index, ok := m.pairs[entry.pair]
+		if !ok {
+			continue
+		}
+
+		delete(m.ids, entry.id)
+
+		index.all = removeByID(index.all, entry.id)
+		index.byProvider[entry.provider] = removeByID(index.byProvider[entry.provider], entry.id)
+
+		if len(index.byProvider[entry.provider]) == 0 {
+			delete(index.byProvider, entry.provider)
+		}
+
+		if len(index.all) == 0 {
+			delete(m.pairs, entry.pair)
+		}
+	}
+}
+
+func (m *memoryStorage) Store(currency []currencyFetcher.Currency) ([]currencyFetcher.CurrencyWithID, error) {
+	return m.StoreWithID(withoutIDs(currency))
+}
+
+func (m *memoryStorage) StoreWithID(currency []currencyFetcher.CurrencyWithID) ([]currencyFetcher.CurrencyWithID, error) {
+	data := make([]currencyFetcher.CurrencyWithID, 0, len(currency))
+
+	for _, cur := range currency {
+		id, err := rateID(m.idGenerator, cur.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if cur.CreatedAt.IsZero() {
+			cur.CreatedAt = time.Now()
+		}
+
+		data = append(data, currencyFetcher.CurrencyWithID{
+			Currency: cur.Currency,
+			ID:       id,
+		})
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Nothing is stored when any ID is taken, like SQL storages fail on the primary key
+	batch := make(map[interface{}]struct{}, len(data))
+
+	for _, c := range data {
+		_, stored := m.ids[c.ID]
+		_, repeated := batch[c.ID]
+
+		if stored || repeated {
+			return nil, fmt.Errorf("%w: %v", ErrDuplicateID, c.ID)
+		}
+
+		batch[c.ID] = struct{}{}
+	}
+
+	for _, c := range data {
+		m.ids[c.ID] = struct{}{}
+		pair := fmt.Sprintf("%s_%s", c.From, c.To)
+		index, ok := m.pairs[pair]
+
+		if !ok {
+			index = &memoryIndex{byProvider: make(map[currencyFetcher.Provider][]currencyFetcher.CurrencyWithID)}
+			m.pairs[pair] = index
+		}
+
+		index.all = insertSorted(index.all, c)
+		index.byProvider[c.Provider] = insertSorted(index.byProvider[c.Provider], c)
+		m.entries = append(m.entries, memoryEntry{pair: pair, provider: c.Provider, id: c.ID})
+	}
+
+	m.evict()
+
+	return data, nil
+}
+
+func (m *memoryStorage) Pairs() ([]string, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	pairs := make([]string, 0, len(m.pairs))
+
+	for pair := range m.pairs {
+		pairs = append(pairs, pair)
+	}
+
+	sort.Strings(pairs)
+
+	return pairs, nil
+}
+
+func (m *memoryStorage) Get(from, to string, page, perPage int64) ([]currencyFetcher.CurrencyWithID, error) {
+	return m.GetByProvider(from, to, currencyFetcher.EmptyProvider, page, perPage)
+}
+
+func (m *memoryStorage) GetByProvider(from, to string, provider currencyFetcher.Provider, page, perPage int64) ([]currencyFetcher.CurrencyWithID, error) {
+	return m.GetByDateAndProvider(from, to, provider, time.Time{}, time.Now(), page, perPage)
+}
+
+func (m *memoryStorage) GetByDate(from, to string, start, end time.Time, page, perPage int64) ([]currencyFetcher.CurrencyWithID, error) {
+	return m.GetByDateAndProvider(from, to, currencyFetcher.EmptyProvider, start, end, page, perPage)
+}
+
+// GetByDateAndProvider follows the SQL storages: rates are sorted by
+// CreatedAt ascending and both start and end are inclusive.
+func (m *memoryStorage) GetByDateAndProvider(from, to string, provider currencyFetcher.Provider, start, end time.Time, page, perPage int64) ([]currencyFetcher.CurrencyWithID, error) {
+	if start.After(end) {
+		return nil, errors.New("start time cannot be after end time")
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	result := make([]currencyFetcher.CurrencyWithID, 0, perPage)
+	index, ok := m.pairs[fmt.Sprintf("%s_%s", from, to)]
+
+	if !ok {
+		return result, nil
+	}
+
+	currencies := index.all
+
+	if provider != currencyFetcher.EmptyProvider {
+		currencies = index.byProvider[provider]
+	}
+
+	first := sort.Search(len(currencies), func(i int) bool {
+		return !currencies[i].CreatedAt.Before(start)
+	})
+	last := sort.Search(len(currencies), func(i int) bool {
+		return currencies[i].CreatedAt.After(end)
+	})
+
+	offset := int64(first) + (page-1)*perPage
+
+	if offset < int64(first) {
+		offset = int64(first)
+	}
+
+	for i := offset; i < int64(last) && i < offset+perPage; i++ {
+		result = append(result, currencies[i])
+	}
+
+	return result, nil
+}
+
+func (m *memoryStorage) Latest(from, to string, provider currencyFetcher.Provider) (currencyFetcher.CurrencyWithID, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	index, ok := m.pairs[fmt.Sprintf("%s_%s", from, to)]
+
+	if !ok {
+		return currencyFetcher.CurrencyWithID{}, currencyFetcher.ErrNotFound
+	}
+
+	currencies := index.all
+
+	if provider != currencyFetcher.EmptyProvider {
+		currencies = index.byProvider[provider]
+	}
+
+	if len(currencies) == 0 {
+		return currencyFetcher.CurrencyWithID{}, currencyFetcher.ErrNotFound
+	}
+
+	return currencies[len(currencies)-1], nil
+}
+
+func (m *memoryStorage) LatestBefore(from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	index, ok := m.pairs[fmt.Sprintf("%s_%s", from, to)]
+
+	if !ok {
+		return currencyFetcher.CurrencyWithID{}, currencyFetcher.ErrNotFound
+	}
+
+	currencies := index.all
+
+	if provider != currencyFetcher.EmptyProvider {
+		currencies = index.byProvider[provider]
+	}
+
+	after := sort.Search(len(currencies), func(i int) bool {
+		return currencies[i].CreatedAt.After(date)
+	})
+
+	if after == 0 {
+		return currencyFetcher.CurrencyWithID{}, currencyFetcher.ErrNotFound
+	}
+
+	return currencies[after-1], nil
+}
+
+func (*memoryStorage) GetStorageProviderName() string {
+	return MemoryStorageProviderName
+}
+
+func (*memoryStorage) Migrate() error {
+	return nil
+}
+
+func (m *memoryStorage) Drop() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.pairs = make(map[string]*memoryIndex)
+	m.ids = make(map[interface{}]struct{})
+	m.entries = nil
+
+	return nil
+}
+
+func (*memoryStorage) Close() error {
+	return nil
+}
+
+func NewMemoryStorage(c MemoryConfig) (currencyFetcher.Storage, error) {
+	if c.MaxEntries < 0 {
+		return nil, fmt.Errorf("max entries cannot be negative: %d", c.MaxEntries)
+	}
+
+	return &memoryStorage{
+		idGenerator: c.IDGenerator,
+		maxEntries:  c.MaxEntries,
+		pairs:       make(map[string]*memoryIndex),
+		ids:         make(map[interface{}]struct{}),
+	}, nil
+}
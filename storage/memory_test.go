@@ -0,0 +1,157 @@
+package storage_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/storage"
+)
+
+func TestMemoryStorage_GetByDateAndProvider(t *testing.T) {
+	t.Parallel()
+	st, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+	require.Nil(t, err)
+
+	start := time.Date(2020, 10, 16, 0, 0, 0, 0, time.UTC)
+	seed := make([]currency.Currency, 0, 20)
+
+	// inserted in reverse order to make sure results are sorted by CreatedAt
+	for i := 9; i >= 0; i-- {
+		seed = append(seed,
+			currency.Currency{From: "EUR", To: "USD", Provider: "First", Rate: decimal.NewFromInt(int64(i)), CreatedAt: start.Add(time.Duration(i) * time.Hour)},
+			currency.Currency{From: "EUR", To: "USD", Provider: "Second", Rate: decimal.NewFromInt(int64(i)), CreatedAt: start.Add(time.Duration(i) * time.Hour)},
+		)
+	}
+
+	stored, err := st.Store(seed)
+	require.Nil(t, err)
+	require.Len(t, stored, 20)
+
+	t.Run("Ascending and paginated", func(t *testing.T) {
+		asserts := require.New(t)
+		result, err := st.GetByDateAndProvider("EUR", "USD", "First", start, start.Add(24*time.Hour), 2, 3)
+
+		asserts.Nil(err)
+		asserts.Len(result, 3)
+
+		for i, c := range result {
+			asserts.Equal(currency.Provider("First"), c.Provider)
+			asserts.True(decimal.NewFromInt(int64(i + 3)).Equal(c.Rate))
+		}
+	})
+
+	t.Run("Inclusive bounds", func(t *testing.T) {
+		asserts := require.New(t)
+		result, err := st.GetByDate("EUR", "USD", start.Add(time.Hour), start.Add(2*time.Hour), 1, 10)
+
+		asserts.Nil(err)
+		asserts.Len(result, 4)
+	})
+
+	t.Run("Unknown pair", func(t *testing.T) {
+		asserts := require.New(t)
+		result, err := st.Get("EUR", "RSD", 1, 10)
+
+		asserts.Nil(err)
+		asserts.Empty(result)
+	})
+
+	t.Run("Start after end", func(t *testing.T) {
+		asserts := require.New(t)
+		result, err := st.GetByDate("EUR", "USD", start.Add(time.Hour), start, 1, 10)
+
+		asserts.Nil(result)
+		asserts.Error(err)
+	})
+}
+
+func TestMemoryStorage_MaxEntries(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	st, err := storage.NewMemoryStorage(storage.MemoryConfig{MaxEntries: 2})
+	asserts.Nil(err)
+
+	start := time.Date(2020, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		_, err := st.Store([]currency.Currency{
+			{From: "EUR", To: "USD", Provider: "TestProvider", Rate: decimal.NewFromInt(int64(i)), CreatedAt: start.Add(time.Duration(i) * time.Hour)},
+		})
+		asserts.Nil(err)
+	}
+
+	result, err := st.GetByDate("EUR", "USD", start, start.Add(24*time.Hour), 1, 10)
+
+	asserts.Nil(err)
+	asserts.Len(result, 2)
+	asserts.Equal("1", result[0].Rate.String())
+	asserts.Equal("2", result[1].Rate.String())
+
+	_, err = storage.NewMemoryStorage(storage.MemoryConfig{MaxEntries: -1})
+	asserts.Error(err)
+}
+
+func TestMemoryStorage_StoreWithDuplicateID(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	st, err := storage.NewMemoryStorage(storage.MemoryConfig{MaxEntries: 1})
+	asserts.Nil(err)
+
+	start := time.Date(2020, 10, 16, 0, 0, 0, 0, time.UTC)
+	stored, err := st.Store([]currency.Currency{
+		{From: "EUR", To: "USD", Provider: "TestProvider", Rate: decimal.NewFromInt(1), CreatedAt: start},
+	})
+	asserts.Nil(err)
+
+	idStorage := st.(currency.IDStorage)
+
+	_, err = idStorage.StoreWithID(stored)
+	asserts.True(errors.Is(err, storage.ErrDuplicateID))
+
+	// IDs of evicted rates can be stored again
+	_, err = st.Store([]currency.Currency{
+		{From: "EUR", To: "USD", Provider: "TestProvider", Rate: decimal.NewFromInt(2), CreatedAt: start.Add(time.Hour)},
+	})
+	asserts.Nil(err)
+
+	_, err = idStorage.StoreWithID(stored)
+	asserts.Nil(err)
+}
+
+func TestMemoryStorage_Concurrent(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	st, _ := storage.NewMemoryStorage(storage.MemoryConfig{})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			_, _ = st.Store([]currency.Currency{{From: "EUR", To: "USD", Provider: "TestProvider", Rate: decimal.RequireFromString("1.17")}})
+		}()
+
+		go func() {
+			defer wg.Done()
+			_, _ = st.Get("EUR", "USD", 1, 100)
+		}()
+	}
+
+	wg.Wait()
+
+	result, err := st.Get("EUR", "USD", 1, 100)
+	asserts.Nil(err)
+	asserts.Len(result, 10)
+
+	asserts.Nil(st.Drop())
+	result, _ = st.Get("EUR", "USD", 1, 100)
+	asserts.Empty(result)
+}
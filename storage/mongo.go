@@ -2,16 +2,21 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"sort"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/x/bsonx"
 
 	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/iso4217"
 )
 
 const MongoDBProviderName = "mongodb"
@@ -37,11 +42,15 @@ func (m mongoStorage) GetByDate(from, to string, start, end time.Time, page, per
 }
 
 func (m mongoStorage) GetByDateAndProvider(from, to string, provider currencyFetcher.Provider, start, end time.Time, page, perPage int64) ([]currencyFetcher.CurrencyWithID, error) {
+	if start.After(end) {
+		return nil, errors.New("start time cannot be after end time")
+	}
+
 	filter := bson.M{
 		"fetchers": fmt.Sprintf("%s_%s", from, to),
 		"createdAt": bson.M{
 			"$gte": start,
-			"$lt":  end,
+			"$lte": end,
 		},
 	}
 
@@ -53,8 +62,10 @@ func (m mongoStorage) GetByDateAndProvider(from, to string, provider currencyFet
 	cursor, err := m.collection.Find(m.ctx, filter, &options.FindOptions{
 		Limit: &perPage,
 		Skip:  &skip,
-		Sort: bson.M{
-			"createdAt": -1,
+		// _id keeps the order of rates created at the same time stable between pages
+		Sort: bson.D{
+			{Key: "createdAt", Value: 1},
+			{Key: "_id", Value: 1},
 		},
 	})
 
@@ -67,24 +78,134 @@ func (m mongoStorage) GetByDateAndProvider(from, to string, provider currencyFet
 	currencies := make([]currencyFetcher.CurrencyWithID, 0, perPage)
 
 	for cursor.Next(m.ctx) {
-		current := cursor.Current
-		isoCurrencies := strings.Split(current.Lookup("fetchers").StringValue(), "_")
-		currencies = append(currencies, currencyFetcher.CurrencyWithID{
-			Currency: currencyFetcher.Currency{
-				From:      isoCurrencies[0],
-				To:        isoCurrencies[1],
-				Provider:  currencyFetcher.Provider(current.Lookup("provider").StringValue()),
-				Rate:      float32(current.Lookup("rate").Double()),
-				CreatedAt: current.Lookup("createdAt").Time(),
-			},
-			ID: current.Lookup("_id").ObjectID(),
-		})
+		currency, err := decodeMongoCurrency(cursor.Current)
+
+		if err != nil {
+			return nil, err
+		}
+
+		currencies = append(currencies, currency)
+	}
+
+	return currencies, cursor.Err()
+}
+
+func (m mongoStorage) Pairs() ([]string, error) {
+	values, err := m.collection.Distinct(m.ctx, "fetchers", bson.M{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make([]string, 0, len(values))
+
+	for _, value := range values {
+		if pair, ok := value.(string); ok {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	sort.Strings(pairs)
+
+	return pairs, nil
+}
+
+func (m mongoStorage) Latest(from, to string, provider currencyFetcher.Provider) (currencyFetcher.CurrencyWithID, error) {
+	return m.latest(from, to, provider, time.Time{})
+}
+
+func (m mongoStorage) LatestBefore(from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, error) {
+	return m.latest(from, to, provider, date)
+}
+
+// latest returns the most recent rate stored at or before date, any rate when date is zero
+func (m mongoStorage) latest(from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, error) {
+	filter := bson.M{
+		"fetchers": fmt.Sprintf("%s_%s", from, to),
+	}
+
+	if provider != currencyFetcher.EmptyProvider {
+		filter["provider"] = provider
+	}
+
+	if !date.IsZero() {
+		filter["createdAt"] = bson.M{"$lte": date}
+	}
+
+	// Filtered by fetchers_1_createdAt_-1 and fetchers_1_provider_1_createdAt_-1 indexes,
+	// _id picks the same rate as the last page of GetByDateAndProvider when CreatedAt is equal
+	result := m.collection.FindOne(m.ctx, filter, options.FindOne().SetSort(bson.D{
+		{Key: "createdAt", Value: -1},
+		{Key: "_id", Value: -1},
+	}))
+
+	raw, err := result.DecodeBytes()
+
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return currencyFetcher.CurrencyWithID{}, currencyFetcher.ErrNotFound
 	}
 
-	return currencies, nil
+	if err != nil {
+		return currencyFetcher.CurrencyWithID{}, err
+	}
+
+	return decodeMongoCurrency(raw)
+}
+
+func decodeMongoCurrency(current bson.Raw) (currencyFetcher.CurrencyWithID, error) {
+	pair, err := iso4217.SplitPair(current.Lookup("fetchers").StringValue())
+
+	if err != nil {
+		return currencyFetcher.CurrencyWithID{}, err
+	}
+
+	rate, err := decodeMongoRate(current.Lookup("rate"))
+
+	if err != nil {
+		return currencyFetcher.CurrencyWithID{}, err
+	}
+
+	return currencyFetcher.CurrencyWithID{
+		Currency: currencyFetcher.Currency{
+			From:      pair.From,
+			To:        pair.To,
+			Provider:  currencyFetcher.Provider(current.Lookup("provider").StringValue()),
+			Rate:      rate,
+			CreatedAt: current.Lookup("createdAt").Time(),
+		},
+		ID: current.Lookup("_id").ObjectID(),
+	}, nil
+}
+
+// decodeMongoRate reads rates stored as decimal128,
+// and rates stored as double before they are migrated.
+func decodeMongoRate(value bson.RawValue) (decimal.Decimal, error) {
+	if value.Type == bsontype.Double {
+		return decimal.NewFromFloat(value.Double()), nil
+	}
+
+	return decimal.NewFromString(value.Decimal128().String())
 }
 
 func (m mongoStorage) Store(currency []currencyFetcher.Currency) ([]currencyFetcher.CurrencyWithID, error) {
+	return m.StoreWithID(withoutIDs(currency))
+}
+
+// mongoID keeps ObjectIDs of rates copied from another storage, other IDs are generated by MongoDB
+func mongoID(id interface{}) (primitive.ObjectID, bool) {
+	switch value := id.(type) {
+	case primitive.ObjectID:
+		return value, true
+	case string:
+		if parsed, err := primitive.ObjectIDFromHex(value); err == nil {
+			return parsed, true
+		}
+	}
+
+	return primitive.NilObjectID, false
+}
+
+func (m mongoStorage) StoreWithID(currency []currencyFetcher.CurrencyWithID) ([]currencyFetcher.CurrencyWithID, error) {
 	currenciesToInsert := make([]interface{}, 0, len(currency))
 
 	for _, cur := range currency {
@@ -93,12 +214,24 @@ func (m mongoStorage) Store(currency []currencyFetcher.Currency) ([]currencyFetc
 			createdAt = time.Now()
 		}
 
-		currenciesToInsert = append(currenciesToInsert, bson.M{
+		rate, err := primitive.ParseDecimal128(cur.Rate.String())
+
+		if err != nil {
+			return nil, err
+		}
+
+		document := bson.M{
 			"fetchers":  fmt.Sprintf("%s_%s", cur.From, cur.To),
-			"rate":      cur.Rate,
+			"rate":      rate,
 			"provider":  cur.Provider,
 			"createdAt": createdAt,
-		})
+		}
+
+		if id, ok := mongoID(cur.ID); ok {
+			document["_id"] = id
+		}
+
+		currenciesToInsert = append(currenciesToInsert, document)
 	}
 
 	results, err := m.collection.InsertMany(m.ctx, currenciesToInsert)
@@ -110,7 +243,7 @@ func (m mongoStorage) Store(currency []currencyFetcher.Currency) ([]currencyFetc
 	data := make([]currencyFetcher.CurrencyWithID, 0, len(results.InsertedIDs))
 	for i, result := range results.InsertedIDs {
 		data = append(data, currencyFetcher.CurrencyWithID{
-			Currency: currency[i],
+			Currency: currency[i].Currency,
 			ID:       result,
 		})
 	}
@@ -125,7 +258,20 @@ func (m mongoStorage) Migrate() error {
 		}
 	}
 
-	_, err := m.collection.Indexes().CreateOne(m.ctx, mongo.IndexModel{
+	// Rates stored before they became decimals are doubles
+	_, err := m.collection.UpdateMany(
+		m.ctx,
+		bson.M{"rate": bson.M{"$type": "double"}},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{"rate": bson.M{"$round": bson.A{bson.M{"$toDecimal": "$rate"}, 8}}}}},
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("error while migrating mongodb rates to decimal: %v", err)
+	}
+
+	_, err = m.collection.Indexes().CreateOne(m.ctx, mongo.IndexModel{
 		Keys: bsonx.Doc{
 			{
 				Key:   "fetchers",
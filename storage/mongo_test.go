@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -72,7 +73,7 @@ func TestStoreOne(t *testing.T) {
 			From:     "EUR",
 			To:       "USD",
 			Provider: provider,
-			Rate:     0.8,
+			Rate:     decimal.RequireFromString("0.8"),
 		},
 	})
 
@@ -82,7 +83,7 @@ func TestStoreOne(t *testing.T) {
 	assert.Equal("EUR", currencies[0].From)
 	assert.Equal("USD", currencies[0].To)
 	assert.Equal(provider, currencies[0].Provider)
-	assert.Equal(float32(0.8), currencies[0].Rate)
+	assert.Equal("0.8", currencies[0].Rate.String())
 }
 
 func TestStoreMany(t *testing.T) {
@@ -105,14 +106,14 @@ func TestStoreMany(t *testing.T) {
 			From:      "EUR",
 			To:        "USD",
 			Provider:  "TestProvider",
-			Rate:      0.8,
+			Rate:      decimal.RequireFromString("0.8"),
 			CreatedAt: time.Now().Add(time.Duration(10) * time.Minute),
 		},
 		{
 			From:     "EUR",
 			To:       "USD",
 			Provider: "TestProvider",
-			Rate:     0.8,
+			Rate:     decimal.RequireFromString("0.8"),
 		},
 	})
 
@@ -123,7 +124,7 @@ func TestStoreMany(t *testing.T) {
 		assert.Equal("EUR", cur.From)
 		assert.Equal("USD", cur.To)
 		assert.Equal(currency.Provider("TestProvider"), cur.Provider)
-		assert.Equal(float32(0.8), cur.Rate)
+		assert.Equal("0.8", cur.Rate.String())
 	}
 }
 
@@ -11,6 +11,7 @@ import (
 	"github.com/google/uuid"
 
 	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/iso4217"
 )
 
 const (
@@ -24,6 +25,9 @@ type (
 	IDGenerator interface {
 		Generate() []byte
 	}
+	rowScanner interface {
+		Scan(dest ...interface{}) error
+	}
 	mysqlStorage struct {
 		idGenerator IDGenerator
 		ctx         context.Context
@@ -32,7 +36,73 @@ type (
 	}
 )
 
+func generateID(generator IDGenerator) (uuid.UUID, error) {
+	if generator == nil {
+		return uuid.New(), nil
+	}
+
+	bytes := generator.Generate()
+	if bytes == nil || len(bytes) != 16 {
+		return uuid.Nil, ErrNotEnoughBytesInGenerator
+	}
+
+	return uuid.FromBytes(bytes)
+}
+
+// rateID keeps UUIDs of rates copied from another storage, other IDs are replaced with generated ones
+func rateID(generator IDGenerator, id interface{}) (uuid.UUID, error) {
+	switch value := id.(type) {
+	case uuid.UUID:
+		return value, nil
+	case string:
+		if parsed, err := uuid.Parse(value); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return generateID(generator)
+}
+
+// sqlPairs lists pairs stored in the currency column of table
+func sqlPairs(ctx context.Context, db *sql.DB, table string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT DISTINCT currency FROM %s ORDER BY currency", table))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	pairs := make([]string, 0)
+
+	for rows.Next() {
+		var pair string
+
+		if err := rows.Scan(&pair); err != nil {
+			return nil, err
+		}
+
+		pairs = append(pairs, pair)
+	}
+
+	return pairs, rows.Err()
+}
+
+func withoutIDs(currencies []currencyFetcher.Currency) []currencyFetcher.CurrencyWithID {
+	data := make([]currencyFetcher.CurrencyWithID, 0, len(currencies))
+
+	for _, c := range currencies {
+		data = append(data, currencyFetcher.CurrencyWithID{Currency: c})
+	}
+
+	return data
+}
+
 func (m mysqlStorage) Store(currency []currencyFetcher.Currency) ([]currencyFetcher.CurrencyWithID, error) {
+	return m.StoreWithID(withoutIDs(currency))
+}
+
+func (m mysqlStorage) StoreWithID(currency []currencyFetcher.CurrencyWithID) ([]currencyFetcher.CurrencyWithID, error) {
 	tx, err := m.db.Begin()
 
 	if err != nil {
@@ -46,18 +116,10 @@ func (m mysqlStorage) Store(currency []currencyFetcher.Currency) ([]currencyFetc
 	data := make([]currencyFetcher.CurrencyWithID, 0, len(currency))
 
 	for _, cur := range currency {
-		var id uuid.UUID
-		if m.idGenerator == nil {
-			id = uuid.New()
-		} else {
-			bytes := m.idGenerator.Generate()
-			if bytes == nil || len(bytes) != 16 {
-				return nil, ErrNotEnoughBytesInGenerator
-			}
-			id, err = uuid.FromBytes(m.idGenerator.Generate())
-			if err != nil {
-				return nil, err
-			}
+		id, err := rateID(m.idGenerator, cur.ID)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
 		}
 
 		createdAt := cur.CreatedAt
@@ -65,11 +127,14 @@ func (m mysqlStorage) Store(currency []currencyFetcher.Currency) ([]currencyFetc
 			createdAt = time.Now()
 		}
 
+		// timestamp columns round fractional seconds, truncating keeps the rate in the second it was created in
+		createdAt = createdAt.Truncate(time.Second)
+
 		builder.WriteString("(?,?,?,?,?),")
 
 		bind = append(bind, id, fmt.Sprintf("%s_%s", cur.From, cur.To), cur.Provider, cur.Rate, createdAt)
 		data = append(data, currencyFetcher.CurrencyWithID{
-			Currency: cur,
+			Currency: cur.Currency,
 			ID:       id,
 		})
 	}
@@ -105,6 +170,10 @@ func (m mysqlStorage) Store(currency []currencyFetcher.Currency) ([]currencyFetc
 	return data, nil
 }
 
+func (m mysqlStorage) Pairs() ([]string, error) {
+	return sqlPairs(m.ctx, m.db, m.tableName)
+}
+
 func (m mysqlStorage) Get(from, to string, page, perPage int64) ([]currencyFetcher.CurrencyWithID, error) {
 	return m.GetByProvider(from, to, currencyFetcher.EmptyProvider, page, perPage)
 }
@@ -124,15 +193,19 @@ func (m mysqlStorage) GetByDateAndProvider(from, to string, provider currencyFet
 
 	var builder strings.Builder
 
+	bind := []interface{}{fmt.Sprintf("%s_%s", from, to), start.UTC().Format(MySQLTimeFormat), end.UTC().Format(MySQLTimeFormat)}
+
 	builder.WriteString("SELECT id,currency,provider,rate,created_at FROM ")
 	builder.WriteString(m.tableName)
 	builder.WriteString(" WHERE currency = ? AND created_at BETWEEN ? AND ?")
 
-	if provider != "" {
+	if provider != currencyFetcher.EmptyProvider {
 		builder.WriteString(" AND provider = ?")
+		bind = append(bind, provider)
 	}
 
-	builder.WriteString(" ORDER BY created_at LIMIT ?, ?")
+	builder.WriteString(" ORDER BY created_at, id LIMIT ?, ?")
+	bind = append(bind, (page-1)*perPage, perPage)
 
 	stmt, err := m.db.PrepareContext(m.ctx, builder.String())
 
@@ -140,7 +213,9 @@ func (m mysqlStorage) GetByDateAndProvider(from, to string, provider currencyFet
 		return nil, err
 	}
 
-	rows, err := stmt.Query(fmt.Sprintf("%s_%s", from, to), start.Format(MySQLTimeFormat), end.Format(MySQLTimeFormat), (page-1)*perPage, perPage)
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(m.ctx, bind...)
 
 	if err != nil {
 		return nil, err
@@ -151,23 +226,81 @@ func (m mysqlStorage) GetByDateAndProvider(from, to string, provider currencyFet
 	result := make([]currencyFetcher.CurrencyWithID, 0, perPage)
 
 	for rows.Next() {
-		var currency string
-		var createdAt string
-
-		currencyWithID := currencyFetcher.CurrencyWithID{}
+		currencyWithID, err := m.scan(rows)
 
-		if err := rows.Scan(&currencyWithID.ID, &currency, &currencyWithID.Provider, &currencyWithID.Rate, &createdAt); err != nil {
+		if err != nil {
 			return nil, err
 		}
 
-		currencyWithID.CreatedAt, _ = time.Parse(MySQLTimeFormat, createdAt)
-		isoCurrencies := strings.Split(currency, "_")
-		currencyWithID.From = isoCurrencies[0]
-		currencyWithID.To = isoCurrencies[1]
 		result = append(result, currencyWithID)
 	}
 
-	return result, nil
+	return result, rows.Err()
+}
+
+func (m mysqlStorage) Latest(from, to string, provider currencyFetcher.Provider) (currencyFetcher.CurrencyWithID, error) {
+	return m.latest(from, to, provider, time.Time{})
+}
+
+func (m mysqlStorage) LatestBefore(from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, error) {
+	return m.latest(from, to, provider, date)
+}
+
+// latest returns the most recent rate stored at or before date, any rate when date is zero
+func (m mysqlStorage) latest(from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, error) {
+	var builder strings.Builder
+
+	bind := []interface{}{fmt.Sprintf("%s_%s", from, to)}
+
+	builder.WriteString("SELECT id,currency,provider,rate,created_at FROM ")
+	builder.WriteString(m.tableName)
+	builder.WriteString(" WHERE currency = ?")
+
+	if provider != currencyFetcher.EmptyProvider {
+		builder.WriteString(" AND provider = ?")
+		bind = append(bind, provider)
+	}
+
+	if !date.IsZero() {
+		builder.WriteString(" AND created_at <= ?")
+		bind = append(bind, date.UTC().Format(MySQLTimeFormat))
+	}
+
+	builder.WriteString(" ORDER BY created_at DESC, id DESC LIMIT 1")
+
+	currencyWithID, err := m.scan(m.db.QueryRowContext(m.ctx, builder.String(), bind...))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return currencyWithID, currencyFetcher.ErrNotFound
+	}
+
+	return currencyWithID, err
+}
+
+func (m mysqlStorage) scan(row rowScanner) (currencyFetcher.CurrencyWithID, error) {
+	var currency string
+	var createdAt string
+	var id uuid.UUID
+
+	currencyWithID := currencyFetcher.CurrencyWithID{}
+
+	if err := row.Scan(&id, &currency, &currencyWithID.Provider, &currencyWithID.Rate, &createdAt); err != nil {
+		return currencyWithID, err
+	}
+
+	currencyWithID.CreatedAt, _ = time.Parse(MySQLTimeFormat, createdAt)
+	currencyWithID.ID = id
+
+	pair, err := iso4217.SplitPair(currency)
+
+	if err != nil {
+		return currencyWithID, err
+	}
+
+	currencyWithID.From = pair.From
+	currencyWithID.To = pair.To
+
+	return currencyWithID, nil
 }
 
 func (m mysqlStorage) Migrate() error {
@@ -175,7 +308,7 @@ func (m mysqlStorage) Migrate() error {
 		id binary(36) PRIMARY KEY,
 		currency varchar(20) NOT NULL,
 		provider varchar(30) NOT NULL,
-		rate float(8,4) NOT NULL,
+		rate decimal(20,8) NOT NULL,
 		created_at timestamp DEFAULT CURRENT_TIMESTAMP 
 	);`, m.tableName))
 
@@ -183,7 +316,21 @@ func (m mysqlStorage) Migrate() error {
 		return err
 	}
 
+	// Tables created before rates became decimals stored them as float(8,4)
+	_, err = m.db.ExecContext(m.ctx, fmt.Sprintf(`ALTER TABLE %s MODIFY rate decimal(20,8) NOT NULL;`, m.tableName))
+
+	if err != nil {
+		return err
+	}
+
 	_, err = m.db.ExecContext(m.ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS search_index ON %s(currency, provider, created_at);`, m.tableName))
+
+	if err != nil {
+		return err
+	}
+
+	// Used by Latest when rates from all providers are requested
+	_, err = m.db.ExecContext(m.ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS latest_index ON %s(currency, created_at);`, m.tableName))
 	return err
 }
 
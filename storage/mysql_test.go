@@ -15,6 +15,7 @@ import (
 	"github.com/bxcodec/faker/v3"
 	"github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
@@ -125,7 +126,7 @@ func TestMySQL_InsertOne(t *testing.T) {
 			From:      "EUR",
 			To:        "USD",
 			Provider:  "TestProvider",
-			Rate:      0.021,
+			Rate:      decimal.RequireFromString("0.021"),
 			CreatedAt: time.Now(),
 		},
 	})
@@ -156,21 +157,21 @@ func TestMySQL_InsertMany(t *testing.T) {
 			From:      "EUR",
 			To:        "USD",
 			Provider:  "TestProvider",
-			Rate:      0.021,
+			Rate:      decimal.RequireFromString("0.021"),
 			CreatedAt: time.Now(),
 		},
 		{
 			From:      "USD",
 			To:        "EUR",
 			Provider:  "TestProvider",
-			Rate:      1.1,
+			Rate:      decimal.RequireFromString("1.1"),
 			CreatedAt: time.Now(),
 		},
 		{
 			From:      "PHP",
 			To:        "EUR",
 			Provider:  "TestProvider",
-			Rate:      0.5,
+			Rate:      decimal.RequireFromString("0.5"),
 			CreatedAt: time.Now(),
 		},
 	})
@@ -213,7 +214,7 @@ func TestMysqlStorage_Store(t *testing.T) {
 				From:      "EUR",
 				To:        "USD",
 				Provider:  "TestProvider",
-				Rate:      0.021,
+				Rate:      decimal.RequireFromString("0.021"),
 				CreatedAt: time.Now(),
 			},
 		})
@@ -238,7 +239,7 @@ func TestMysqlStorage_StoreUnit(t *testing.T) {
 			From:      "EUR",
 			To:        "USD",
 			Provider:  "TestProvider",
-			Rate:      0.021,
+			Rate:      decimal.RequireFromString("0.021"),
 			CreatedAt: time.Now(),
 		},
 	}
@@ -0,0 +1,303 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+
+	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/iso4217"
+)
+
+const (
+	PostgreSQLStorageProviderName = "postgres"
+	// postgresInsertBatchSize keeps every insert within 65535 bind parameters PostgreSQL allows per statement
+	postgresInsertBatchSize = 65535 / 5
+)
+
+type postgresStorage struct {
+	idGenerator IDGenerator
+	ctx         context.Context
+	db          *sql.DB
+	tableName   string
+}
+
+func (p postgresStorage) Store(currency []currencyFetcher.Currency) ([]currencyFetcher.CurrencyWithID, error) {
+	return p.StoreWithID(withoutIDs(currency))
+}
+
+func (p postgresStorage) StoreWithID(currency []currencyFetcher.CurrencyWithID) ([]currencyFetcher.CurrencyWithID, error) {
+	tx, err := p.db.Begin()
+
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]currencyFetcher.CurrencyWithID, 0, len(currency))
+	createdAt := make([]time.Time, 0, len(currency))
+
+	for _, cur := range currency {
+		id, err := rateID(p.idGenerator, cur.ID)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+
+		created := cur.CreatedAt
+		if created.IsZero() {
+			created = time.Now()
+		}
+
+		// timestamptz rounds to microseconds, truncating keeps the rate in the second it was created in
+		createdAt = append(createdAt, created.Truncate(time.Microsecond))
+		data = append(data, currencyFetcher.CurrencyWithID{
+			Currency: cur.Currency,
+			ID:       id,
+		})
+	}
+
+	for start := 0; start < len(data); start += postgresInsertBatchSize {
+		end := start + postgresInsertBatchSize
+
+		if end > len(data) {
+			end = len(data)
+		}
+
+		if err := p.insert(tx, data[start:end], createdAt[start:end]); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// insert stores rates with one statement, createdAt holds the time stored for each rate
+func (p postgresStorage) insert(tx *sql.Tx, rates []currencyFetcher.CurrencyWithID, createdAt []time.Time) error {
+	var builder strings.Builder
+
+	bind := make([]interface{}, 0, 5*len(rates))
+
+	for i, cur := range rates {
+		n := i * 5
+		builder.WriteString(fmt.Sprintf("($%d,$%d,$%d,$%d,$%d),", n+1, n+2, n+3, n+4, n+5))
+
+		bind = append(bind, cur.ID, fmt.Sprintf("%s_%s", cur.From, cur.To), cur.Provider, cur.Rate, createdAt[i])
+	}
+
+	stmt, err := tx.PrepareContext(p.ctx, fmt.Sprintf(
+		"INSERT INTO %s(id, currency, provider, rate, created_at) VALUES %s;",
+		p.tableName,
+		strings.TrimRight(builder.String(), ",")),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := stmt.ExecContext(p.ctx, bind...); err != nil {
+		_ = stmt.Close()
+		return err
+	}
+
+	return stmt.Close()
+}
+
+func (p postgresStorage) Pairs() ([]string, error) {
+	return sqlPairs(p.ctx, p.db, p.tableName)
+}
+
+func (p postgresStorage) Get(from, to string, page, perPage int64) ([]currencyFetcher.CurrencyWithID, error) {
+	return p.GetByProvider(from, to, currencyFetcher.EmptyProvider, page, perPage)
+}
+
+func (p postgresStorage) GetByProvider(from, to string, provider currencyFetcher.Provider, page, perPage int64) ([]currencyFetcher.CurrencyWithID, error) {
+	return p.GetByDateAndProvider(from, to, provider, time.Time{}, time.Now(), page, perPage)
+}
+
+func (p postgresStorage) GetByDate(from, to string, start, end time.Time, page, perPage int64) ([]currencyFetcher.CurrencyWithID, error) {
+	return p.GetByDateAndProvider(from, to, currencyFetcher.EmptyProvider, start, end, page, perPage)
+}
+
+func (p postgresStorage) GetByDateAndProvider(from, to string, provider currencyFetcher.Provider, start, end time.Time, page, perPage int64) ([]currencyFetcher.CurrencyWithID, error) {
+	if start.After(end) {
+		return nil, errors.New("start time cannot be after end time")
+	}
+
+	var builder strings.Builder
+
+	bind := []interface{}{fmt.Sprintf("%s_%s", from, to), start, end}
+
+	builder.WriteString("SELECT id,currency,provider,rate,created_at FROM ")
+	builder.WriteString(p.tableName)
+	builder.WriteString(" WHERE currency = $1 AND created_at BETWEEN $2 AND $3")
+
+	if provider != currencyFetcher.EmptyProvider {
+		bind = append(bind, provider)
+		builder.WriteString(fmt.Sprintf(" AND provider = $%d", len(bind)))
+	}
+
+	bind = append(bind, perPage, (page-1)*perPage)
+	builder.WriteString(fmt.Sprintf(" ORDER BY created_at, id LIMIT $%d OFFSET $%d", len(bind)-1, len(bind)))
+
+	rows, err := p.db.QueryContext(p.ctx, builder.String(), bind...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	result := make([]currencyFetcher.CurrencyWithID, 0, perPage)
+
+	for rows.Next() {
+		currencyWithID, err := p.scan(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, currencyWithID)
+	}
+
+	return result, rows.Err()
+}
+
+func (p postgresStorage) Latest(from, to string, provider currencyFetcher.Provider) (currencyFetcher.CurrencyWithID, error) {
+	return p.latest(from, to, provider, time.Time{})
+}
+
+func (p postgresStorage) LatestBefore(from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, error) {
+	return p.latest(from, to, provider, date)
+}
+
+// latest returns the most recent rate stored at or before date, any rate when date is zero
+func (p postgresStorage) latest(from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, error) {
+	var builder strings.Builder
+
+	bind := []interface{}{fmt.Sprintf("%s_%s", from, to)}
+
+	builder.WriteString("SELECT id,currency,provider,rate,created_at FROM ")
+	builder.WriteString(p.tableName)
+	builder.WriteString(" WHERE currency = $1")
+
+	if provider != currencyFetcher.EmptyProvider {
+		bind = append(bind, provider)
+		builder.WriteString(fmt.Sprintf(" AND provider = $%d", len(bind)))
+	}
+
+	if !date.IsZero() {
+		bind = append(bind, date)
+		builder.WriteString(fmt.Sprintf(" AND created_at <= $%d", len(bind)))
+	}
+
+	builder.WriteString(" ORDER BY created_at DESC, id DESC LIMIT 1")
+
+	currencyWithID, err := p.scan(p.db.QueryRowContext(p.ctx, builder.String(), bind...))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return currencyWithID, currencyFetcher.ErrNotFound
+	}
+
+	return currencyWithID, err
+}
+
+func (p postgresStorage) scan(row rowScanner) (currencyFetcher.CurrencyWithID, error) {
+	var currency string
+	var id uuid.UUID
+
+	currencyWithID := currencyFetcher.CurrencyWithID{}
+
+	if err := row.Scan(&id, &currency, &currencyWithID.Provider, &currencyWithID.Rate, &currencyWithID.CreatedAt); err != nil {
+		return currencyWithID, err
+	}
+
+	pair, err := iso4217.SplitPair(currency)
+
+	if err != nil {
+		return currencyWithID, err
+	}
+
+	currencyWithID.ID = id
+	currencyWithID.From = pair.From
+	currencyWithID.To = pair.To
+
+	return currencyWithID, nil
+}
+
+func (p postgresStorage) Migrate() error {
+	_, err := p.db.ExecContext(p.ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s(
+		id uuid PRIMARY KEY,
+		currency varchar(20) NOT NULL,
+		provider varchar(30) NOT NULL,
+		rate numeric(20,8) NOT NULL,
+		created_at timestamptz NOT NULL DEFAULT now()
+	);`, p.tableName))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.ExecContext(p.ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_search_index ON %s(currency, provider, created_at);`, p.tableName, p.tableName))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.ExecContext(p.ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_latest_index ON %s(currency, created_at);`, p.tableName, p.tableName))
+	return err
+}
+
+func (postgresStorage) GetStorageProviderName() string {
+	return PostgreSQLStorageProviderName
+}
+
+func (p *postgresStorage) Close() error {
+	if err := p.db.Close(); err != nil {
+		return fmt.Errorf("disconnecting from PostgreSQL database failed: %v", err)
+	}
+	return nil
+}
+
+func (p postgresStorage) Drop() error {
+	_, err := p.db.ExecContext(p.ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", p.tableName))
+	return err
+}
+
+func NewPostgreSQLStorageFromDB(ctx context.Context, db *sql.DB, generator IDGenerator, tableName string, migrate bool) (currencyFetcher.Storage, error) {
+	storage := &postgresStorage{
+		idGenerator: generator,
+		ctx:         ctx,
+		db:          db,
+		tableName:   tableName,
+	}
+
+	if migrate {
+		if err := storage.Migrate(); err != nil {
+			return nil, fmt.Errorf("error while migrating postgres database: %v", err)
+		}
+	}
+
+	return storage, nil
+}
+
+func NewPostgreSQLStorage(c PostgreSQLConfig) (currencyFetcher.Storage, error) {
+	db, err := sql.Open("postgres", c.ConnectionString)
+
+	if err != nil {
+		return nil, fmt.Errorf("error while connecting to PostgreSQL database: %v", err)
+	}
+
+	return NewPostgreSQLStorageFromDB(c.Cxt, db, c.IDGenerator, c.TableName, c.Migrate)
+}
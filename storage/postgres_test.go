@@ -0,0 +1,198 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/storage"
+)
+
+func TestPostgreSQLStorage_GetStorageProviderName(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	db, _, _ := sqlmock.New()
+	defer db.Close()
+
+	st, err := storage.NewPostgreSQLStorageFromDB(context.Background(), db, nil, "currency", false)
+
+	asserts.Nil(err)
+	asserts.Equal(storage.PostgreSQLStorageProviderName, st.GetStorageProviderName())
+}
+
+func TestPostgreSQLStorage_Migrate(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	m.ExpectExec("CREATE TABLE IF NOT EXISTS currency_migrate\\(").WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec("CREATE INDEX IF NOT EXISTS currency_migrate_search_index ON currency_migrate\\(currency, provider, created_at\\)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec("CREATE INDEX IF NOT EXISTS currency_migrate_latest_index ON currency_migrate\\(currency, created_at\\)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err := storage.NewPostgreSQLStorageFromDB(context.Background(), db, nil, "currency_migrate", true)
+
+	asserts.Nil(err)
+	asserts.Nil(m.ExpectationsWereMet())
+}
+
+func TestPostgreSQLStorage_Store(t *testing.T) {
+	t.Parallel()
+	db, m, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	defer db.Close()
+	ctx := context.Background()
+	st, _ := storage.NewPostgreSQLStorageFromDB(ctx, db, nil, "currency_store", false)
+	createdAt := time.Date(2020, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	currencies := []currency.Currency{
+		{From: "EUR", To: "USD", Provider: "TestProvider", Rate: decimal.RequireFromString("1.17"), CreatedAt: createdAt},
+		{From: "EUR", To: "RSD", Provider: "TestProvider", Rate: decimal.RequireFromString("117.58"), CreatedAt: createdAt},
+	}
+
+	t.Run("Inserts all rows in one statement", func(t *testing.T) {
+		asserts := require.New(t)
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO currency_store(id, currency, provider, rate, created_at) VALUES ($1,$2,$3,$4,$5),($6,$7,$8,$9,$10);").
+			ExpectExec().
+			WithArgs(sqlmock.AnyArg(), "EUR_USD", "TestProvider", "1.17", createdAt, sqlmock.AnyArg(), "EUR_RSD", "TestProvider", "117.58", createdAt).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		m.ExpectCommit()
+
+		stored, err := st.Store(currencies)
+
+		asserts.Nil(err)
+		asserts.Nil(m.ExpectationsWereMet())
+		asserts.Len(stored, 2)
+		asserts.IsType(uuid.UUID{}, stored[0].ID)
+	})
+
+	t.Run("Rollback on error", func(t *testing.T) {
+		asserts := require.New(t)
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO currency_store(id, currency, provider, rate, created_at) VALUES ($1,$2,$3,$4,$5),($6,$7,$8,$9,$10);").
+			WillReturnError(errors.New("cannot create prepare statement"))
+		m.ExpectRollback()
+
+		stored, err := st.Store(currencies)
+
+		asserts.Nil(stored)
+		asserts.Error(err)
+		asserts.Nil(m.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid ID generator", func(t *testing.T) {
+		asserts := require.New(t)
+		generator := &IDGeneratorMock{}
+		generator.On("Generate").Return(make([]byte, 10))
+		st, _ := storage.NewPostgreSQLStorageFromDB(ctx, db, generator, "currency_store", false)
+
+		m.ExpectBegin()
+		m.ExpectRollback()
+
+		stored, err := st.Store(currencies)
+
+		asserts.Nil(stored)
+		asserts.True(errors.Is(err, storage.ErrNotEnoughBytesInGenerator))
+		asserts.Nil(m.ExpectationsWereMet())
+	})
+}
+
+func TestPostgreSQLStorage_StoreInBatches(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+	st, _ := storage.NewPostgreSQLStorageFromDB(context.Background(), db, nil, "currency_store", false)
+	createdAt := time.Date(2020, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	// One rate more than fits into 65535 bind parameters
+	currencies := make([]currency.Currency, 65535/5+1)
+
+	for i := range currencies {
+		currencies[i] = currency.Currency{From: "EUR", To: "USD", Provider: "TestProvider", Rate: decimal.NewFromInt(int64(i)), CreatedAt: createdAt}
+	}
+
+	m.ExpectBegin()
+	m.ExpectPrepare(`\(\$65531,\$65532,\$65533,\$65534,\$65535\);$`).
+		ExpectExec().
+		WillReturnResult(sqlmock.NewResult(0, 65535/5))
+	m.ExpectPrepare(`VALUES \(\$1,\$2,\$3,\$4,\$5\);$`).
+		ExpectExec().
+		WithArgs(sqlmock.AnyArg(), "EUR_USD", "TestProvider", decimal.NewFromInt(65535/5), createdAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	m.ExpectCommit()
+
+	stored, err := st.Store(currencies)
+
+	asserts.Nil(err)
+	asserts.Nil(m.ExpectationsWereMet())
+	asserts.Len(stored, len(currencies))
+}
+
+func TestPostgreSQLStorage_GetByDateAndProvider(t *testing.T) {
+	t.Parallel()
+	db, m, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	defer db.Close()
+	st, _ := storage.NewPostgreSQLStorageFromDB(context.Background(), db, nil, "currency_get", false)
+	start := time.Date(2020, 10, 16, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	id := uuid.New()
+
+	t.Run("With provider", func(t *testing.T) {
+		asserts := require.New(t)
+		m.ExpectQuery("SELECT id,currency,provider,rate,created_at FROM currency_get WHERE currency = $1 AND created_at BETWEEN $2 AND $3 AND provider = $4 ORDER BY created_at, id LIMIT $5 OFFSET $6").
+			WithArgs("EUR_USD", start, end, "TestProvider", int64(10), int64(10)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "currency", "provider", "rate", "created_at"}).
+				AddRow(id.String(), "EUR_USD", "TestProvider", "1.17080000", start))
+
+		result, err := st.GetByDateAndProvider("EUR", "USD", "TestProvider", start, end, 2, 10)
+
+		asserts.Nil(err)
+		asserts.Nil(m.ExpectationsWereMet())
+		asserts.Len(result, 1)
+		asserts.Equal("1.1708", result[0].Rate.String())
+
+		result[0].Rate = decimal.Decimal{}
+		asserts.Equal([]currency.CurrencyWithID{
+			{
+				ID: id,
+				Currency: currency.Currency{
+					From:      "EUR",
+					To:        "USD",
+					Provider:  "TestProvider",
+					CreatedAt: start,
+				},
+			},
+		}, result)
+	})
+
+	t.Run("Without provider", func(t *testing.T) {
+		asserts := require.New(t)
+		m.ExpectQuery("SELECT id,currency,provider,rate,created_at FROM currency_get WHERE currency = $1 AND created_at BETWEEN $2 AND $3 ORDER BY created_at, id LIMIT $4 OFFSET $5").
+			WithArgs("EUR_USD", start, end, int64(10), int64(0)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "currency", "provider", "rate", "created_at"}))
+
+		result, err := st.GetByDate("EUR", "USD", start, end, 1, 10)
+
+		asserts.Nil(err)
+		asserts.Nil(m.ExpectationsWereMet())
+		asserts.Empty(result)
+	})
+
+	t.Run("Start after end", func(t *testing.T) {
+		asserts := require.New(t)
+		result, err := st.GetByDate("EUR", "USD", end, start, 1, 10)
+
+		asserts.Nil(result)
+		asserts.Error(err)
+	})
+}
@@ -0,0 +1,455 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	currencyFetcher "github.com/malusev998/currency"
+)
+
+const (
+	// quotaRetention is how long windows are kept after they start
+	quotaRetention  = 24 * time.Hour
+	quotaLockWait   = 5 * time.Second
+	quotaStaleLock  = 30 * time.Second
+	quotaLockSuffix = ".lock"
+)
+
+var ErrQuotaLocked = errors.New("quota file is locked")
+
+type (
+	SQLQuotaConfig struct {
+		BaseConfig
+		// Driver is MySQL, PostgreSQL or SQLite
+		Driver           Provider
+		ConnectionString string
+		TableName        string
+	}
+
+	// quotaWindows holds used requests by provider and window start in unix seconds
+	quotaWindows map[currencyFetcher.Provider]map[string]int
+
+	memoryQuotaStorage struct {
+		mutex   sync.Mutex
+		windows quotaWindows
+	}
+
+	fileQuotaStorage struct {
+		mutex sync.Mutex
+		path  string
+	}
+
+	sqlQuotaStorage struct {
+		ctx       context.Context
+		db        *sql.DB
+		driver    Provider
+		tableName string
+	}
+)
+
+func quotaKey(window time.Time) string {
+	return strconv.FormatInt(window.Unix(), 10)
+}
+
+// reserve updates windows in place, windows older than quotaRetention are removed
+func (w quotaWindows) reserve(provider currencyFetcher.Provider, window time.Time, n, limit int) (int, error) {
+	windows, ok := w[provider]
+
+	if !ok {
+		windows = make(map[string]int)
+		w[provider] = windows
+	}
+
+	oldest := window.Add(-quotaRetention).Unix()
+
+	for key := range windows {
+		if start, err := strconv.ParseInt(key, 10, 64); err != nil || start < oldest {
+			delete(windows, key)
+		}
+	}
+
+	used := windows[quotaKey(window)]
+
+	if used+n > limit {
+		return used, currencyFetcher.ErrQuotaExceeded
+	}
+
+	windows[quotaKey(window)] = used + n
+
+	return used + n, nil
+}
+
+func (w quotaWindows) used(provider currencyFetcher.Provider, window time.Time) int {
+	return w[provider][quotaKey(window)]
+}
+
+// NewMemoryQuotaStorage keeps quota in memory, usage is lost on restart
+// and is not shared between processes.
+func NewMemoryQuotaStorage() currencyFetcher.QuotaStorage {
+	return &memoryQuotaStorage{windows: make(quotaWindows)}
+}
+
+func (m *memoryQuotaStorage) Reserve(provider currencyFetcher.Provider, window time.Time, n, limit int) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.windows.reserve(provider, window, n, limit)
+}
+
+func (m *memoryQuotaStorage) Used(provider currencyFetcher.Provider, window time.Time) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.windows.used(provider, window), nil
+}
+
+func (*memoryQuotaStorage) Close() error {
+	return nil
+}
+
+// NewFileQuotaStorage keeps quota in a JSON file at path. Processes sharing the file
+// take turns through a lock file next to it, locks older than 30 seconds are considered stale.
+func NewFileQuotaStorage(path string) currencyFetcher.QuotaStorage {
+	return &fileQuotaStorage{path: path}
+}
+
+func (f *fileQuotaStorage) lock() (func(), error) {
+	lockPath := f.path + quotaLockSuffix
+	deadline := time.Now().Add(quotaLockWait)
+
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+
+		if err == nil {
+			_ = file.Close()
+
+			return func() {
+				_ = os.Remove(lockPath)
+			}, nil
+		}
+
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > quotaStaleLock {
+			_ = os.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: %s", ErrQuotaLocked, lockPath)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (f *fileQuotaStorage) read() (quotaWindows, error) {
+	windows := make(quotaWindows)
+	data, err := ioutil.ReadFile(f.path)
+
+	if os.IsNotExist(err) {
+		return windows, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return windows, nil
+	}
+
+	if err := json.Unmarshal(data, &windows); err != nil {
+		return nil, fmt.Errorf("error while reading quota file %s: %v", f.path, err)
+	}
+
+	return windows, nil
+}
+
+// write replaces the file through a rename, so readers never see a partial file
+func (f *fileQuotaStorage) write(windows quotaWindows) error {
+	data, err := json.Marshal(windows)
+
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path)
+}
+
+func (f *fileQuotaStorage) Reserve(provider currencyFetcher.Provider, window time.Time, n, limit int) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	unlock, err := f.lock()
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer unlock()
+
+	windows, err := f.read()
+
+	if err != nil {
+		return 0, err
+	}
+
+	used, err := windows.reserve(provider, window, n, limit)
+
+	if err != nil {
+		return used, err
+	}
+
+	if err := f.write(windows); err != nil {
+		return 0, err
+	}
+
+	return used, nil
+}
+
+func (f *fileQuotaStorage) Used(provider currencyFetcher.Provider, window time.Time) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	windows, err := f.read()
+
+	if err != nil {
+		return 0, err
+	}
+
+	return windows.used(provider, window), nil
+}
+
+func (*fileQuotaStorage) Close() error {
+	return nil
+}
+
+// NewSQLQuotaStorage keeps quota in a MySQL, PostgreSQL or SQLite table,
+// so every replica using the same database shares it.
+func NewSQLQuotaStorage(c SQLQuotaConfig) (currencyFetcher.QuotaStorage, error) {
+	var driverName string
+
+	switch c.Driver {
+	case MySQL:
+		driverName = "mysql"
+	case PostgreSQL:
+		driverName = "postgres"
+	case SQLite:
+		driverName = "sqlite"
+	default:
+		return nil, fmt.Errorf("%w: quota cannot be stored in %s", ErrStorageNotFound, c.Driver)
+	}
+
+	db, err := sql.Open(driverName, c.ConnectionString)
+
+	if err != nil {
+		return nil, fmt.Errorf("error while opening %s quota database: %v", c.Driver, err)
+	}
+
+	if c.Driver == SQLite {
+		db.SetMaxOpenConns(1)
+	}
+
+	ctx := c.Cxt
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	storage := &sqlQuotaStorage{
+		ctx:       ctx,
+		db:        db,
+		driver:    c.Driver,
+		tableName: c.TableName,
+	}
+
+	if c.Migrate {
+		if err := storage.Migrate(); err != nil {
+			return nil, fmt.Errorf("error while migrating quota table: %v", err)
+		}
+	}
+
+	return storage, nil
+}
+
+// query replaces ? placeholders with $n for PostgreSQL
+func (s *sqlQuotaStorage) query(query string) string {
+	if s.driver != PostgreSQL {
+		return query
+	}
+
+	var builder strings.Builder
+
+	n := 0
+
+	for _, r := range query {
+		if r == '?' {
+			n++
+			builder.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
+
+func (s *sqlQuotaStorage) Migrate() error {
+	_, err := s.db.ExecContext(s.ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s(provider VARCHAR(50) NOT NULL, window_start BIGINT NOT NULL, used INTEGER NOT NULL DEFAULT 0, PRIMARY KEY(provider, window_start));",
+		s.tableName,
+	))
+
+	return err
+}
+
+func (s *sqlQuotaStorage) insertWindowQuery() string {
+	switch s.driver {
+	case MySQL:
+		return fmt.Sprintf("INSERT IGNORE INTO %s(provider, window_start, used) VALUES (?, ?, 0);", s.tableName)
+	case SQLite:
+		return fmt.Sprintf("INSERT OR IGNORE INTO %s(provider, window_start, used) VALUES (?, ?, 0);", s.tableName)
+	default:
+		return fmt.Sprintf("INSERT INTO %s(provider, window_start, used) VALUES (?, ?, 0) ON CONFLICT DO NOTHING;", s.tableName)
+	}
+}
+
+func (s *sqlQuotaStorage) Reserve(provider currencyFetcher.Provider, window time.Time, n, limit int) (int, error) {
+	tx, err := s.db.BeginTx(s.ctx, nil)
+
+	if err != nil {
+		return 0, err
+	}
+
+	start := window.Unix()
+
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{
+			query: fmt.Sprintf("DELETE FROM %s WHERE provider = ? AND window_start < ?;", s.tableName),
+			args:  []interface{}{string(provider), window.Add(-quotaRetention).Unix()},
+		},
+		{
+			query: s.insertWindowQuery(),
+			args:  []interface{}{string(provider), start},
+		},
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(s.ctx, s.query(statement.query), statement.args...); err != nil {
+			_ = tx.Rollback()
+			return 0, err
+		}
+	}
+
+	exceeded := false
+
+	// MySQL reports changed rows, so nothing is updated when no requests are reserved
+	if n > 0 {
+		// The condition is checked by the database, so concurrent reservations cannot exceed the limit
+		res, err := tx.ExecContext(
+			s.ctx,
+			s.query(fmt.Sprintf("UPDATE %s SET used = used + ? WHERE provider = ? AND window_start = ? AND used + ? <= ?;", s.tableName)),
+			n, string(provider), start, n, limit,
+		)
+
+		if err != nil {
+			_ = tx.Rollback()
+			return 0, err
+		}
+
+		affected, err := res.RowsAffected()
+
+		if err != nil {
+			_ = tx.Rollback()
+			return 0, err
+		}
+
+		exceeded = affected == 0
+	}
+
+	var used int
+
+	err = tx.QueryRowContext(
+		s.ctx,
+		s.query(fmt.Sprintf("SELECT used FROM %s WHERE provider = ? AND window_start = ?;", s.tableName)),
+		string(provider), start,
+	).Scan(&used)
+
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	if exceeded {
+		return used, currencyFetcher.ErrQuotaExceeded
+	}
+
+	return used, nil
+}
+
+func (s *sqlQuotaStorage) Used(provider currencyFetcher.Provider, window time.Time) (int, error) {
+	var used int
+
+	err := s.db.QueryRowContext(
+		s.ctx,
+		s.query(fmt.Sprintf("SELECT used FROM %s WHERE provider = ? AND window_start = ?;", s.tableName)),
+		string(provider), window.Unix(),
+	).Scan(&used)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return used, err
+}
+
+func (s *sqlQuotaStorage) Drop() error {
+	_, err := s.db.ExecContext(s.ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", s.tableName))
+	return err
+}
+
+func (s *sqlQuotaStorage) Close() error {
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("closing %s quota database failed: %v", s.driver, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package storage_test
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/storage"
+)
+
+func testQuotaStorage(t *testing.T, quota currency.QuotaStorage) {
+	window := time.Date(2020, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Reserves within limit", func(t *testing.T) {
+		asserts := require.New(t)
+
+		used, err := quota.Reserve(currency.FreeConvProvider, window, 60, 100)
+		asserts.Nil(err)
+		asserts.Equal(60, used)
+
+		used, err = quota.Reserve(currency.FreeConvProvider, window, 40, 100)
+		asserts.Nil(err)
+		asserts.Equal(100, used)
+
+		used, err = quota.Reserve(currency.FreeConvProvider, window, 1, 100)
+		asserts.True(errors.Is(err, currency.ErrQuotaExceeded))
+		asserts.Equal(100, used)
+
+		// Fetches without requests reserve nothing, even in a full window
+		used, err = quota.Reserve(currency.FreeConvProvider, window, 0, 100)
+		asserts.Nil(err)
+		asserts.Equal(100, used)
+
+		used, err = quota.Used(currency.FreeConvProvider, window)
+		asserts.Nil(err)
+		asserts.Equal(100, used)
+	})
+
+	t.Run("Windows and providers are separate", func(t *testing.T) {
+		asserts := require.New(t)
+
+		used, err := quota.Reserve(currency.FreeConvProvider, window.Add(time.Hour), 10, 100)
+		asserts.Nil(err)
+		asserts.Equal(10, used)
+
+		used, err = quota.Used(currency.ExchangeRatesAPIProvider, window)
+		asserts.Nil(err)
+		asserts.Zero(used)
+	})
+
+	t.Run("Concurrent reservations stay within limit", func(t *testing.T) {
+		asserts := require.New(t)
+		next := window.Add(2 * time.Hour)
+
+		var wg sync.WaitGroup
+		var mutex sync.Mutex
+		reserved := 0
+
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				if _, err := quota.Reserve(currency.FreeConvProvider, next, 1, 10); err == nil {
+					mutex.Lock()
+					reserved++
+					mutex.Unlock()
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		used, err := quota.Used(currency.FreeConvProvider, next)
+		asserts.Nil(err)
+		asserts.Equal(10, reserved)
+		asserts.Equal(10, used)
+	})
+
+	t.Run("Old windows are removed", func(t *testing.T) {
+		asserts := require.New(t)
+
+		_, err := quota.Reserve(currency.FreeConvProvider, window.Add(48*time.Hour), 1, 100)
+		asserts.Nil(err)
+
+		used, err := quota.Used(currency.FreeConvProvider, window)
+		asserts.Nil(err)
+		asserts.Zero(used)
+	})
+}
+
+func TestMemoryQuotaStorage(t *testing.T) {
+	t.Parallel()
+	testQuotaStorage(t, storage.NewMemoryQuotaStorage())
+}
+
+func TestFileQuotaStorage(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "quota.json")
+
+	testQuotaStorage(t, storage.NewFileQuotaStorage(path))
+
+	t.Run("Shared between instances", func(t *testing.T) {
+		asserts := require.New(t)
+		window := time.Date(2020, 10, 20, 12, 0, 0, 0, time.UTC)
+		first := storage.NewFileQuotaStorage(path)
+		second := storage.NewFileQuotaStorage(path)
+
+		_, err := first.Reserve(currency.FreeConvProvider, window, 7, 10)
+		asserts.Nil(err)
+
+		_, err = second.Reserve(currency.FreeConvProvider, window, 4, 10)
+		asserts.True(errors.Is(err, currency.ErrQuotaExceeded))
+
+		used, err := second.Used(currency.FreeConvProvider, window)
+		asserts.Nil(err)
+		asserts.Equal(7, used)
+	})
+}
+
+func TestSQLiteQuotaStorage(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+
+	quota, err := storage.NewSQLQuotaStorage(storage.SQLQuotaConfig{
+		BaseConfig:       storage.BaseConfig{Migrate: true},
+		Driver:           storage.SQLite,
+		ConnectionString: filepath.Join(t.TempDir(), "quota.db"),
+		TableName:        "currency_quota",
+	})
+	asserts.Nil(err)
+	defer quota.Close()
+
+	testQuotaStorage(t, quota)
+}
+
+func TestSQLQuotaStorage_UnsupportedDriver(t *testing.T) {
+	_, err := storage.NewSQLQuotaStorage(storage.SQLQuotaConfig{Driver: storage.MongoDB})
+
+	require.True(t, errors.Is(err, storage.ErrStorageNotFound))
+}
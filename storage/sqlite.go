@@ -0,0 +1,337 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	_ "modernc.org/sqlite"
+
+	currencyFetcher "github.com/malusev998/currency"
+	"github.com/malusev998/currency/iso4217"
+)
+
+const (
+	SQLiteStorageProviderName = "sqlite"
+	// SQLiteTimeFormat is fixed width so created_at values are ordered correctly as text
+	SQLiteTimeFormat = "2006-01-02 15:04:05.000000000"
+)
+
+type sqliteStorage struct {
+	idGenerator IDGenerator
+	ctx         context.Context
+	db          *sql.DB
+	tableName   string
+}
+
+func (s sqliteStorage) Store(currency []currencyFetcher.Currency) ([]currencyFetcher.CurrencyWithID, error) {
+	return s.StoreWithID(withoutIDs(currency))
+}
+
+func (s sqliteStorage) StoreWithID(currency []currencyFetcher.CurrencyWithID) ([]currencyFetcher.CurrencyWithID, error) {
+	tx, err := s.db.BeginTx(s.ctx, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var builder strings.Builder
+
+	bind := make([]interface{}, 0, 5*len(currency))
+
+	data := make([]currencyFetcher.CurrencyWithID, 0, len(currency))
+
+	for _, cur := range currency {
+		id, err := rateID(s.idGenerator, cur.ID)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+
+		createdAt := cur.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+
+		builder.WriteString("(?,?,?,?,?),")
+
+		bind = append(bind, id.String(), fmt.Sprintf("%s_%s", cur.From, cur.To), string(cur.Provider), cur.Rate.String(), createdAt.UTC().Format(SQLiteTimeFormat))
+		data = append(data, currencyFetcher.CurrencyWithID{
+			Currency: cur.Currency,
+			ID:       id,
+		})
+	}
+
+	_, err = tx.ExecContext(s.ctx, fmt.Sprintf(
+		"INSERT INTO %s(id, currency, provider, rate, created_at) VALUES %s;",
+		s.tableName,
+		strings.TrimRight(builder.String(), ",")),
+		bind...,
+	)
+
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (s sqliteStorage) Pairs() ([]string, error) {
+	return sqlPairs(s.ctx, s.db, s.tableName)
+}
+
+func (s sqliteStorage) Get(from, to string, page, perPage int64) ([]currencyFetcher.CurrencyWithID, error) {
+	return s.GetByProvider(from, to, currencyFetcher.EmptyProvider, page, perPage)
+}
+
+func (s sqliteStorage) GetByProvider(from, to string, provider currencyFetcher.Provider, page, perPage int64) ([]currencyFetcher.CurrencyWithID, error) {
+	return s.GetByDateAndProvider(from, to, provider, time.Time{}, time.Now(), page, perPage)
+}
+
+func (s sqliteStorage) GetByDate(from, to string, start, end time.Time, page, perPage int64) ([]currencyFetcher.CurrencyWithID, error) {
+	return s.GetByDateAndProvider(from, to, currencyFetcher.EmptyProvider, start, end, page, perPage)
+}
+
+func (s sqliteStorage) GetByDateAndProvider(from, to string, provider currencyFetcher.Provider, start, end time.Time, page, perPage int64) ([]currencyFetcher.CurrencyWithID, error) {
+	if start.After(end) {
+		return nil, errors.New("start time cannot be after end time")
+	}
+
+	var builder strings.Builder
+
+	bind := []interface{}{
+		fmt.Sprintf("%s_%s", from, to),
+		start.UTC().Format(SQLiteTimeFormat),
+		end.UTC().Format(SQLiteTimeFormat),
+	}
+
+	builder.WriteString("SELECT id,currency,provider,rate,created_at FROM ")
+	builder.WriteString(s.tableName)
+	builder.WriteString(" WHERE currency = ? AND created_at BETWEEN ? AND ?")
+
+	if provider != currencyFetcher.EmptyProvider {
+		builder.WriteString(" AND provider = ?")
+		bind = append(bind, string(provider))
+	}
+
+	builder.WriteString(" ORDER BY created_at, id LIMIT ?, ?")
+	bind = append(bind, (page-1)*perPage, perPage)
+
+	rows, err := s.db.QueryContext(s.ctx, builder.String(), bind...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	result := make([]currencyFetcher.CurrencyWithID, 0, perPage)
+
+	for rows.Next() {
+		currencyWithID, err := s.scan(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, currencyWithID)
+	}
+
+	return result, rows.Err()
+}
+
+func (s sqliteStorage) Latest(from, to string, provider currencyFetcher.Provider) (currencyFetcher.CurrencyWithID, error) {
+	return s.latest(from, to, provider, time.Time{})
+}
+
+func (s sqliteStorage) LatestBefore(from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, error) {
+	return s.latest(from, to, provider, date)
+}
+
+// latest returns the most recent rate stored at or before date, any rate when date is zero
+func (s sqliteStorage) latest(from, to string, provider currencyFetcher.Provider, date time.Time) (currencyFetcher.CurrencyWithID, error) {
+	var builder strings.Builder
+
+	bind := []interface{}{fmt.Sprintf("%s_%s", from, to)}
+
+	builder.WriteString("SELECT id,currency,provider,rate,created_at FROM ")
+	builder.WriteString(s.tableName)
+	builder.WriteString(" WHERE currency = ?")
+
+	if provider != currencyFetcher.EmptyProvider {
+		builder.WriteString(" AND provider = ?")
+		bind = append(bind, string(provider))
+	}
+
+	if !date.IsZero() {
+		builder.WriteString(" AND created_at <= ?")
+		bind = append(bind, date.UTC().Format(SQLiteTimeFormat))
+	}
+
+	builder.WriteString(" ORDER BY created_at DESC, id DESC LIMIT 1")
+
+	currencyWithID, err := s.scan(s.db.QueryRowContext(s.ctx, builder.String(), bind...))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return currencyWithID, currencyFetcher.ErrNotFound
+	}
+
+	return currencyWithID, err
+}
+
+func (s sqliteStorage) scan(row rowScanner) (currencyFetcher.CurrencyWithID, error) {
+	var currency string
+	var createdAt string
+	var id uuid.UUID
+
+	currencyWithID := currencyFetcher.CurrencyWithID{}
+
+	if err := row.Scan(&id, &currency, &currencyWithID.Provider, &currencyWithID.Rate, &createdAt); err != nil {
+		return currencyWithID, err
+	}
+
+	currencyWithID.CreatedAt, _ = time.Parse(SQLiteTimeFormat, createdAt)
+
+	pair, err := iso4217.SplitPair(currency)
+
+	if err != nil {
+		return currencyWithID, err
+	}
+
+	currencyWithID.ID = id
+	currencyWithID.From = pair.From
+	currencyWithID.To = pair.To
+
+	return currencyWithID, nil
+}
+
+// Rate is stored as text, columns with real or numeric affinity would round it to a float
+func sqliteCreateTableQuery(tableName string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s(
+		id text PRIMARY KEY,
+		currency text NOT NULL,
+		provider text NOT NULL,
+		rate text NOT NULL,
+		created_at text NOT NULL
+	);`, tableName)
+}
+
+// migrateRateToText rebuilds tables created before rates became decimals,
+// SQLite cannot change the type of an existing column.
+func (s sqliteStorage) migrateRateToText() error {
+	var rateType string
+
+	err := s.db.QueryRowContext(s.ctx, "SELECT type FROM pragma_table_info(?) WHERE name = 'rate'", s.tableName).Scan(&rateType)
+
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(rateType, "text") {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(s.ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	oldTableName := s.tableName + "_float_rates"
+	statements := []string{
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", s.tableName, oldTableName),
+		sqliteCreateTableQuery(s.tableName),
+		fmt.Sprintf(
+			"INSERT INTO %s(id, currency, provider, rate, created_at) SELECT id, currency, provider, CAST(rate AS TEXT), created_at FROM %s;",
+			s.tableName,
+			oldTableName,
+		),
+		fmt.Sprintf("DROP TABLE %s;", oldTableName),
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(s.ctx, statement); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s sqliteStorage) Migrate() error {
+	if _, err := s.db.ExecContext(s.ctx, sqliteCreateTableQuery(s.tableName)); err != nil {
+		return err
+	}
+
+	if err := s.migrateRateToText(); err != nil {
+		return fmt.Errorf("error while migrating rates to text: %v", err)
+	}
+
+	_, err := s.db.ExecContext(s.ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_search_index ON %s(currency, provider, created_at);`, s.tableName, s.tableName))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(s.ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_latest_index ON %s(currency, created_at);`, s.tableName, s.tableName))
+	return err
+}
+
+func (sqliteStorage) GetStorageProviderName() string {
+	return SQLiteStorageProviderName
+}
+
+func (s *sqliteStorage) Close() error {
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("closing SQLite database failed: %v", err)
+	}
+	return nil
+}
+
+func (s sqliteStorage) Drop() error {
+	_, err := s.db.ExecContext(s.ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", s.tableName))
+	return err
+}
+
+func NewSQLiteStorage(c SQLiteConfig) (currencyFetcher.Storage, error) {
+	db, err := sql.Open("sqlite", c.Path)
+
+	if err != nil {
+		return nil, fmt.Errorf("error while opening SQLite database: %v", err)
+	}
+
+	// SQLite allows only one writer, and every connection to ":memory:" is a new database
+	db.SetMaxOpenConns(1)
+
+	ctx := c.Cxt
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	storage := &sqliteStorage{
+		idGenerator: c.IDGenerator,
+		ctx:         ctx,
+		db:          db,
+		tableName:   c.TableName,
+	}
+
+	if c.Migrate {
+		if err := storage.Migrate(); err != nil {
+			return nil, fmt.Errorf("error while migrating sqlite database: %v", err)
+		}
+	}
+
+	return storage, nil
+}
@@ -0,0 +1,178 @@
+package storage_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/storage"
+)
+
+func newSQLiteStorage(t *testing.T) currency.Storage {
+	st, err := storage.NewSQLiteStorage(storage.SQLiteConfig{
+		BaseConfig: storage.BaseConfig{
+			Cxt:     context.Background(),
+			Migrate: true,
+		},
+		Path:      ":memory:",
+		TableName: "currency",
+	})
+
+	require.Nil(t, err)
+
+	return st
+}
+
+func TestSQLiteStorage_Store(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	st := newSQLiteStorage(t)
+	defer st.Close()
+
+	currencies, err := st.Store([]currency.Currency{
+		{From: "EUR", To: "USD", Provider: "TestProvider", Rate: decimal.RequireFromString("1.17")},
+		{From: "EUR", To: "RSD", Provider: "TestProvider", Rate: decimal.RequireFromString("117.58")},
+	})
+
+	asserts.Nil(err)
+	asserts.Len(currencies, 2)
+
+	for _, c := range currencies {
+		asserts.IsType(uuid.UUID{}, c.ID)
+	}
+
+	result, err := st.Get("EUR", "RSD", 1, 10)
+
+	asserts.Nil(err)
+	asserts.Len(result, 1)
+	asserts.Equal(currencies[1].ID, result[0].ID)
+	asserts.Equal("117.58", result[0].Rate.String())
+}
+
+func TestSQLiteStorage_GetByDateAndProvider(t *testing.T) {
+	t.Parallel()
+	st := newSQLiteStorage(t)
+	defer st.Close()
+
+	start := time.Date(2020, 10, 16, 0, 0, 0, 0, time.UTC)
+	seed := make([]currency.Currency, 0, 20)
+
+	for i := 0; i < 10; i++ {
+		seed = append(seed,
+			currency.Currency{From: "EUR", To: "USD", Provider: "First", Rate: decimal.NewFromInt(int64(i)), CreatedAt: start.Add(time.Duration(i) * time.Hour)},
+			currency.Currency{From: "EUR", To: "USD", Provider: "Second", Rate: decimal.NewFromInt(int64(i)), CreatedAt: start.Add(time.Duration(i) * time.Hour)},
+		)
+	}
+
+	_, err := st.Store(seed)
+	require.Nil(t, err)
+
+	t.Run("Ascending and paginated", func(t *testing.T) {
+		asserts := require.New(t)
+		result, err := st.GetByDateAndProvider("EUR", "USD", "First", start, start.Add(24*time.Hour), 2, 3)
+
+		asserts.Nil(err)
+		asserts.Len(result, 3)
+
+		for i, c := range result {
+			asserts.Equal(currency.Provider("First"), c.Provider)
+			asserts.True(decimal.NewFromInt(int64(i + 3)).Equal(c.Rate))
+			asserts.True(start.Add(time.Duration(i+3) * time.Hour).Equal(c.CreatedAt))
+		}
+	})
+
+	t.Run("Inclusive bounds", func(t *testing.T) {
+		asserts := require.New(t)
+		result, err := st.GetByDate("EUR", "USD", start.Add(time.Hour), start.Add(2*time.Hour), 1, 10)
+
+		asserts.Nil(err)
+		asserts.Len(result, 4)
+	})
+
+	t.Run("Start after end", func(t *testing.T) {
+		asserts := require.New(t)
+		result, err := st.GetByDate("EUR", "USD", start.Add(time.Hour), start, 1, 10)
+
+		asserts.Nil(result)
+		asserts.Error(err)
+	})
+}
+
+func TestSQLiteStorage_MigrateAndDrop(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	path := filepath.Join(t.TempDir(), "currency.db")
+
+	st, err := storage.NewSQLiteStorage(storage.SQLiteConfig{
+		BaseConfig: storage.BaseConfig{Cxt: context.Background(), Migrate: true},
+		Path:       path,
+		TableName:  "currency",
+	})
+	asserts.Nil(err)
+	defer st.Close()
+
+	asserts.Equal(storage.SQLiteStorageProviderName, st.GetStorageProviderName())
+	asserts.Nil(st.Migrate())
+	asserts.Nil(st.Drop())
+	asserts.Nil(st.Drop())
+
+	_, err = st.Get("EUR", "USD", 1, 10)
+	asserts.Error(err)
+}
+
+func TestSQLiteStorage_MigrateFloatRates(t *testing.T) {
+	t.Parallel()
+	asserts := require.New(t)
+	path := filepath.Join(t.TempDir(), "currency.db")
+
+	// Schema used before rates became decimals
+	db, err := sql.Open("sqlite", path)
+	asserts.Nil(err)
+	_, err = db.Exec(`CREATE TABLE currency(
+		id text PRIMARY KEY,
+		currency text NOT NULL,
+		provider text NOT NULL,
+		rate real NOT NULL,
+		created_at text NOT NULL
+	);`)
+	asserts.Nil(err)
+	_, err = db.Exec(
+		"INSERT INTO currency(id, currency, provider, rate, created_at) VALUES (?, 'EUR_RSD', 'TestProvider', 117.58, '2020-10-16 12:00:00.000000000');",
+		uuid.New().String(),
+	)
+	asserts.Nil(err)
+	asserts.Nil(db.Close())
+
+	st, err := storage.NewSQLiteStorage(storage.SQLiteConfig{
+		BaseConfig: storage.BaseConfig{Cxt: context.Background(), Migrate: true},
+		Path:       path,
+		TableName:  "currency",
+	})
+	asserts.Nil(err)
+	defer st.Close()
+
+	_, err = st.Store([]currency.Currency{
+		{From: "EUR", To: "RSD", Provider: "TestProvider", Rate: decimal.RequireFromString("117.58123456"), CreatedAt: time.Date(2020, 10, 16, 13, 0, 0, 0, time.UTC)},
+	})
+	asserts.Nil(err)
+
+	result, err := st.Get("EUR", "RSD", 1, 10)
+
+	asserts.Nil(err)
+	asserts.Len(result, 2)
+	asserts.Equal("117.58", result[0].Rate.String())
+	asserts.Equal("117.58123456", result[1].Rate.String())
+
+	// Migrating again keeps the data
+	asserts.Nil(st.Migrate())
+	result, err = st.Get("EUR", "RSD", 1, 10)
+	asserts.Nil(err)
+	asserts.Len(result, 2)
+}
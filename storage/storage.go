@@ -21,6 +21,25 @@ type (
 		TableName        string
 		IDGenerator      IDGenerator
 	}
+	PostgreSQLConfig struct {
+		BaseConfig
+		ConnectionString string
+		TableName        string
+		IDGenerator      IDGenerator
+	}
+	SQLiteConfig struct {
+		BaseConfig
+		Path        string
+		TableName   string
+		IDGenerator IDGenerator
+	}
+	MemoryConfig struct {
+		BaseConfig
+		// MaxEntries bounds the number of stored rates, oldest inserted are evicted first.
+		// Zero means unbounded.
+		MaxEntries  int
+		IDGenerator IDGenerator
+	}
 	MongoDBConfig struct {
 		BaseConfig
 		ConnectionString string
@@ -30,8 +49,11 @@ type (
 )
 
 const (
-	MySQL   Provider = "mysql"
-	MongoDB Provider = "mongodb"
+	MySQL      Provider = "mysql"
+	MongoDB    Provider = "mongodb"
+	PostgreSQL Provider = "postgres"
+	SQLite     Provider = "sqlite"
+	Memory     Provider = "memory"
 )
 
 var (
@@ -59,6 +81,12 @@ func ConvertToProviderFromString(str string) (Provider, error) {
 		return MySQL, nil
 	case "mongodb":
 		return MongoDB, nil
+	case "postgres", "postgresql":
+		return PostgreSQL, nil
+	case "sqlite":
+		return SQLite, nil
+	case "memory":
+		return Memory, nil
 	}
 
 	return "", fmt.Errorf("value %s is not valid Provider", str)
@@ -71,6 +99,12 @@ func NewStorage(provider Provider, config interface{}) (currencyFetcher.Storage,
 	case MongoDB:
 		c := config.(MongoDBConfig)
 		return NewMongoStorage(c)
+	case PostgreSQL:
+		return NewPostgreSQLStorage(config.(PostgreSQLConfig))
+	case SQLite:
+		return NewSQLiteStorage(config.(SQLiteConfig))
+	case Memory:
+		return NewMemoryStorage(config.(MemoryConfig))
 	}
 
 	return nil, ErrStorageNotFound
@@ -0,0 +1,487 @@
+// Package storagetest provides a conformance suite every currency.Storage
+// implementation has to pass, so all backends behave the same way:
+//
+//   - rates are returned sorted by CreatedAt ascending
+//   - page starts from 1, perPage limits the number of returned rates
+//   - rates with the same CreatedAt are neither skipped nor repeated between pages
+//   - both start and end of the date range are inclusive
+//   - EmptyProvider matches rates from every provider
+//   - Store returns the same IDs later returned by Get*
+//   - Latest returns the rate with the greatest CreatedAt or currency.ErrNotFound,
+//     the last one in page order among rates with the same CreatedAt (when currency.LatestStorage is implemented)
+//   - LatestBefore does the same for rates stored at or before the given time (when currency.LatestStorage is implemented)
+//   - Migrate and Drop can be called multiple times
+//   - rates with up to 8 decimal places are returned exactly as stored
+//   - Pairs lists every stored pair once, sorted (when currency.PairLister is implemented)
+//   - StoreWithID keeps IDs the storage can use and fails for IDs already stored (when currency.IDStorage is implemented)
+//
+// Timestamps used by the suite fit into the least precise backend (whole seconds).
+package storagetest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency"
+)
+
+const (
+	FirstProvider  currency.Provider = "StorageTestFirst"
+	SecondProvider currency.Provider = "StorageTestSecond"
+	seedSize                         = 5
+)
+
+// Factory returns an empty and migrated storage.
+// Run drops and closes it after every sub test.
+type Factory func(t *testing.T) currency.Storage
+
+// Start is the CreatedAt of the first seeded rate, seeded rates are one hour apart.
+var Start = time.Date(2020, 10, 16, 8, 0, 0, 0, time.UTC)
+
+func Run(t *testing.T, factory Factory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, st currency.Storage)
+	}{
+		{"StoreReturnsIDs", testStoreReturnsIDs},
+		{"SortedByCreatedAtAscending", testSortedByCreatedAtAscending},
+		{"Pagination", testPagination},
+		{"PaginationWithEqualCreatedAt", testPaginationWithEqualCreatedAt},
+		{"InclusiveRangeBounds", testInclusiveRangeBounds},
+		{"ProviderFilter", testProviderFilter},
+		{"StartAfterEnd", testStartAfterEnd},
+		{"Latest", testLatest},
+		{"LatestBefore", testLatestBefore},
+		{"MigrateAndDropIdempotent", testMigrateAndDropIdempotent},
+		{"DecimalPrecision", testDecimalPrecision},
+		{"Pairs", testPairs},
+		{"StoreWithID", testStoreWithID},
+		{"StoreWithDuplicateID", testStoreWithDuplicateID},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			st := factory(t)
+			defer func() {
+				_ = st.Drop()
+				_ = st.Close()
+			}()
+
+			test.test(t, st)
+		})
+	}
+}
+
+func at(hour int) time.Time {
+	return Start.Add(time.Duration(hour) * time.Hour)
+}
+
+// seed stores seedSize EUR_USD rates for both providers and one USD_EUR rate.
+// Rates are stored in reverse order, so storages cannot rely on insertion order.
+// Rate of the EUR_USD rate is 1 + hour/10, the same for both providers.
+func seed(t *testing.T, st currency.Storage) []currency.CurrencyWithID {
+	currencies := make([]currency.Currency, 0, 2*seedSize+1)
+
+	for i := seedSize - 1; i >= 0; i-- {
+		rate := decimal.New(int64(10+i), -1)
+		currencies = append(currencies,
+			currency.Currency{From: "EUR", To: "USD", Provider: FirstProvider, Rate: rate, CreatedAt: at(i)},
+			currency.Currency{From: "EUR", To: "USD", Provider: SecondProvider, Rate: rate, CreatedAt: at(i)},
+		)
+	}
+
+	currencies = append(currencies, currency.Currency{From: "USD", To: "EUR", Provider: FirstProvider, Rate: decimal.New(5, -1), CreatedAt: at(0)})
+
+	stored, err := st.Store(currencies)
+
+	require.NoError(t, err)
+	require.Len(t, stored, len(currencies))
+
+	return stored
+}
+
+func testStoreReturnsIDs(t *testing.T, st currency.Storage) {
+	asserts := require.New(t)
+	stored := seed(t, st)
+	ids := make(map[interface{}]currency.Currency, len(stored))
+
+	for _, c := range stored {
+		asserts.NotNil(c.ID)
+		asserts.NotContains(ids, c.ID, "Store returned duplicate ID")
+		ids[c.ID] = c.Currency
+	}
+
+	result, err := st.GetByDate("EUR", "USD", at(0), at(seedSize), 1, 2*seedSize)
+
+	asserts.NoError(err)
+	asserts.Len(result, 2*seedSize)
+
+	for _, c := range result {
+		storedCurrency, ok := ids[c.ID]
+		asserts.Truef(ok, "ID %v returned by Get is not returned by Store", c.ID)
+		asserts.Equal(storedCurrency.From, c.From)
+		asserts.Equal(storedCurrency.To, c.To)
+		asserts.Equal(storedCurrency.Provider, c.Provider)
+		asserts.True(storedCurrency.Rate.Equal(c.Rate), "expected rate %v, got %v", storedCurrency.Rate, c.Rate)
+		asserts.True(storedCurrency.CreatedAt.Equal(c.CreatedAt), "expected %v, got %v", storedCurrency.CreatedAt, c.CreatedAt)
+	}
+}
+
+func testSortedByCreatedAtAscending(t *testing.T, st currency.Storage) {
+	asserts := require.New(t)
+	seed(t, st)
+
+	result, err := st.GetByDateAndProvider("EUR", "USD", FirstProvider, at(0), at(seedSize), 1, seedSize)
+
+	asserts.NoError(err)
+	asserts.Len(result, seedSize)
+
+	for i, c := range result {
+		asserts.True(at(i).Equal(c.CreatedAt), "expected %v at position %d, got %v", at(i), i, c.CreatedAt)
+	}
+}
+
+func testPagination(t *testing.T, st currency.Storage) {
+	asserts := require.New(t)
+	seed(t, st)
+
+	first, err := st.GetByDateAndProvider("EUR", "USD", FirstProvider, at(0), at(seedSize), 1, 2)
+	asserts.NoError(err)
+	second, err := st.GetByDateAndProvider("EUR", "USD", FirstProvider, at(0), at(seedSize), 2, 2)
+	asserts.NoError(err)
+	last, err := st.GetByDateAndProvider("EUR", "USD", FirstProvider, at(0), at(seedSize), 3, 2)
+	asserts.NoError(err)
+	empty, err := st.GetByDateAndProvider("EUR", "USD", FirstProvider, at(0), at(seedSize), 4, 2)
+	asserts.NoError(err)
+
+	asserts.Len(first, 2)
+	asserts.Len(second, 2)
+	asserts.Len(last, 1)
+	asserts.Empty(empty)
+
+	asserts.True(at(0).Equal(first[0].CreatedAt))
+	asserts.True(at(2).Equal(second[0].CreatedAt))
+	asserts.True(at(4).Equal(last[0].CreatedAt))
+}
+
+// testPaginationWithEqualCreatedAt pages through more rates created at the same time
+// than fit on a page, like backfilled daily rates stored at midnight
+func testPaginationWithEqualCreatedAt(t *testing.T, st currency.Storage) {
+	asserts := require.New(t)
+	currencies := make([]currency.Currency, 0, 3*seedSize)
+
+	for i := 0; i < 3*seedSize; i++ {
+		provider := FirstProvider
+
+		if i%2 == 1 {
+			provider = SecondProvider
+		}
+
+		currencies = append(currencies, currency.Currency{From: "EUR", To: "USD", Provider: provider, Rate: decimal.New(int64(10+i), -1), CreatedAt: at(0)})
+	}
+
+	stored, err := st.Store(currencies)
+	asserts.NoError(err)
+
+	const perPage = 4
+	read := make(map[interface{}]bool, len(stored))
+
+	for page := int64(1); ; page++ {
+		result, err := st.GetByDate("EUR", "USD", at(0), at(0), page, perPage)
+		asserts.NoError(err)
+
+		for _, c := range result {
+			asserts.False(read[c.ID], "rate %v returned on more than one page", c.ID)
+			read[c.ID] = true
+		}
+
+		if len(result) < perPage {
+			break
+		}
+	}
+
+	asserts.Len(read, len(stored))
+
+	for _, c := range stored {
+		asserts.True(read[c.ID], "rate %v was skipped", c.ID)
+	}
+}
+
+func testInclusiveRangeBounds(t *testing.T, st currency.Storage) {
+	asserts := require.New(t)
+	seed(t, st)
+
+	result, err := st.GetByDateAndProvider("EUR", "USD", FirstProvider, at(1), at(3), 1, seedSize)
+
+	asserts.NoError(err)
+	asserts.Len(result, 3)
+	asserts.True(at(1).Equal(result[0].CreatedAt))
+	asserts.True(at(3).Equal(result[2].CreatedAt))
+
+	result, err = st.GetByDate("EUR", "USD", at(2), at(2), 1, seedSize)
+
+	asserts.NoError(err)
+	asserts.Len(result, 2)
+
+	result, err = st.GetByDate("EUR", "USD", at(seedSize), at(seedSize+1), 1, seedSize)
+
+	asserts.NoError(err)
+	asserts.Empty(result)
+}
+
+func testProviderFilter(t *testing.T, st currency.Storage) {
+	asserts := require.New(t)
+	seed(t, st)
+
+	for _, provider := range []currency.Provider{FirstProvider, SecondProvider} {
+		result, err := st.GetByDateAndProvider("EUR", "USD", provider, at(0), at(seedSize), 1, 2*seedSize)
+
+		asserts.NoError(err)
+		asserts.Len(result, seedSize)
+
+		for _, c := range result {
+			asserts.Equal(provider, c.Provider)
+			asserts.Equal("EUR", c.From)
+			asserts.Equal("USD", c.To)
+		}
+	}
+
+	result, err := st.GetByDate("EUR", "USD", at(0), at(seedSize), 1, 3*seedSize)
+	asserts.NoError(err)
+	asserts.Len(result, 2*seedSize)
+
+	result, err = st.GetByProvider("USD", "EUR", FirstProvider, 1, 10)
+	asserts.NoError(err)
+	asserts.Len(result, 1)
+
+	result, err = st.GetByProvider("USD", "EUR", SecondProvider, 1, 10)
+	asserts.NoError(err)
+	asserts.Empty(result)
+
+	result, err = st.Get("EUR", "RSD", 1, 10)
+	asserts.NoError(err)
+	asserts.Empty(result)
+}
+
+func testStartAfterEnd(t *testing.T, st currency.Storage) {
+	asserts := require.New(t)
+
+	result, err := st.GetByDate("EUR", "USD", at(1), at(0), 1, 10)
+
+	asserts.Error(err)
+	asserts.Empty(result)
+}
+
+func latestStorage(t *testing.T, st currency.Storage) currency.LatestStorage {
+	latest, ok := st.(currency.LatestStorage)
+
+	if !ok {
+		t.Skipf("%s does not implement currency.LatestStorage", st.GetStorageProviderName())
+	}
+
+	return latest
+}
+
+func testLatest(t *testing.T, storage currency.Storage) {
+	st := latestStorage(t, storage)
+	asserts := require.New(t)
+
+	_, err := st.Latest("EUR", "USD", currency.EmptyProvider)
+	asserts.True(errors.Is(err, currency.ErrNotFound), "expected currency.ErrNotFound, got %v", err)
+
+	seed(t, storage)
+
+	_, err = storage.Store([]currency.Currency{
+		{From: "EUR", To: "USD", Provider: SecondProvider, Rate: decimal.NewFromInt(2), CreatedAt: at(seedSize)},
+	})
+	asserts.NoError(err)
+
+	latest, err := st.Latest("EUR", "USD", currency.EmptyProvider)
+	asserts.NoError(err)
+	asserts.NotNil(latest.ID)
+	asserts.Equal(SecondProvider, latest.Provider)
+	asserts.True(decimal.NewFromInt(2).Equal(latest.Rate))
+	asserts.True(at(seedSize).Equal(latest.CreatedAt))
+
+	latest, err = st.Latest("EUR", "USD", FirstProvider)
+	asserts.NoError(err)
+	asserts.Equal(FirstProvider, latest.Provider)
+	asserts.Equal("EUR", latest.From)
+	asserts.Equal("USD", latest.To)
+	asserts.True(at(seedSize - 1).Equal(latest.CreatedAt))
+
+	_, err = st.Latest("USD", "EUR", SecondProvider)
+	asserts.True(errors.Is(err, currency.ErrNotFound), "expected currency.ErrNotFound, got %v", err)
+
+	tied := make([]currency.Currency, 0, seedSize)
+
+	for i := 0; i < seedSize; i++ {
+		tied = append(tied, currency.Currency{From: "USD", To: "EUR", Provider: FirstProvider, Rate: decimal.NewFromInt(int64(i + 1)), CreatedAt: at(1)})
+	}
+
+	_, err = storage.Store(tied)
+	asserts.NoError(err)
+
+	page, err := storage.GetByDate("USD", "EUR", at(1), at(1), 1, seedSize)
+	asserts.NoError(err)
+	asserts.Len(page, seedSize)
+
+	latest, err = st.Latest("USD", "EUR", currency.EmptyProvider)
+	asserts.NoError(err)
+	asserts.Equal(page[seedSize-1].ID, latest.ID)
+
+	latest, err = st.LatestBefore("USD", "EUR", FirstProvider, at(1))
+	asserts.NoError(err)
+	asserts.Equal(page[seedSize-1].ID, latest.ID)
+}
+
+func testLatestBefore(t *testing.T, storage currency.Storage) {
+	st := latestStorage(t, storage)
+	asserts := require.New(t)
+
+	seed(t, storage)
+
+	latest, err := st.LatestBefore("EUR", "USD", SecondProvider, at(2))
+	asserts.NoError(err)
+	asserts.Equal(SecondProvider, latest.Provider)
+	asserts.True(at(2).Equal(latest.CreatedAt))
+
+	latest, err = st.LatestBefore("EUR", "USD", currency.EmptyProvider, at(3).Add(30*time.Minute))
+	asserts.NoError(err)
+	asserts.True(at(3).Equal(latest.CreatedAt))
+
+	_, err = st.LatestBefore("EUR", "USD", currency.EmptyProvider, at(0).Add(-time.Second))
+	asserts.True(errors.Is(err, currency.ErrNotFound), "expected currency.ErrNotFound, got %v", err)
+
+	_, err = st.LatestBefore("USD", "EUR", SecondProvider, at(seedSize))
+	asserts.True(errors.Is(err, currency.ErrNotFound), "expected currency.ErrNotFound, got %v", err)
+}
+
+func testMigrateAndDropIdempotent(t *testing.T, st currency.Storage) {
+	asserts := require.New(t)
+
+	asserts.NoError(st.Migrate())
+	asserts.NoError(st.Migrate())
+	seed(t, st)
+
+	asserts.NoError(st.Drop())
+	asserts.NoError(st.Drop())
+	asserts.NoError(st.Migrate())
+
+	result, err := st.Get("EUR", "USD", 1, 10)
+	asserts.NoError(err)
+	asserts.Empty(result)
+
+	seed(t, st)
+}
+
+func testDecimalPrecision(t *testing.T, st currency.Storage) {
+	asserts := require.New(t)
+	rates := []string{"117.58129384", "0.00000001", "123456789012.12345678"}
+
+	for i, rate := range rates {
+		_, err := st.Store([]currency.Currency{
+			{From: "EUR", To: "RSD", Provider: FirstProvider, Rate: decimal.RequireFromString(rate), CreatedAt: at(i)},
+		})
+		asserts.NoError(err)
+	}
+
+	result, err := st.GetByDateAndProvider("EUR", "RSD", FirstProvider, at(0), at(len(rates)), 1, int64(len(rates)))
+
+	asserts.NoError(err)
+	asserts.Len(result, len(rates))
+
+	for i, rate := range rates {
+		asserts.Equal(rate, result[i].Rate.String())
+	}
+}
+
+func testPairs(t *testing.T, st currency.Storage) {
+	lister, ok := st.(currency.PairLister)
+
+	if !ok {
+		t.Skipf("%s does not implement currency.PairLister", st.GetStorageProviderName())
+	}
+
+	asserts := require.New(t)
+
+	pairs, err := lister.Pairs()
+	asserts.NoError(err)
+	asserts.Empty(pairs)
+
+	seed(t, st)
+
+	pairs, err = lister.Pairs()
+	asserts.NoError(err)
+	asserts.Equal([]string{"EUR_USD", "USD_EUR"}, pairs)
+}
+
+func testStoreWithID(t *testing.T, st currency.Storage) {
+	idStorage, ok := st.(currency.IDStorage)
+
+	if !ok {
+		t.Skipf("%s does not implement currency.IDStorage", st.GetStorageProviderName())
+	}
+
+	asserts := require.New(t)
+	stored := seed(t, st)
+
+	asserts.NoError(st.Drop())
+	asserts.NoError(st.Migrate())
+
+	// IDs returned by the same kind of storage can always be kept
+	copied, err := idStorage.StoreWithID(stored)
+	asserts.NoError(err)
+	asserts.Len(copied, len(stored))
+
+	for i, c := range copied {
+		asserts.Equal(stored[i].ID, c.ID)
+	}
+
+	result, err := st.GetByDateAndProvider("EUR", "USD", FirstProvider, at(0), at(seedSize), 1, seedSize)
+	asserts.NoError(err)
+	asserts.Len(result, seedSize)
+
+	ids := make(map[interface{}]struct{}, len(stored))
+
+	for _, c := range stored {
+		ids[c.ID] = struct{}{}
+	}
+
+	for _, c := range result {
+		asserts.Contains(ids, c.ID)
+	}
+
+	// Rates without IDs get generated ones
+	generated, err := idStorage.StoreWithID([]currency.CurrencyWithID{
+		{Currency: currency.Currency{From: "EUR", To: "RSD", Provider: FirstProvider, Rate: decimal.NewFromInt(117), CreatedAt: at(0)}},
+	})
+	asserts.NoError(err)
+	asserts.Len(generated, 1)
+	asserts.NotNil(generated[0].ID)
+	asserts.NotContains(ids, generated[0].ID)
+}
+
+func testStoreWithDuplicateID(t *testing.T, st currency.Storage) {
+	idStorage, ok := st.(currency.IDStorage)
+
+	if !ok {
+		t.Skipf("%s does not implement currency.IDStorage", st.GetStorageProviderName())
+	}
+
+	asserts := require.New(t)
+	stored := seed(t, st)
+
+	// Importing the same rates twice must not duplicate them
+	_, err := idStorage.StoreWithID(stored)
+	asserts.Error(err)
+
+	result, err := st.GetByDate("EUR", "USD", at(0), at(seedSize), 1, 4*seedSize)
+	asserts.NoError(err)
+	asserts.Len(result, 2*seedSize)
+}
@@ -0,0 +1,64 @@
+package currency_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/malusev998/currency"
+	"github.com/malusev998/currency/storage"
+)
+
+// plainStorage hides optional interfaces of the embedded storage
+type plainStorage struct {
+	currency.Storage
+}
+
+func TestLatestRate(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2020, 10, 16, 0, 0, 0, 0, time.UTC)
+	memory, err := storage.NewMemoryStorage(storage.MemoryConfig{})
+	require.Nil(t, err)
+
+	// More rates than are read per page when the storage does not implement LatestStorage
+	rates := make([]currency.Currency, 0, 1200)
+
+	for i := 0; i < cap(rates); i++ {
+		rates = append(rates, currency.Currency{
+			From:      "EUR",
+			To:        "USD",
+			Provider:  currency.ECBProvider,
+			Rate:      decimal.NewFromInt(int64(i)),
+			CreatedAt: start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+
+	_, err = memory.Store(rates)
+	require.Nil(t, err)
+
+	for name, st := range map[string]currency.Storage{"LatestStorage": memory, "Paged": plainStorage{Storage: memory}} {
+		st := st
+
+		t.Run(name, func(t *testing.T) {
+			asserts := require.New(t)
+
+			latest, err := currency.LatestRate(st, "EUR", "USD", currency.EmptyProvider)
+			asserts.Nil(err)
+			asserts.Equal("1199", latest.Rate.String())
+
+			latest, err = currency.LatestRateBefore(st, "EUR", "USD", currency.ECBProvider, start.Add(700*time.Minute+time.Second))
+			asserts.Nil(err)
+			asserts.Equal("700", latest.Rate.String())
+
+			_, err = currency.LatestRateBefore(st, "EUR", "USD", currency.EmptyProvider, start.Add(-time.Second))
+			asserts.True(errors.Is(err, currency.ErrNotFound))
+
+			_, err = currency.LatestRate(st, "EUR", "RSD", currency.EmptyProvider)
+			asserts.True(errors.Is(err, currency.ErrNotFound))
+		})
+	}
+}